@@ -6,7 +6,6 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
-	"sync"
 	"syscall"
 	"time"
 
@@ -16,16 +15,19 @@ import (
 	"github.com/anamalala/internal/config"
 	"github.com/anamalala/internal/handlers"
 	"github.com/anamalala/internal/middlewares"
+	"github.com/anamalala/internal/repositories/interfaces"
+	"github.com/anamalala/internal/repositories/memory"
 	"github.com/anamalala/internal/repositories/mongodb"
 	routers "github.com/anamalala/internal/router"
 	"github.com/anamalala/internal/services"
 	"github.com/anamalala/internal/utils"
+	"github.com/anamalala/pkg/contentfilter"
+	"github.com/anamalala/pkg/hub"
 	"github.com/anamalala/pkg/logger"
 	"github.com/anamalala/pkg/sms"
+	"github.com/anamalala/pkg/storage"
 )
 
-var smsConfig = sms.SMSConfig{}
-
 func main() {
 	// Carregar variáveis de ambiente
 	if err := godotenv.Load(); err != nil {
@@ -33,7 +35,10 @@ func main() {
 	}
 
 	// Inicializar configuração
-	cfg, _ := config.LoadConfig()
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Configuração inválida: %v", err)
+	}
 
 	// Inicializar logger
 	appLogger := logger.NewLogger(cfg.Enviroment)
@@ -54,9 +59,15 @@ func main() {
 	}()
 	appLogger.Info("Conectado ao MongoDB com sucesso")
 
-	// Inicializar repositórios
-	var lock = &sync.RWMutex{}
+	// Criar os índices, como o TTL que apaga as sessões expiradas
+	indexCtx, cancelIndexes := context.WithTimeout(context.Background(), 30*time.Second)
+	if err := mongoClient.CreateIndexes(indexCtx); err != nil {
+		cancelIndexes()
+		appLogger.Fatal("Falha ao criar índices do MongoDB:", err)
+	}
+	cancelIndexes()
 
+	// Inicializar repositórios
 	appLogger.Info("Iniciando repositorios")
 
 	userRepo := mongodb.NewUserRepository(&mongoClient)
@@ -64,46 +75,168 @@ func main() {
 	infoRepo := mongodb.NewInformationRepository(&mongoClient)
 	commentRepo := mongodb.NewCommentRepository(&mongoClient)
 	suggestionRepo := mongodb.NewSuggestionRepository(&mongoClient)
+	sessionRepo := mongodb.NewSessionRepository(&mongoClient)
+	verificationRepo := mongodb.NewVerificationRepository(&mongoClient)
+	smsOutboxRepo := mongodb.NewSMSOutboxRepository(&mongoClient)
+	smsCampaignRepo := mongodb.NewSMSCampaignRepository(&mongoClient)
+	notificationRepo := mongodb.NewNotificationRepository(&mongoClient)
+	notificationPreferencesRepo := mongodb.NewNotificationPreferencesRepository(&mongoClient)
+	mediaRepo := mongodb.NewMediaRepository(&mongoClient)
+	moderationRepo := mongodb.NewModerationRepository(&mongoClient)
+	blockedTermRepo := mongodb.NewBlockedTermRepository(&mongoClient)
+	contentFingerprintRepo := mongodb.NewContentFingerprintRepository(&mongoClient)
+	sanctionRepo := mongodb.NewSanctionRepository(&mongoClient)
+	auditLogRepo := mongodb.NewAuditLogRepository(&mongoClient)
 
 	// Inicializar utilitários
 
 	appLogger.Info(" A Inicializar utilitários")
 
-	tokenUtil := utils.NewTokenUtil(cfg.JWT.Secret, time.Duration(time.Hour.Abs()*5))
+	tokenUtil := utils.NewTokenUtil(
+		cfg.JWT.Secret,
+		time.Duration(cfg.JWT.ExpirationHours)*time.Hour,
+		cfg.JWT.RefreshSecret,
+		time.Duration(cfg.JWT.RefreshExpHours)*time.Hour,
+	)
 	validator := utils.NewValidator()
 
 	// Inicializar serviço de SMS
-	// smsConfig.APIKey = ""
-	// smsConfig.ProviderType = ""
-	// smsConfig.SenderID = ""
-	smsService, _ := sms.NewService(&smsConfig, appLogger)
+	smsConfig := sms.SMSConfig{
+		ProviderType: cfg.SMS.Provider,
+		APIKey:       cfg.SMS.APIKey,
+		APISecret:    cfg.SMS.APISecret,
+		ServiceURL:   cfg.SMS.ServiceURL,
+		SenderID:     cfg.SMS.SenderID,
+	}
+	smsService, err := sms.NewService(&smsConfig, appLogger)
+	if err != nil {
+		appLogger.Fatal("Falha ao inicializar serviço de SMS:", err)
+	}
+
+	// Inicializar armazenamento de ficheiros
+	mediaStorage, err := storage.NewStorage(storage.Config{
+		Driver: cfg.Media.Storage,
+		Root:   cfg.Media.Root,
+	})
+	if err != nil {
+		appLogger.Fatal("Falha ao inicializar armazenamento de ficheiros:", err)
+	}
 
 	// Inicializar serviços
 
 	appLogger.Info(" A Inicializar serviços")
 
-	authService := services.NewAuthService(userRepo, tokenUtil)
+	// Histórico das ações dos administradores, usado pelos serviços que as executam
+	auditService := services.NewAuditService(auditLogRepo, userRepo, appLogger)
+
+	verificationService := services.NewVerificationService(
+		verificationRepo,
+		smsService,
+		cfg.OTP.Expiry,
+		cfg.OTP.MaxAttempts,
+		cfg.OTP.ResendCooldown,
+		cfg.OTP.Lockout,
+	)
+	smsOutboxService := services.NewSMSOutboxService(
+		smsOutboxRepo,
+		smsService,
+		appLogger,
+		cfg.SMS.OutboxMaxAttempts,
+	)
+	smsCampaignService := services.NewSMSCampaignService(
+		smsCampaignRepo,
+		smsOutboxRepo,
+		userRepo,
+		notificationPreferencesRepo,
+		smsOutboxService,
+		auditService,
+		appLogger,
+		cfg.SMS.CostPerSegment,
+		cfg.SMS.Currency,
+	)
+	authService := services.NewAuthService(userRepo, sessionRepo, tokenUtil, verificationService, appLogger)
 	userService := services.NewUserService(userRepo)
-	infoService := services.NewInformationService(infoRepo)
-	chatroomService := services.NewChatroomService(postRepo, commentRepo, userRepo)
+	sessionService := services.NewSessionService(sessionRepo)
+	mediaService := services.NewMediaService(
+		mediaRepo,
+		mediaStorage,
+		cfg.Media.MaxUploadSize,
+		cfg.Media.ThumbnailWidths,
+		cfg.Media.SigningSecret,
+		cfg.Media.URLTTL,
+		cfg.Media.PublicURL,
+	)
+	infoService := services.NewInformationService(infoRepo, mediaService, auditService)
+	notificationService := services.NewNotificationService(notificationRepo, notificationPreferencesRepo, userRepo, smsOutboxService)
+	// Filtro de conteúdo: a lista de bloqueio gerida pelos administradores e as regras contra spam
+	contentFilterService := services.NewContentFilterService(
+		blockedTermRepo,
+		cfg.ContentFilter.BlocklistRefresh,
+		contentfilter.NewLinkRule(cfg.ContentFilter.MaxLinks, contentfilter.ParseAction(cfg.ContentFilter.LinkAction)),
+		contentfilter.NewPhoneRule(cfg.ContentFilter.MaxPhones, contentfilter.ParseAction(cfg.ContentFilter.PhoneAction)),
+		contentfilter.NewDuplicateRule(contentFingerprintRepo, cfg.ContentFilter.DuplicateWindow, contentfilter.ParseAction(cfg.ContentFilter.DuplicateAction)),
+	)
+	chatroomService := services.NewChatroomService(postRepo, commentRepo, userRepo, notificationService, mediaService, contentFilterService, moderationRepo, auditService)
 	suggestionService := services.NewSuggestionService(suggestionRepo, userRepo)
-	adminService := services.NewAdminService(userRepo, postRepo, commentRepo, smsService)
+	sanctionService := services.NewSanctionService(sanctionRepo, userRepo, notificationPreferencesRepo, sessionService, smsOutboxService, auditService, appLogger)
+	adminService := services.NewAdminService(userRepo, postRepo, commentRepo, smsOutboxService, smsCampaignService, sanctionService, auditService)
+
+	// Inicializar hub de WebSocket
+	wsHub := hub.NewHub(hub.Config{
+		SendBuffer:     cfg.WebSocket.SendBuffer,
+		PingPeriod:     cfg.WebSocket.PingPeriod,
+		PongWait:       cfg.WebSocket.PongWait,
+		WriteWait:      cfg.WebSocket.WriteWait,
+		MaxMessageSize: cfg.WebSocket.MaxMessageSize,
+	}, appLogger)
+
+	// Barramento de eventos da sala de bate-papo: em memória para uma única instância,
+	// ou change streams do MongoDB para várias réplicas
+	var eventBus interfaces.EventBus = memory.NewEventBus()
+	if cfg.WebSocket.EventBus == "mongodb" {
+		eventBus = mongodb.NewChangeStreamBus(&mongoClient)
+	}
+	appLogger.Info("Barramento de eventos configurado", "backend", cfg.WebSocket.EventBus)
+
+	presenceService := services.NewPresenceService(userRepo, eventBus, appLogger)
+	moderationService := services.NewModerationService(
+		moderationRepo,
+		postRepo,
+		commentRepo,
+		chatroomService,
+		notificationService,
+		eventBus,
+		auditService,
+		appLogger,
+		cfg.Moderation.HideThreshold,
+	)
 
 	// Inicializar handlers
 	appLogger.Info(" A Inicializar handlers")
 
 	authHandler := handlers.NewAuthHandler(authService, validator)
-	userHandler := handlers.NewUserHandler(userService)
+	userHandler := handlers.NewUserHandler(userService, presenceService)
 	infoHandler := handlers.NewInformationHandler(infoService)
-	chatroomHandler := handlers.NewChatroomHandler(chatroomService, lock)
+	chatroomHandler := handlers.NewChatroomHandler(chatroomService, wsHub, eventBus, presenceService, sanctionService, appLogger)
+	notificationService.SetPusher(&chatroomHandler)
 	suggestionHandler := handlers.NewSuggestionHandler(suggestionService)
 	adminHandler := handlers.NewAdminHandler(adminService)
+	sessionHandler := handlers.NewSessionHandler(sessionService)
+	smsHandler := handlers.NewSMSHandler(smsOutboxService, smsCampaignService, cfg.SMS.WebhookToken)
+	if cfg.SMS.WebhookToken == "" {
+		appLogger.Warn("sms_webhook_token_missing", "detail", "SMS_WEBHOOK_TOKEN vazio, relatórios de entrega serão recusados")
+	}
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	mediaHandler := handlers.NewMediaHandler(mediaService)
+	moderationHandler := handlers.NewModerationHandler(moderationService)
+	contentFilterHandler := handlers.NewContentFilterHandler(contentFilterService)
+	sanctionHandler := handlers.NewSanctionHandler(sanctionService)
 
 	// Inicializar middlewares
 	appLogger.Info(" A Inicializar middlewares")
 
-	authMiddleware := middlewares.NewAuthMiddleware(tokenUtil, userService)
-	adminMiddleware := middlewares.NewAdminMiddleware(tokenUtil)
+	authMiddleware := middlewares.NewAuthMiddleware(tokenUtil, userService, sessionService, presenceService)
+	adminMiddleware := middlewares.NewAdminMiddleware(tokenUtil, sanctionService)
 	//	loggerMiddleware := middlewares.NewLoggerMiddleware(appLogger)
 
 	// Configurar router (Gin)
@@ -121,10 +254,31 @@ func main() {
 		chatroomHandler,
 		suggestionHandler,
 		adminHandler,
+		sessionHandler,
+		smsHandler,
+		notificationHandler,
+		mediaHandler,
+		moderationHandler,
+		contentFilterHandler,
+		sanctionHandler,
 		authMiddleware,
 		adminMiddleware,
 	)
 
+	// Iniciar workers da fila de SMS, o agendador de campanhas, o levantamento de sanções e o hub de WebSocket
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	go wsHub.Run(workersCtx)
+	go chatroomHandler.RunBroadcasts(workersCtx)
+	go presenceService.Run(workersCtx)
+	workersDone := make(chan struct{})
+	go func() {
+		defer close(workersDone)
+		smsOutboxService.Run(workersCtx, cfg.SMS.OutboxWorkers)
+	}()
+	go smsCampaignService.RunScheduler(workersCtx)
+	go sanctionService.RunScheduler(workersCtx)
+	appLogger.Info("Fila de SMS iniciada", "workers", cfg.SMS.OutboxWorkers)
+
 	// Configurar servidor HTTP
 	server := &http.Server{
 		Addr:    ":8080",
@@ -154,6 +308,13 @@ func main() {
 		appLogger.Fatal("Erro ao encerrar servidor:", err)
 	}
 
+	// Parar os workers e esperar pelos envios em curso
+	stopWorkers()
+	select {
+	case <-workersDone:
+	case <-ctx.Done():
+	}
+
 	appLogger.Info("Servidor encerrado com sucesso")
 }
 
@@ -1,9 +1,11 @@
 package config
 
 import (
+	"errors"
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -15,6 +17,11 @@ type Config struct {
 	Database DatabaseConfig
 	JWT      JWTConfig
 	SMS      SMSConfig
+	OTP      OTPConfig
+	WebSocket WebSocketConfig
+	Media     MediaConfig
+	Moderation ModerationConfig
+	ContentFilter ContentFilterConfig
 	Enviroment string
 }
 
@@ -44,10 +51,63 @@ type JWTConfig struct {
 
 // SMSConfig contém configurações para o serviço de SMS
 type SMSConfig struct {
+	Provider     string
 	APIKey       string
 	APISecret    string
 	ServiceURL   string
 	SenderID     string
+	OutboxWorkers     int
+	OutboxMaxAttempts int
+	WebhookToken      string
+	CostPerSegment    float64
+	Currency          string
+}
+
+// OTPConfig contém configurações dos códigos de verificação enviados por SMS
+type OTPConfig struct {
+	Expiry         time.Duration
+	MaxAttempts    int
+	ResendCooldown time.Duration
+	Lockout        time.Duration
+}
+
+// WebSocketConfig contém configurações das ligações WebSocket da sala de bate-papo
+type WebSocketConfig struct {
+	EventBus       string
+	SendBuffer     int
+	PingPeriod     time.Duration
+	PongWait       time.Duration
+	WriteWait      time.Duration
+	MaxMessageSize int64
+}
+
+// MediaConfig contém configurações do envio e armazenamento de ficheiros
+type MediaConfig struct {
+	Storage       string
+	Root          string
+	MaxUploadSize int64
+	ThumbnailWidths []int
+	SigningSecret string
+	URLTTL        time.Duration
+	PublicURL     string
+}
+
+// ModerationConfig contém configurações da fila de denúncias
+type ModerationConfig struct {
+	// HideThreshold é o número de denúncias a partir do qual o conteúdo fica oculto; 0 desativa
+	HideThreshold int
+}
+
+// ContentFilterConfig contém configurações do filtro aplicado às postagens e comentários.
+// As ações são reject, mask ou hold; qualquer outro valor, como "off", desativa a regra.
+type ContentFilterConfig struct {
+	BlocklistRefresh time.Duration
+	LinkAction       string
+	MaxLinks         int
+	PhoneAction      string
+	MaxPhones        int
+	DuplicateAction  string
+	DuplicateWindow  time.Duration
 }
 
 // LoadConfig carrega todas as configurações do ambiente
@@ -57,6 +117,9 @@ func LoadConfig() (*Config, error) {
 		log.Println("Arquivo .env não encontrado, usando variáveis de ambiente do sistema")
 	}
 
+	// Ambiente: "dev" para desenvolvimento; qualquer outro valor é tratado como produção
+	environment := getEnv("APP_ENV", "production")
+
 	// Configurações do servidor
 	serverPort := getEnv("SERVER_PORT", "8080")
 	readTimeout, _ := strconv.Atoi(getEnv("SERVER_READ_TIMEOUT", "15"))
@@ -76,13 +139,66 @@ func LoadConfig() (*Config, error) {
 	jwtRefreshExpHours, _ := strconv.Atoi(getEnv("JWT_REFRESH_EXPIRATION_HOURS", "168")) // 7 dias
 
 	// Configurações SMS
+	// O provedor mock escreve os SMS no log, por isso só é o padrão em desenvolvimento
+	smsProvider := os.Getenv("SMS_PROVIDER")
+	if smsProvider == "" {
+		if environment != "dev" {
+			return nil, errors.New("SMS_PROVIDER não definido: indique o provedor de SMS ou use APP_ENV=dev")
+		}
+		smsProvider = "mock"
+	}
 	smsAPIKey := getEnv("SMS_API_KEY", "")
 	smsAPISecret := getEnv("SMS_API_SECRET", "")
 	smsServiceURL := getEnv("SMS_SERVICE_URL", "")
 	smsSenderID := getEnv("SMS_SENDER_ID", "ANAMALALA")
+	smsOutboxWorkers, _ := strconv.Atoi(getEnv("SMS_OUTBOX_WORKERS", "4"))
+	smsOutboxMaxAttempts, _ := strconv.Atoi(getEnv("SMS_OUTBOX_MAX_ATTEMPTS", "5"))
+	smsWebhookToken := getEnv("SMS_WEBHOOK_TOKEN", "")
+	smsCostPerSegment, _ := strconv.ParseFloat(getEnv("SMS_COST_PER_SEGMENT", "1"), 64)
+	smsCurrency := getEnv("SMS_COST_CURRENCY", "MZN")
+
+	// Configurações WebSocket
+	wsEventBus := getEnv("WS_EVENT_BUS", "memory")
+	wsSendBuffer, _ := strconv.Atoi(getEnv("WS_SEND_BUFFER", "64"))
+	wsPingPeriod, _ := strconv.Atoi(getEnv("WS_PING_PERIOD_SECONDS", "50"))
+	wsPongWait, _ := strconv.Atoi(getEnv("WS_PONG_WAIT_SECONDS", "60"))
+	wsWriteWait, _ := strconv.Atoi(getEnv("WS_WRITE_WAIT_SECONDS", "10"))
+	wsMaxMessageSize, _ := strconv.ParseInt(getEnv("WS_MAX_MESSAGE_SIZE", "8192"), 10, 64)
+
+	// Configurações de ficheiros
+	mediaStorage := getEnv("MEDIA_STORAGE", "filesystem")
+	mediaRoot := getEnv("MEDIA_ROOT", "./data/media")
+	mediaMaxUploadMB, _ := strconv.ParseInt(getEnv("MEDIA_MAX_UPLOAD_MB", "10"), 10, 64)
+	var mediaThumbnailWidths []int
+	for _, width := range strings.Split(getEnv("MEDIA_THUMBNAIL_WIDTHS", "160,320,640"), ",") {
+		if w, err := strconv.Atoi(strings.TrimSpace(width)); err == nil && w > 0 {
+			mediaThumbnailWidths = append(mediaThumbnailWidths, w)
+		}
+	}
+	mediaSigningSecret := getEnv("MEDIA_SIGNING_SECRET", "anamalala_media_key")
+	mediaURLTTL, _ := strconv.Atoi(getEnv("MEDIA_URL_TTL_MINUTES", "60"))
+	mediaPublicURL := getEnv("MEDIA_PUBLIC_URL", "")
+
+	// Configurações de moderação
+	moderationHideThreshold, _ := strconv.Atoi(getEnv("MODERATION_HIDE_THRESHOLD", "5"))
+
+	// Configurações do filtro de conteúdo
+	filterBlocklistRefresh, _ := strconv.Atoi(getEnv("CONTENT_FILTER_BLOCKLIST_REFRESH_SECONDS", "60"))
+	filterLinkAction := getEnv("CONTENT_FILTER_LINK_ACTION", "hold")
+	filterMaxLinks, _ := strconv.Atoi(getEnv("CONTENT_FILTER_MAX_LINKS", "2"))
+	filterPhoneAction := getEnv("CONTENT_FILTER_PHONE_ACTION", "hold")
+	filterMaxPhones, _ := strconv.Atoi(getEnv("CONTENT_FILTER_MAX_PHONES", "1"))
+	filterDuplicateAction := getEnv("CONTENT_FILTER_DUPLICATE_ACTION", "reject")
+	filterDuplicateWindow, _ := strconv.Atoi(getEnv("CONTENT_FILTER_DUPLICATE_WINDOW_MINUTES", "10"))
+
+	// Configurações OTP
+	otpExpiryMinutes, _ := strconv.Atoi(getEnv("OTP_EXPIRY_MINUTES", "10"))
+	otpMaxAttempts, _ := strconv.Atoi(getEnv("OTP_MAX_ATTEMPTS", "5"))
+	otpResendCooldown, _ := strconv.Atoi(getEnv("OTP_RESEND_COOLDOWN_SECONDS", "60"))
+	otpLockoutMinutes, _ := strconv.Atoi(getEnv("OTP_LOCKOUT_MINUTES", "30"))
 
 	return &Config{
-		Enviroment: "dev",
+		Enviroment: environment,
 		Server: ServerConfig{
 			Port:         serverPort,
 			ReadTimeout:  time.Duration(readTimeout) * time.Second,
@@ -102,10 +218,51 @@ func LoadConfig() (*Config, error) {
 			RefreshExpHours: jwtRefreshExpHours,
 		},
 		SMS: SMSConfig{
+			Provider:   smsProvider,
 			APIKey:     smsAPIKey,
 			APISecret:  smsAPISecret,
 			ServiceURL: smsServiceURL,
 			SenderID:   smsSenderID,
+			OutboxWorkers:     smsOutboxWorkers,
+			OutboxMaxAttempts: smsOutboxMaxAttempts,
+			WebhookToken:      smsWebhookToken,
+			CostPerSegment:    smsCostPerSegment,
+			Currency:          smsCurrency,
+		},
+		OTP: OTPConfig{
+			Expiry:         time.Duration(otpExpiryMinutes) * time.Minute,
+			MaxAttempts:    otpMaxAttempts,
+			ResendCooldown: time.Duration(otpResendCooldown) * time.Second,
+			Lockout:        time.Duration(otpLockoutMinutes) * time.Minute,
+		},
+		WebSocket: WebSocketConfig{
+			EventBus:       wsEventBus,
+			SendBuffer:     wsSendBuffer,
+			PingPeriod:     time.Duration(wsPingPeriod) * time.Second,
+			PongWait:       time.Duration(wsPongWait) * time.Second,
+			WriteWait:      time.Duration(wsWriteWait) * time.Second,
+			MaxMessageSize: wsMaxMessageSize,
+		},
+		Media: MediaConfig{
+			Storage:       mediaStorage,
+			Root:          mediaRoot,
+			MaxUploadSize: mediaMaxUploadMB << 20,
+			ThumbnailWidths: mediaThumbnailWidths,
+			SigningSecret: mediaSigningSecret,
+			URLTTL:        time.Duration(mediaURLTTL) * time.Minute,
+			PublicURL:     mediaPublicURL,
+		},
+		Moderation: ModerationConfig{
+			HideThreshold: moderationHideThreshold,
+		},
+		ContentFilter: ContentFilterConfig{
+			BlocklistRefresh: time.Duration(filterBlocklistRefresh) * time.Second,
+			LinkAction:       filterLinkAction,
+			MaxLinks:         filterMaxLinks,
+			PhoneAction:      filterPhoneAction,
+			MaxPhones:        filterMaxPhones,
+			DuplicateAction:  filterDuplicateAction,
+			DuplicateWindow:  time.Duration(filterDuplicateWindow) * time.Minute,
 		},
 	}, nil
 }
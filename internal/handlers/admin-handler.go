@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/anamalala/internal/models"
 	"github.com/anamalala/internal/services"
 	"github.com/anamalala/internal/utils"
 	"github.com/gin-gonic/gin"
@@ -55,8 +58,9 @@ func (h *AdminHandler) PromoteToAdmin(c *gin.Context) {
 		return
 	}
 	// Validar role
+	adminID, _ := c.Get("userID")
 
-	err := h.adminService.PromoteToAdmin(c, userID)
+	err := h.adminService.PromoteToAdmin(c, adminID.(string), userID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Falha ao atualizar função do usuário")
 		return
@@ -72,6 +76,106 @@ func (h *AdminHandler) PromoteToAdmin(c *gin.Context) {
 	})
 }
 
+// GetMyPermissions devolve o role do membro da equipa que faz o pedido e o que ele permite
+func (h *AdminHandler) GetMyPermissions(c *gin.Context) {
+	userRole, _ := c.Get("userRole")
+	role := models.Role(userRole.(string))
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Permissões obtidas com sucesso",
+		"data": models.RoleDefinition{
+			Role:        role,
+			Permissions: role.Permissions(),
+		},
+	})
+}
+
+// GetRoles lista os roles que podem ser atribuídos e as permissões de cada um
+func (h *AdminHandler) GetRoles(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Roles obtidos com sucesso",
+		"data":    h.adminService.GetRoles(),
+	})
+}
+
+// GetUsersByRole lista os usuários com um role
+func (h *AdminHandler) GetUsersByRole(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	users, total, err := h.adminService.GetUsersByRole(c, models.Role(c.Param("role")), page, limit)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRole) {
+			c.JSON(http.StatusBadRequest, err.Error())
+			return
+		}
+		c.JSON(http.StatusInternalServerError, "Falha ao buscar usuários")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Usuários obtidos com sucesso",
+		"data": gin.H{
+			"users":      users,
+			"total":      total,
+			"page":       page,
+			"limit":      limit,
+			"totalPages": (total + limit - 1) / limit,
+		},
+	})
+}
+
+// AssignRole altera o role de um usuário e, com ele, as suas permissões
+func (h *AdminHandler) AssignRole(c *gin.Context) {
+	userID := c.Param("id")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, "ID do usuário não fornecido")
+		return
+	}
+
+	var assignment models.RoleAssignment
+	if err := c.ShouldBindJSON(&assignment); err != nil {
+		c.JSON(http.StatusBadRequest, "Dados inválidos")
+		return
+	}
+
+	adminID, _ := c.Get("userID")
+
+	user, err := h.adminService.AssignRole(c, adminID.(string), userID, assignment.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRoleUserNotFound):
+			c.JSON(http.StatusNotFound, err.Error())
+		case errors.Is(err, services.ErrInvalidRole) || errors.Is(err, services.ErrRoleUnchanged) ||
+			errors.Is(err, services.ErrRoleSelf):
+			c.JSON(http.StatusBadRequest, err.Error())
+		default:
+			c.JSON(http.StatusInternalServerError, "Falha ao atualizar função do usuário")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Função do usuário atualizada com sucesso",
+		"data": gin.H{
+			"user_id":     user.ID,
+			"role":        user.Role,
+			"permissions": user.Role.Permissions(),
+		},
+	})
+}
+
+// BanUser suspende um usuário; duration_hours igual a zero, ou omitido, suspende até ser levantada à mão
 func (h *AdminHandler) BanUser(c *gin.Context) {
 	// Verificando se é um usuário administrador
 	isAdmin, exists := c.Get("isAdmin")
@@ -86,10 +190,30 @@ func (h *AdminHandler) BanUser(c *gin.Context) {
 		return
 	}
 
+	var request struct {
+		Reason        string `json:"reason"`
+		DurationHours int    `json:"duration_hours"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, services.ErrSanctionReasonRequired.Error())
+		return
+	}
+
+	adminID, _ := c.Get("userID")
 
-	err := h.adminService.BanUser(c, userID)
+	sanction, err := h.adminService.BanUser(c, adminID.(string), userID, request.Reason, request.DurationHours)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, "Falha ao banir usuário")
+		switch {
+		case errors.Is(err, services.ErrSanctionUserNotFound):
+			c.JSON(http.StatusNotFound, err.Error())
+		case errors.Is(err, services.ErrSanctionStaff):
+			c.JSON(http.StatusForbidden, err.Error())
+		case errors.Is(err, services.ErrSanctionReasonRequired) || errors.Is(err, services.ErrInvalidSanctionDuration) ||
+			errors.Is(err, services.ErrSanctionSelf):
+			c.JSON(http.StatusBadRequest, err.Error())
+		default:
+			c.JSON(http.StatusInternalServerError, "Falha ao banir usuário")
+		}
 		return
 	}
 
@@ -97,11 +221,13 @@ func (h *AdminHandler) BanUser(c *gin.Context) {
 		"status":  "success",
 		"message": "Usuário banido com sucesso",
 		"data": gin.H{
-			"user_id": userID,
+			"user_id":  userID,
+			"sanction": sanction,
 		},
 	})
 }
 
+// UnbanUser levanta as suspensões em vigor de um usuário, com um motivo opcional
 func (h *AdminHandler) UnbanUser(c *gin.Context) {
 	// Verificando se é um usuário administrador
 	isAdmin, exists := c.Get("isAdmin")
@@ -116,8 +242,28 @@ func (h *AdminHandler) UnbanUser(c *gin.Context) {
 		return
 	}
 
-	err := h.adminService.UnbanUser(c, userID)
+	var request struct {
+		Reason string `json:"reason"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, "Dados inválidos")
+			return
+		}
+	}
+
+	adminID, _ := c.Get("userID")
+
+	err := h.adminService.UnbanUser(c, adminID.(string), userID, request.Reason)
 	if err != nil {
+		if errors.Is(err, services.ErrUserNotBanned) {
+			c.JSON(http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrSanctionStaff) {
+			c.JSON(http.StatusForbidden, err.Error())
+			return
+		}
 		c.JSON(http.StatusInternalServerError, "Falha ao desbanir usuário")
 		return
 	}
@@ -171,6 +317,8 @@ func (h *AdminHandler) GetBannedUsers(c *gin.Context) {
 	})
 }
 
+// GetAdminLogs lista o histórico das ações dos administradores, das mais recentes para as mais antigas.
+// Pode ser filtrado por admin_id, action e um intervalo de datas from/to.
 func (h *AdminHandler) GetAdminLogs(c *gin.Context) {
 	// Verificando se é um usuário administrador
 	isAdmin, exists := c.Get("isAdmin")
@@ -181,23 +329,24 @@ func (h *AdminHandler) GetAdminLogs(c *gin.Context) {
 
 	// Parâmetros para paginação
 	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
-
-	if  err != nil{
+	if err != nil || page < 1 {
 		page = 1
-	 }
-	limit,_ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-
-	if err != nil{
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 {
 		limit = 20
-	 }
-
+	}
 
 	// Filtros opcionais
-	action := c.Query("action")
+	filter, err := auditFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
 
-	logs, err := h.adminService.GetAdminLogs(page, limit, action)
+	logs, total, err := h.adminService.GetAdminLogs(c, filter, page, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, "Falha ao buscar logs administrativos",)
+		c.JSON(http.StatusInternalServerError, "Falha ao buscar logs administrativos")
 		return
 	}
 
@@ -206,14 +355,79 @@ func (h *AdminHandler) GetAdminLogs(c *gin.Context) {
 		"message": "Logs obtidos com sucesso",
 		"data": gin.H{
 			"logs":       logs,
-			"total":      len(logs),
+			"total":      total,
 			"page":       page,
 			"limit":      limit,
-			"totalPages": (len(logs) + limit - 1) / limit,
+			"totalPages": (total + limit - 1) / limit,
 		},
 	})
 }
 
+// ExportAdminLogs descarrega o histórico em CSV, com os mesmos filtros de GetAdminLogs
+func (h *AdminHandler) ExportAdminLogs(c *gin.Context) {
+	// Verificando se é um usuário administrador
+	isAdmin, exists := c.Get("isAdmin")
+	if !exists || !isAdmin.(bool) {
+		c.JSON(http.StatusForbidden, "Acesso restrito a administradores")
+		return
+	}
+
+	filter, err := auditFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filename := "admin-logs-" + time.Now().UTC().Format("20060102-150405") + ".csv"
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	// Os cabeçalhos já foram enviados: uma falha a meio só pode ser registada
+	if err := h.adminService.ExportAdminLogs(c, filter, c.Writer); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// errInvalidAuditDate indica uma data from ou to num formato desconhecido
+var errInvalidAuditDate = errors.New("data inválida: use AAAA-MM-DD ou RFC 3339")
+
+// auditFilter lê os filtros do histórico da query string.
+// As datas são RFC 3339 ou YYYY-MM-DD em UTC; uma data simples em to inclui o dia inteiro.
+func auditFilter(c *gin.Context) (models.AuditFilter, error) {
+	filter := models.AuditFilter{
+		AdminID: c.Query("admin_id"),
+		Action:  models.AuditAction(c.Query("action")),
+	}
+
+	for _, bound := range []struct {
+		param string
+		value *time.Time
+	}{
+		{"from", &filter.From},
+		{"to", &filter.To},
+	} {
+		raw := c.Query(bound.param)
+		if raw == "" {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339, raw); err == nil {
+			*bound.value = t
+			continue
+		}
+		t, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			return models.AuditFilter{}, errInvalidAuditDate
+		}
+		if bound.param == "to" {
+			t = t.AddDate(0, 0, 1)
+		}
+		*bound.value = t
+	}
+
+	return filter, nil
+}
+
 func (h *AdminHandler) SendMassMessage(c *gin.Context) {
 	// Verificando se é um usuário administrador
 	isAdmin, exists := c.Get("isAdmin")
@@ -235,15 +449,24 @@ func (h *AdminHandler) SendMassMessage(c *gin.Context) {
 		return
 	}
 
-	if len(request.Provinces) == 0{
-		_, err = h.adminService.SendSMSToAllUsers(c, request.Message)
-	}
+	adminID, _ := c.Get("userID")
 
-	for _, province := range request.Provinces{
-		_, err = h.adminService.SendSMSToProvince(c, request.Message, province)
+	var campaign models.SMSCampaign
+	if len(request.Provinces) == 0{
+		campaign, err = h.adminService.SendSMSToAllUsers(c, adminID.(string), request.Message)
+	} else {
+		campaign, err = h.adminService.SendSMSToProvinces(c, adminID.(string), request.Message, request.Provinces)
 	}
 	
 	if err != nil {
+		if errors.Is(err, services.ErrCampaignNoRecipients) || errors.Is(err, services.ErrCampaignInvalidTemplate) {
+			c.JSON(http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrCampaignOutsideProvince) {
+			c.JSON(http.StatusForbidden, err.Error())
+			return
+		}
 		c.JSON(http.StatusInternalServerError, "Falha ao enviar mensagem em massa")
 		return
 	}
@@ -251,7 +474,7 @@ func (h *AdminHandler) SendMassMessage(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "success",
 		"message": "Mensagem em massa enviada com sucesso",
-
+		"data":    campaign,
 	})
 }
 
@@ -278,8 +501,14 @@ func (h *AdminHandler) DeleteAccount(c *gin.Context) {
 		return
 	}
 
-	err := h.adminService.DeleteUserAccount(userID)
+	adminID, _ := c.Get("userID")
+
+	err := h.adminService.DeleteUserAccount(c, adminID.(string), userID, request.Reason)
 	if err != nil {
+		if errors.Is(err, services.ErrDeleteUserNotFound) {
+			c.JSON(http.StatusNotFound, err.Error())
+			return
+		}
 		c.JSON(http.StatusInternalServerError,"Falha ao excluir conta")
 		return
 	}
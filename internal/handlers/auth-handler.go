@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/anamalala/internal/models"
@@ -63,13 +64,17 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	saveuser.Province = user.Province
 
 	registeredUser, err := h.authService.Register(c, saveuser)
+	if errors.Is(err, services.ErrCodeResendCooldown) || errors.Is(err, services.ErrCodeLocked) {
+		c.JSON(http.StatusTooManyRequests, err.Error())
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, "Falha ao registrar usuário")
 		return
 	}
 	c.JSON(http.StatusCreated, gin.H{
 		"status":  "success",
-		"message": "Usuário registrado com sucesso",
+		"message": "Usuário registrado, confirme o código enviado por SMS",
 		"data": gin.H{
 			"user": registeredUser,
 		},
@@ -80,6 +85,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	var loginRequest struct {
 		Contact  string `json:"contact" binding:"required"`
 		Password string `json:"password" binding:"required"`
+		Device   string `json:"device"`
 	}
 
 	if err := c.ShouldBindJSON(&loginRequest); err != nil {
@@ -89,15 +95,100 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	loginRequest.Contact = h.validator.FormatPhoneNumber(loginRequest.Contact)
 
-	user, token, err := h.authService.Login(c, loginRequest.Contact, loginRequest.Password)
+	if loginRequest.Device == "" {
+		loginRequest.Device = c.Request.UserAgent()
+	}
+
+	user, tokens, err := h.authService.Login(c, loginRequest.Contact, loginRequest.Password, loginRequest.Device, c.ClientIP())
+	if errors.Is(err, services.ErrContactNotVerified) {
+		c.JSON(http.StatusForbidden, "Contacto ainda não verificado")
+		return
+	}
+	if errors.Is(err, services.ErrAccountSuspended) {
+		c.JSON(http.StatusForbidden, "Conta suspensa")
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, "Credenciais inválidas")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"token": token,
-		"user": user,
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"user":          user,
+	})
+}
+
+func (h *AuthHandler) VerifyContact(c *gin.Context) {
+	var request models.ContactVerification
+	if err := c.ShouldBindJSON(&request); err != nil || request.Contact == "" || request.Code == "" {
+		c.JSON(http.StatusBadRequest, "Dados inválidos")
+		return
+	}
+	request.Contact = h.validator.FormatPhoneNumber(request.Contact)
+
+	user, err := h.authService.VerifyContact(c, request.Contact, request.Code)
+	if err != nil {
+		h.verificationErrorResponse(c, err, "Falha ao verificar contacto")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Contacto verificado com sucesso",
+		"data": gin.H{
+			"user": user,
+		},
+	})
+}
+
+func (h *AuthHandler) ResendVerificationCode(c *gin.Context) {
+	var request struct {
+		Contact string `json:"contact" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, "Número de telefone inválido")
+		return
+	}
+	request.Contact = h.validator.FormatPhoneNumber(request.Contact)
+
+	err := h.authService.ResendVerificationCode(c, request.Contact)
+	if errors.Is(err, services.ErrCodeResendCooldown) || errors.Is(err, services.ErrCodeLocked) {
+		c.JSON(http.StatusTooManyRequests, err.Error())
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, "Falha ao enviar código de verificação")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Se o contacto estiver pendente, um novo código foi enviado",
+	})
+}
+
+func (h *AuthHandler) RefreshToken(c *gin.Context) {
+	var request struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, "Token de renovação não fornecido")
+		return
+	}
+
+	tokens, err := h.authService.RefreshToken(c, request.RefreshToken, c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, "Sessão inválida, entre de novo")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
 	})
 }
 
@@ -109,15 +200,19 @@ func (h *AuthHandler) RequestPasswordReset(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, "Número de telefone inválido")
 		return
 	}
-	// Gerar token de redefinição e enviar SMS
+	request.PhoneNumber = h.validator.FormatPhoneNumber(request.PhoneNumber)
+
+	// Gerar código de redefinição e enviar SMS
 	err := h.authService.ResetPasswordRequest(c, request.PhoneNumber)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, "Falha ao enviar token de redefinição")
 		return
 	}
+
+	// A mesma resposta é devolvida quer o contacto exista ou não
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "success",
-		"message": "Token de redefinição enviado com sucesso",
+		"message": "Se o número estiver registado, receberá um código por SMS",
 	})
 }
 
@@ -131,10 +226,11 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, "Dados inválidos")
 		return
 	}
+	request.PhoneNumber = h.validator.FormatPhoneNumber(request.PhoneNumber)
 
 	err := h.authService.ResetPassword(c, request.PhoneNumber, request.Token, request.NewPassword)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, "Falha ao redefinir senha")
+		h.verificationErrorResponse(c, err, "Falha ao redefinir senha")
 		return
 	}
 
@@ -154,34 +250,45 @@ func (h *AuthHandler) VerifyResetPasswordToken(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, "Dados inválidos")
 		return
 	}
+	request.PhoneNumber = h.validator.FormatPhoneNumber(request.PhoneNumber)
 
-	ok := h.authService.VerifyResetPasswordCode(c, request.PhoneNumber, request.Token)
-	if !ok {
-		c.JSON(http.StatusBadRequest, "codigo invalido")
+	err := h.authService.VerifyResetPasswordCode(c, request.PhoneNumber, request.Token)
+	if err != nil {
+		h.verificationErrorResponse(c, err, "codigo invalido")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"status":  "success",
-		"token": request.Token,
+		"status": "success",
+		"token":  request.Token,
 	})
 }
 
-func (h *AuthHandler) Logout(c *gin.Context) {
-	var request struct{
-		Phone string `json:"contact"`
+// verificationErrorResponse traduz os erros de verificação de código em respostas HTTP
+func (h *AuthHandler) verificationErrorResponse(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrCodeTooManyAttempts), errors.Is(err, services.ErrCodeLocked):
+		c.JSON(http.StatusTooManyRequests, err.Error())
+	case errors.Is(err, services.ErrCodeInvalid), errors.Is(err, services.ErrCodeExpired):
+		c.JSON(http.StatusBadRequest, err.Error())
+	default:
+		c.JSON(http.StatusBadRequest, fallback)
 	}
-	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, "Número de telefone inválido")
+}
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	sessionID, exists := c.Get("sessionID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, "Usuário não autenticado")
 		return
 	}
 
-	err := h.authService.Logout(c, request.Phone)
+	err := h.authService.Logout(c, sessionID.(string))
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, err.Error())
 		return
-	} 
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "success",
 		"message": "Logout realizado com sucesso",
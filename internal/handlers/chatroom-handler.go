@@ -1,15 +1,18 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
 	"strconv"
-	"sync"
 	"time"
 
 	"github.com/anamalala/internal/models"
+	"github.com/anamalala/internal/repositories/interfaces"
 	"github.com/anamalala/internal/services"
+	"github.com/anamalala/pkg/hub"
+	"github.com/anamalala/pkg/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 )
@@ -25,14 +28,20 @@ var upgrader = websocket.Upgrader{
 
 // Define message types for WebSocket communication
 const (
-	MsgTypeNewPost    = "new_post"
-	MsgTypeDeletePost = "delete_post"
-	MsgTypeNewComment = "new_comment"
-	MsgTypeDelComment = "delete_comment"
-	MsgTypeLikePost   = "like_post"
-	MsgTypeLikeCmnt   = "like_comment"
+	MsgTypeNewPost    = models.EventNewPost
+	MsgTypeDeletePost = models.EventDeletePost
+	MsgTypeNewComment = models.EventNewComment
+	MsgTypeDelComment = models.EventDeleteComment
+	MsgTypeLikePost   = models.EventLikePost
+	MsgTypeLikeCmnt   = models.EventLikeComment
 )
 
+// maxPageLimit is the largest page accepted by the cursor paginated listings
+const maxPageLimit = 50
+
+// defaultThreadDepth is the number of reply levels returned by GetThread when depth is not given
+const defaultThreadDepth = 5
+
 // WebSocketMessage represents the structure of messages sent over WebSocket
 type WebSocketMessage struct {
 	Type    string      `json:"type"`
@@ -43,16 +52,25 @@ type WebSocketMessage struct {
 type ChatroomHandler struct {
 	chatroomService services.ChatroomService
 	// WebSocket connection management
-	clients    map[string][]*websocket.Conn // Map of userID to connections (a user can have multiple connections)
-	clientsMux *sync.RWMutex                 // Mutex for thread-safe access to clients map
+	hub *hub.Hub
+	// Bus that carries chatroom events to the clients of every replica
+	eventBus interfaces.EventBus
+	// Online presence derived from the open connections
+	presenceService *services.PresenceService
+	// Mutes and bans, checked before the write commands sent over the WebSocket
+	sanctionService services.SanctionService
+	logger          *logger.Logger
 }
 
 // NewChatroomHandler creates a new instance of ChatroomHandler
-func NewChatroomHandler(chatroomService services.ChatroomService, clientsMux *sync.RWMutex  ) ChatroomHandler {
+func NewChatroomHandler(chatroomService services.ChatroomService, wsHub *hub.Hub, eventBus interfaces.EventBus, presenceService *services.PresenceService, sanctionService services.SanctionService, logger *logger.Logger) ChatroomHandler {
 	return ChatroomHandler{
 		chatroomService: chatroomService,
-		clients:         make(map[string][]*websocket.Conn),
-		clientsMux: clientsMux ,
+		hub:             wsHub,
+		eventBus:        eventBus,
+		presenceService: presenceService,
+		sanctionService: sanctionService,
+		logger:          logger,
 	}
 }
 
@@ -67,87 +85,159 @@ func (h *ChatroomHandler) HandleWebSocket(c *gin.Context) {
 	// Upgrade HTTP connection to WebSocket
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
-		fmt.Printf("Failed to upgrade connection: %v\n", err)
+		h.logger.Warn("websocket_upgrade_failed", "user_id", userID.(string), "error", err.Error())
 		return
 	}
 
-	// Register the client
-	h.registerClient(userID.(string), conn)
+	// New connections follow the feed and their own user channel; post threads are
+	// subscribed with commands. Serve blocks until the connection ends.
+	topics := []string{models.TopicFeed, models.UserTopic(userID.(string))}
+	h.presenceService.Connected(userID.(string))
+	defer h.presenceService.Disconnected(userID.(string))
+	if err := h.hub.Serve(conn, userID.(string), topics, h.handleCommand); err != nil {
+		h.logger.Error("websocket_serve_failed", "user_id", userID.(string), "error", err.Error())
+	}
+}
 
-	// Handle disconnection when the function returns
-	defer h.unregisterClient(userID.(string), conn)
+// broadcastMessage publishes a message on the event bus so it reaches the subscribers
+// of the given topics on every replica
+func (h *ChatroomHandler) broadcastMessage(message WebSocketMessage, topics ...string) {
+	h.publish(models.Event{Type: message.Type, Topics: topics, Payload: message.Payload})
+}
 
-	// Handle incoming messages (if needed)
-	h.handleMessages(conn, userID.(string))
+// publish sends an event built by the models constructors through the event bus
+func (h *ChatroomHandler) publish(event models.Event) {
+	if err := h.eventBus.Publish(context.Background(), event); err != nil {
+		h.logger.Error("event_publish_failed", "type", event.Type, "error", err.Error())
+	}
 }
 
-// registerClient adds a new WebSocket connection to the clients map
-func (h *ChatroomHandler) registerClient(userID string, conn *websocket.Conn) {
-	h.clientsMux.Lock()
-	defer h.clientsMux.Unlock()
+// RunBroadcasts delivers the events of the bus to the clients connected to this replica
+// until ctx is cancelled, resubscribing after failures.
+func (h *ChatroomHandler) RunBroadcasts(ctx context.Context) {
+	for {
+		err := h.eventBus.Subscribe(ctx, func(event models.Event) {
+			messageJSON, err := json.Marshal(WebSocketMessage{Type: event.Type, Payload: event.Payload})
+			if err != nil {
+				h.logger.Error("websocket_message_marshal_failed", "type", event.Type, "error", err.Error())
+				return
+			}
+			if len(event.Topics) == 0 {
+				h.hub.Broadcast(messageJSON)
+				return
+			}
+			h.hub.Publish(event.Topics, messageJSON)
+		})
+		if ctx.Err() != nil {
+			return
+		}
 
-	h.clients[userID] = append(h.clients[userID], conn)
-	fmt.Printf("Client registered: %s (total connections: %d)\n", userID, len(h.clients[userID]))
-}
-// unregisterClient removes a WebSocket connection from the clients map
-func (h *ChatroomHandler) unregisterClient(userID string, conn *websocket.Conn) {
-	h.clientsMux.Lock()
-	defer h.clientsMux.Unlock()
-
-	// Find and remove the connection
-	connections := h.clients[userID]
-	for i, c := range connections {
-		if c == conn {
-			// Close the connection
-			conn.Close()
-			// Remove from slice
-			h.clients[userID] = append(connections[:i], connections[i+1:]...)
-			break
+		h.logger.Warn("event_bus_subscribe_failed", "retry_in", "5s", "error", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
 		}
 	}
+}
 
-	// If no more connections for this user, remove the user entry
-	if len(h.clients[userID]) == 0 {
-		delete(h.clients, userID)
+// PushToUser sends a message only to the connections of one user and returns how many received it.
+// It implements services.NotificationPusher.
+func (h *ChatroomHandler) PushToUser(userID string, messageType string, payload any) int {
+	messageJSON, err := json.Marshal(WebSocketMessage{Type: messageType, Payload: payload})
+	if err != nil {
+		h.logger.Error("websocket_message_marshal_failed", "type", messageType, "user_id", userID, "error", err.Error())
+		return 0
 	}
 
-	fmt.Printf("Client unregistered: %s (remaining connections: %d)\n", userID, len(h.clients[userID]))
+	return h.hub.SendToUser(userID, messageJSON)
 }
 
-// handleMessages processes incoming WebSocket messages
-func (h *ChatroomHandler) handleMessages(conn *websocket.Conn, userID string) {
-	for {
-		_, _, err := conn.ReadMessage()
-		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				fmt.Printf("WebSocket error: %v\n", err)
-			}
-			break // Exit the loop if there's an error
-		}
-		// We're not processing incoming messages for now, just keeping the connection alive
+// GetWebSocketMetrics returns the counters of the WebSocket hub
+func (h *ChatroomHandler) GetWebSocketMetrics(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Métricas de WebSocket obtidas com sucesso",
+		"data":    h.hub.Metrics(),
+	})
+}
+
+// createPost saves a post and broadcasts it; shared by the REST and WebSocket paths
+func (h *ChatroomHandler) createPost(ctx context.Context, post models.Post) (models.Post, error) {
+	createdPost, err := h.chatroomService.CreatePost(ctx, post)
+	if err != nil {
+		return models.Post{}, err
+	}
+
+	// Posts held by the content filter are only shown after a moderator approves them
+	if createdPost.Hidden {
+		return createdPost, nil
 	}
+
+	// New posts go to the feed
+	h.publish(models.NewPostEvent(createdPost))
+
+	return createdPost, nil
 }
 
-// broadcastMessage sends a message to all connected clients
-func (h *ChatroomHandler) broadcastMessage(message WebSocketMessage) {
-	h.clientsMux.RLock()
-	defer h.clientsMux.RUnlock()
+// commentPost saves a comment on a post and broadcasts it
+func (h *ChatroomHandler) commentPost(ctx context.Context, postID string, comment models.Comment) (models.Comment, error) {
+	createdComment, err := h.chatroomService.CommentPost(ctx, postID, comment, comment.UserID)
+	if err != nil {
+		return models.Comment{}, err
+	}
 
-	messageJSON, err := json.Marshal(message)
+	if createdComment.Hidden {
+		return createdComment, nil
+	}
+
+	// Comments go to the subscribers of the post thread
+	h.publish(models.NewCommentEvent(createdComment))
+
+	return createdComment, nil
+}
+
+// replyComment saves a reply to a comment and broadcasts it
+func (h *ChatroomHandler) replyComment(ctx context.Context, commentID string, comment models.Comment) (models.Comment, error) {
+	createdComment, err := h.chatroomService.ReplayComment(ctx, commentID, comment, comment.UserID)
 	if err != nil {
-		fmt.Printf("Error marshaling message: %v\n", err)
-		return
+		return models.Comment{}, err
 	}
 
-	for userID, connections := range h.clients {
-		for _, conn := range connections {
-			err := conn.WriteMessage(websocket.TextMessage, messageJSON)
-			if err != nil {
-				fmt.Printf("Error sending message to %s: %v\n", userID, err)
-				// We don't remove the connection here; it will be handled on next read operation
-			}
-		}
+	if createdComment.Hidden {
+		return createdComment, nil
+	}
+
+	// Replies go to the subscribers of the post thread
+	h.publish(models.NewCommentEvent(createdComment))
+
+	return createdComment, nil
+}
+
+// likePost toggles the like of a user on a post and broadcasts the updated post
+func (h *ChatroomHandler) likePost(ctx context.Context, postID, userID string) (models.Post, error) {
+	post, err := h.chatroomService.LikePost(ctx, postID, userID)
+	if err != nil {
+		return models.Post{}, err
+	}
+
+	// Like counts are shown in the feed, the thread and to the post author
+	h.publish(models.LikePostEvent(post, userID))
+
+	return post, nil
+}
+
+// likeComment toggles the like of a user on a comment and broadcasts it
+func (h *ChatroomHandler) likeComment(ctx context.Context, commentID, userID string) error {
+	comment, err := h.chatroomService.LikeComment(ctx, commentID, userID)
+	if err != nil {
+		return err
 	}
+
+	// Comment likes go to the thread and to the comment author
+	h.publish(models.LikeCommentEvent(comment, userID))
+
+	return nil
 }
 
 // CreatePost handles the creation of a new post
@@ -159,6 +249,8 @@ func (h *ChatroomHandler) CreatePost(c *gin.Context) {
 	}
 	var content struct{
 		Content string `json:"content"`
+		// Attachments are the IDs of media uploaded beforehand through POST /media
+		Attachments []string `json:"attachments"`
 	}
 
 	var post = models.Post{}
@@ -168,49 +260,52 @@ func (h *ChatroomHandler) CreatePost(c *gin.Context) {
 	}
 
 	post.Content = content.Content
+	post.Attachments = content.Attachments
 
 	// Validação de campos obrigatórios
-	if post.Content == "" {
+	if post.Content == "" && len(post.Attachments) == 0 {
 		c.JSON(http.StatusBadRequest, "Conteúdo é obrigatório")
 		return
 	}
 
 	post.UserID = userID.(string)
 
-	createdPost, err := h.chatroomService.CreatePost(c, post)
+	createdPost, err := h.createPost(c, post)
 	if err != nil {
+		if errors.Is(err, services.ErrContentRequired) || errors.Is(err, services.ErrInvalidAttachments) {
+			c.JSON(http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrContentRejected) {
+			c.JSON(http.StatusUnprocessableEntity, err.Error())
+			return
+		}
 		c.JSON(http.StatusInternalServerError, "Falha ao criar postagem")
 		return
 	}
 
-	// Broadcast new post to all clients
-	h.broadcastMessage(WebSocketMessage{
-		Type:    MsgTypeNewPost,
-		Payload: createdPost,
-	})
-
+	message := "Postagem criada com sucesso"
+	if createdPost.Hidden {
+		message = "Postagem enviada para revisão"
+	}
 	c.JSON(http.StatusCreated, gin.H{
 		"status":  "success",
-		"message": "Postagem criada com sucesso",
+		"message": message,
 		"data":    createdPost,
 	})
 }
 
-// GetPosts retrieves posts with pagination
+// GetPosts retrieves a page of posts, newest first.
+// The next_cursor of the response is sent back as ?cursor= to get the following page.
 func (h *ChatroomHandler) GetPosts(c *gin.Context) {
-	// Parâmetros para paginação
-	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
-	if err != nil {
-		page = 1
-	}
+	limit := pageLimit(c)
 
-	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	if err != nil {
-		limit = 20
-	}
-
-	posts, total, err := h.chatroomService.GetAllPosts(c, page, limit)
+	posts, nextCursor, err := h.chatroomService.GetAllPosts(c, c.Query("cursor"), limit)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, err.Error())
+			return
+		}
 		c.JSON(http.StatusInternalServerError, "Falha ao buscar postagens")
 		return
 	}
@@ -219,44 +314,23 @@ func (h *ChatroomHandler) GetPosts(c *gin.Context) {
 		"status":  "success",
 		"message": "Postagens obtidas com sucesso",
 		"data": gin.H{
-			"posts":      posts,
-			"total":      total,
-			"page":       page,
-			"limit":      limit,
-			"totalPages": (total + limit - 1) / limit,
+			"posts":       posts,
+			"limit":       limit,
+			"next_cursor": nextCursor,
 		},
 	})
 }
 
-// GetRecentPostsTotal retrieves the count of recent posts and comments
+// GetRecentPostsTotal retrieves the count of posts and comments of the last 48 hours
 func (h *ChatroomHandler) GetRecentPostsTotal(c *gin.Context) {
-	var totalComments int = 0
-	var totalPosts int = 0
-	var recentPosts []models.Post = []models.Post{}
-
-	posts, _, err := h.chatroomService.GetAllPosts(c, 0, 0)
+	total, err := h.chatroomService.CountRecentActivity(c, time.Now().Add(-48*time.Hour))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, "Falha ao buscar postagens")
 		return
 	}
 
-	for _, p := range posts {
-		if time.Now().After(p.CreatedAt.Add(time.Hour * 48)) {
-			continue
-		}
-		recentPosts = append(recentPosts, p)
-	}
-	totalPosts = len(recentPosts)
-
-	for _, p := range recentPosts {
-		n := len(p.Comments)
-		totalComments = totalComments + n
-	}
-
-	totalPosts = totalPosts + totalComments
-
 	c.JSON(http.StatusOK, gin.H{
-		"total": totalPosts,
+		"total": total,
 	})
 }
 
@@ -301,13 +375,8 @@ func (h *ChatroomHandler) DeletePost(c *gin.Context) {
 		return
 	}
 
-	// Broadcast post deletion to all clients
-	h.broadcastMessage(WebSocketMessage{
-		Type: MsgTypeDeletePost,
-		Payload: gin.H{
-			"postID": postID,
-		},
-	})
+	// Post deletions go to the feed and the thread
+	h.publish(models.DeletePostEvent(postID))
 
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "success",
@@ -345,24 +414,27 @@ func (h *ChatroomHandler) CommentPost(c *gin.Context) {
 	comment.ReferenceID = postID
 	comment.Reference = "post"
 
-	createdComment, err := h.chatroomService.CommentPost(c, postID, comment, comment.UserID)
+	createdComment, err := h.commentPost(c, postID, comment)
 	if err != nil {
+		if errors.Is(err, services.ErrPostNotFound) {
+			c.JSON(http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrContentRejected) {
+			c.JSON(http.StatusUnprocessableEntity, err.Error())
+			return
+		}
 		c.JSON(http.StatusInternalServerError, "Falha ao criar comentário")
 		return
 	}
 
-	// Broadcast new comment to all clients
-	h.broadcastMessage(WebSocketMessage{
-		Type: MsgTypeNewComment,
-		Payload: gin.H{
-			"comment": createdComment,
-			"postID":  postID,
-		},
-	})
-
+	message := "Comentário criado com sucesso"
+	if createdComment.Hidden {
+		message = "Comentário enviado para revisão"
+	}
 	c.JSON(http.StatusCreated, gin.H{
 		"status":  "success",
-		"message": "Comentário criado com sucesso",
+		"message": message,
 		"data":    createdComment,
 	})
 }
@@ -397,29 +469,33 @@ func (h *ChatroomHandler) ReplayComment(c *gin.Context) {
 	comment.ReferenceID = commentID
 	comment.Reference = "comment"
 
-	createdComment, err := h.chatroomService.ReplayComment(c, commentID, comment, comment.UserID)
+	createdComment, err := h.replyComment(c, commentID, comment)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, "Falha ao criar comentário")
+		switch {
+		case errors.Is(err, services.ErrCommentNotFound):
+			c.JSON(http.StatusNotFound, err.Error())
+		case errors.Is(err, services.ErrCommentDeleted):
+			c.JSON(http.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrContentRejected):
+			c.JSON(http.StatusUnprocessableEntity, err.Error())
+		default:
+			c.JSON(http.StatusInternalServerError, "Falha ao criar comentário")
+		}
 		return
 	}
 
-	// Broadcast new comment to all clients
-	h.broadcastMessage(WebSocketMessage{
-		Type: MsgTypeNewComment,
-		Payload: gin.H{
-			"comment": createdComment,
-			"referenceId":  commentID,
-		},
-	})
-
+	message := "Comentário criado com sucesso"
+	if createdComment.Hidden {
+		message = "Comentário enviado para revisão"
+	}
 	c.JSON(http.StatusCreated, gin.H{
 		"status":  "success",
-		"message": "Comentário criado com sucesso",
+		"message": message,
 		"data":    createdComment,
 	})
 }
 
-// GetCommentsByPostID retrieves comments for a specific post
+// GetCommentsByPostID retrieves a page of comments for a specific post, oldest first
 func (h *ChatroomHandler) GetCommentsByPostID(c *gin.Context) {
 	postID := c.Param("id")
 	if postID == "" {
@@ -427,36 +503,106 @@ func (h *ChatroomHandler) GetCommentsByPostID(c *gin.Context) {
 		return
 	}
 
-	// Parâmetros para paginação
-	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit := pageLimit(c)
+
+	comments, nextCursor, err := h.chatroomService.GetCommentsByPostID(c, postID, c.Query("cursor"), limit)
 	if err != nil {
-		page = 1
+		if errors.Is(err, services.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, err.Error())
+			return
+		}
+		c.JSON(http.StatusInternalServerError, "Falha ao buscar comentários")
+		return
 	}
 
-	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	if err != nil {
-		limit = 20
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Comentários obtidos com sucesso",
+		"data": gin.H{
+			"comments":    comments,
+			"limit":       limit,
+			"next_cursor": nextCursor,
+		},
+	})
+}
+
+// GetReplies retrieves a page of replies to a comment, oldest first
+func (h *ChatroomHandler) GetReplies(c *gin.Context) {
+	commentID := c.Param("id")
+	if commentID == "" {
+		c.JSON(http.StatusBadRequest, "ID do comentário não fornecido")
+		return
 	}
 
-	comments, total, err := h.chatroomService.GetCommentsByPostID(c, postID, page, limit)
+	limit := pageLimit(c)
+
+	replies, nextCursor, err := h.chatroomService.GetReplies(c, commentID, c.Query("cursor"), limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, "Falha ao buscar comentários")
+		if errors.Is(err, services.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, err.Error())
+			return
+		}
+		c.JSON(http.StatusInternalServerError, "Falha ao buscar respostas")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "success",
-		"message": "Comentários obtidos com sucesso",
+		"message": "Respostas obtidas com sucesso",
 		"data": gin.H{
-			"comments":   comments,
-			"total":      total,
-			"page":       page,
-			"limit":      limit,
-			"totalPages": (total + limit - 1) / limit,
+			"comments":    replies,
+			"limit":       limit,
+			"next_cursor": nextCursor,
 		},
 	})
 }
 
+// GetThread retrieves a comment with its replies nested at any depth.
+// depth limits the levels of replies and limit the replies returned under each comment;
+// reply_count tells how many there are, so the rest can be loaded from /comment/:id/comments.
+func (h *ChatroomHandler) GetThread(c *gin.Context) {
+	commentID := c.Param("id")
+	if commentID == "" {
+		c.JSON(http.StatusBadRequest, "ID do comentário não fornecido")
+		return
+	}
+
+	depth, err := strconv.Atoi(c.DefaultQuery("depth", strconv.Itoa(defaultThreadDepth)))
+	if err != nil || depth < 0 {
+		c.JSON(http.StatusBadRequest, "Profundidade inválida")
+		return
+	}
+	limit := pageLimit(c)
+
+	thread, err := h.chatroomService.GetThread(c, commentID, depth, limit)
+	if err != nil {
+		if errors.Is(err, services.ErrCommentNotFound) {
+			c.JSON(http.StatusNotFound, err.Error())
+			return
+		}
+		c.JSON(http.StatusInternalServerError, "Falha ao buscar conversa")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Conversa obtida com sucesso",
+		"data":    thread,
+	})
+}
+
+// pageLimit reads the page size of cursor paginated listings, between 1 and maxPageLimit
+func pageLimit(c *gin.Context) int {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		return 10
+	}
+	if limit > maxPageLimit {
+		return maxPageLimit
+	}
+	return limit
+}
+
 // DeleteComment handles deletion of a comment
 func (h *ChatroomHandler) DeleteComment(c *gin.Context) {
 	userID, exists := c.Get("userID")
@@ -478,20 +624,19 @@ func (h *ChatroomHandler) DeleteComment(c *gin.Context) {
 		return
 	}
 
-	err = h.chatroomService.DeleteComment(c, commentID, userID.(string))
+	placeholder, err := h.chatroomService.DeleteComment(c, commentID, userID.(string))
 	if err != nil {
+		if errors.Is(err, services.ErrCommentNotFound) {
+			c.JSON(http.StatusNotFound, err.Error())
+			return
+		}
 		c.JSON(http.StatusInternalServerError, "Falha ao excluir comentário")
 		return
 	}
 
-	// Broadcast comment deletion to all clients
-	h.broadcastMessage(WebSocketMessage{
-		Type: MsgTypeDelComment,
-		Payload: gin.H{
-			"commentID": commentID,
-			"reference_id":    comment.ReferenceID,
-		},
-	})
+	// Comment deletions go to the subscribers of the post thread. A placeholder
+	// stays in the thread, so clients should blank it instead of removing it.
+	h.publish(models.DeleteCommentEvent(comment, placeholder))
 
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "success",
@@ -513,29 +658,15 @@ func (h *ChatroomHandler) LikeComment(c *gin.Context) {
 		return
 	}
 
-	comment, err := h.chatroomService.GetCommentID(c, commentID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, "Falha ao buscar comentário")
-		return
-	}
-
-	err = h.chatroomService.LikeComment(c, commentID, userID.(string))
-	if err != nil {
+	if err := h.likeComment(c, commentID, userID.(string)); err != nil {
+		if errors.Is(err, services.ErrCommentNotFound) {
+			c.JSON(http.StatusNotFound, err.Error())
+			return
+		}
 		c.JSON(http.StatusInternalServerError, "Falha ao curtir o comentario")
 		return
 	}
 
-	// Broadcast comment like to all clients
-	h.broadcastMessage(WebSocketMessage{
-		Type: MsgTypeLikeCmnt,
-		Payload: gin.H{
-			"commentID": commentID,
-			"reference": comment.Reference,
-			"reference_id":    comment.ReferenceID,
-			"userID":    userID.(string),
-		},
-	})
-
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "success",
 		"message": "Comentario curtido com sucesso",
@@ -556,22 +687,15 @@ func (h *ChatroomHandler) LikePost(c *gin.Context) {
 		return
 	}
 
-	post, err := h.chatroomService.LikePost(c, postID, userID.(string))
-	if err != nil {
+	if _, err := h.likePost(c, postID, userID.(string)); err != nil {
+		if errors.Is(err, services.ErrPostNotFound) {
+			c.JSON(http.StatusNotFound, err.Error())
+			return
+		}
 		c.JSON(http.StatusInternalServerError, "Falha ao curtir postagem")
 		return
 	}
 
-	// Broadcast post like to all clients
-	h.broadcastMessage(WebSocketMessage{
-		Type: MsgTypeLikePost,
-		Payload: gin.H{
-			"post": post,
-			"postID": postID,
-			"userID": userID.(string),
-		},
-	})
-
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "success",
 		"message": "Postagem curtida com sucesso",
@@ -592,22 +716,16 @@ func (h *ChatroomHandler) UnlikePost(c *gin.Context) {
 		return
 	}
 
-	post, err := h.chatroomService.LikePost(c, postID, userID.(string))
-	if err != nil {
+	// LikePost toggles, so unliking re-uses the same path and message type
+	if _, err := h.likePost(c, postID, userID.(string)); err != nil {
+		if errors.Is(err, services.ErrPostNotFound) {
+			c.JSON(http.StatusNotFound, err.Error())
+			return
+		}
 		c.JSON(http.StatusInternalServerError, "Falha ao descurtir postagem")
 		return
 	}
 
-	// Broadcast post unlike to all clients
-	h.broadcastMessage(WebSocketMessage{
-		Type: MsgTypeLikePost, // Re-use the same message type, clients can determine action based on payload
-		Payload: gin.H{
-			"postID":  postID,
-			"userID":  userID.(string),
-			"post": post,
-		},
-	})
-
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "success",
 		"message": "Postagem descurtida com sucesso",
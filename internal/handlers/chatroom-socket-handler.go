@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/anamalala/internal/models"
+	"github.com/anamalala/internal/services"
+	"github.com/anamalala/pkg/hub"
+	"github.com/gin-gonic/gin"
+)
+
+// Commands accepted from clients over the chatroom WebSocket
+const (
+	CmdCreatePost      = "create_post"
+	CmdCreateComment   = "create_comment"
+	CmdReplyComment    = "reply_comment"
+	CmdLikePost        = "like_post"
+	CmdLikeComment     = "like_comment"
+	CmdTyping          = "typing"
+	CmdSubscribe       = "subscribe"
+	CmdUnsubscribe     = "unsubscribe"
+	CmdSubscribePost   = "subscribe_post"
+	CmdUnsubscribePost = "unsubscribe_post"
+)
+
+// Message types sent only in reply to commands or to post subscribers
+const (
+	MsgTypeAck    = "ack"
+	MsgTypeError  = "error"
+	MsgTypeTyping = "typing"
+)
+
+const (
+	// commandTimeout bounds the service calls made for a single command
+	commandTimeout = 10 * time.Second
+	// typingInterval is the minimum time between two typing indicators from the same connection
+	typingInterval = 2 * time.Second
+)
+
+// WebSocketCommand represents a command sent by a client.
+// ID is chosen by the client and echoed in the ack or error reply.
+type WebSocketCommand struct {
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// commandPayload holds the fields used by the different commands
+type commandPayload struct {
+	Content     string   `json:"content"`
+	Attachments []string `json:"attachments"`
+	PostID      string   `json:"post_id"`
+	CommentID   string   `json:"comment_id"`
+	Topic       string   `json:"topic"`
+}
+
+// Errors returned to clients for malformed commands
+var (
+	errInvalidCommand = errors.New("comando inválido")
+	errInvalidTopic   = errors.New("tópico inválido")
+	errReadOnly       = errors.New("conta em modo só de leitura")
+	errBanned         = errors.New("usuário está banido")
+)
+
+// writeCommands are the commands refused to muted and banned users, as the REST write routes are
+var writeCommands = map[string]bool{
+	CmdCreatePost:    true,
+	CmdCreateComment: true,
+	CmdReplyComment:  true,
+	CmdLikePost:      true,
+	CmdLikeComment:   true,
+	CmdTyping:        true,
+}
+
+// canSubscribe checks whether a user may follow a topic: the feed, a visible post, or only their own user channel
+func (h *ChatroomHandler) canSubscribe(ctx context.Context, userID, topic string) error {
+	kind, id, ok := models.ParseTopic(topic)
+	if !ok || (kind == "user" && id != userID) {
+		return errInvalidTopic
+	}
+	if kind == "post" {
+		return h.checkPostVisible(ctx, id)
+	}
+	return nil
+}
+
+// checkPostVisible returns ErrPostNotFound unless the post exists and is not hidden for moderation
+func (h *ChatroomHandler) checkPostVisible(ctx context.Context, postID string) error {
+	visible, err := h.chatroomService.IsPostVisible(ctx, postID)
+	if err != nil {
+		return err
+	}
+	if !visible {
+		return services.ErrPostNotFound
+	}
+	return nil
+}
+
+// handleCommand runs a command received on a WebSocket connection and replies with an ack or an error
+func (h *ChatroomHandler) handleCommand(client *hub.Client, data []byte) {
+	var command WebSocketCommand
+	if err := json.Unmarshal(data, &command); err != nil || command.Type == "" {
+		h.replyError(client, command.ID, errInvalidCommand)
+		return
+	}
+
+	var payload commandPayload
+	if len(command.Payload) > 0 {
+		if err := json.Unmarshal(command.Payload, &payload); err != nil {
+			h.replyError(client, command.ID, errInvalidCommand)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	result, err := h.runCommand(ctx, client, command.Type, payload)
+	if err != nil {
+		h.replyError(client, command.ID, err)
+		return
+	}
+
+	h.reply(client, WebSocketMessage{
+		Type: MsgTypeAck,
+		Payload: gin.H{
+			"id":   command.ID,
+			"type": command.Type,
+			"data": result,
+		},
+	})
+}
+
+// runCommand dispatches a command to the same ChatroomService paths used by the REST handlers
+func (h *ChatroomHandler) runCommand(ctx context.Context, client *hub.Client, commandType string, payload commandPayload) (any, error) {
+	userID := client.UserID()
+
+	// Clients send typing on every keystroke: the extra ones are acked but dropped before any lookup
+	if commandType == CmdTyping && !client.Allow(CmdTyping, typingInterval) {
+		return nil, nil
+	}
+
+	if writeCommands[commandType] {
+		sanction, err := h.sanctionService.Restriction(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if sanction.Type == models.SanctionBan {
+			return nil, errBanned
+		}
+		if sanction.ID != "" {
+			return nil, errReadOnly
+		}
+	}
+
+	switch commandType {
+	case CmdCreatePost:
+		return h.createPost(ctx, models.Post{UserID: userID, Content: payload.Content, Attachments: payload.Attachments})
+	case CmdCreateComment:
+		if payload.PostID == "" {
+			return nil, errInvalidCommand
+		}
+		return h.commentPost(ctx, payload.PostID, models.Comment{UserID: userID, Content: payload.Content})
+	case CmdReplyComment:
+		if payload.CommentID == "" {
+			return nil, errInvalidCommand
+		}
+		return h.replyComment(ctx, payload.CommentID, models.Comment{UserID: userID, Content: payload.Content})
+	case CmdLikePost:
+		if payload.PostID == "" {
+			return nil, errInvalidCommand
+		}
+		return h.likePost(ctx, payload.PostID, userID)
+	case CmdLikeComment:
+		if payload.CommentID == "" {
+			return nil, errInvalidCommand
+		}
+		return nil, h.likeComment(ctx, payload.CommentID, userID)
+	case CmdTyping:
+		if payload.PostID == "" {
+			return nil, errInvalidCommand
+		}
+		if err := h.checkPostVisible(ctx, payload.PostID); err != nil {
+			return nil, err
+		}
+		// Typing indicators are not stored with the post, but go through the bus
+		// to reach the subscribers of the thread on every replica
+		h.broadcastMessage(WebSocketMessage{
+			Type: MsgTypeTyping,
+			Payload: gin.H{
+				"postID": payload.PostID,
+				"userID": userID,
+			},
+		}, models.PostTopic(payload.PostID))
+		return nil, nil
+	case CmdSubscribe:
+		if err := h.canSubscribe(ctx, userID, payload.Topic); err != nil {
+			return nil, err
+		}
+		client.Subscribe(payload.Topic)
+		return gin.H{"topic": payload.Topic}, nil
+	case CmdUnsubscribe:
+		if _, _, ok := models.ParseTopic(payload.Topic); !ok {
+			return nil, errInvalidTopic
+		}
+		client.Unsubscribe(payload.Topic)
+		return gin.H{"topic": payload.Topic}, nil
+	case CmdSubscribePost:
+		if payload.PostID == "" {
+			return nil, errInvalidCommand
+		}
+		if err := h.checkPostVisible(ctx, payload.PostID); err != nil {
+			return nil, err
+		}
+		client.Subscribe(models.PostTopic(payload.PostID))
+		return gin.H{"postID": payload.PostID}, nil
+	case CmdUnsubscribePost:
+		if payload.PostID == "" {
+			return nil, errInvalidCommand
+		}
+		client.Unsubscribe(models.PostTopic(payload.PostID))
+		return gin.H{"postID": payload.PostID}, nil
+	default:
+		return nil, errInvalidCommand
+	}
+}
+
+// replyError sends an error reply; only validation errors are exposed to the client
+func (h *ChatroomHandler) replyError(client *hub.Client, commandID string, err error) {
+	message := "Falha ao processar comando"
+	if errors.Is(err, errInvalidCommand) || errors.Is(err, errInvalidTopic) || errors.Is(err, services.ErrContentRequired) ||
+		errors.Is(err, errReadOnly) || errors.Is(err, errBanned) ||
+		errors.Is(err, services.ErrPostNotFound) ||
+		errors.Is(err, services.ErrCommentNotFound) || errors.Is(err, services.ErrCommentDeleted) ||
+		errors.Is(err, services.ErrInvalidAttachments) || errors.Is(err, services.ErrContentRejected) {
+		message = err.Error()
+	}
+
+	h.reply(client, WebSocketMessage{
+		Type: MsgTypeError,
+		Payload: gin.H{
+			"id":      commandID,
+			"message": message,
+		},
+	})
+}
+
+// reply queues a message for a single connection
+func (h *ChatroomHandler) reply(client *hub.Client, message WebSocketMessage) {
+	messageJSON, err := json.Marshal(message)
+	if err != nil {
+		h.logger.Error("websocket_message_marshal_failed", "type", message.Type, "user_id", client.UserID(), "error", err.Error())
+		return
+	}
+	if !client.Send(messageJSON) {
+		h.logger.Warn("websocket_reply_dropped", "type", message.Type, "user_id", client.UserID(), "reason", "connection closed or queue full")
+	}
+}
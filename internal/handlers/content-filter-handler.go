@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/anamalala/internal/models"
+	"github.com/anamalala/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ContentFilterHandler struct {
+	contentFilterService *services.ContentFilterService
+}
+
+func NewContentFilterHandler(contentFilterService *services.ContentFilterService) ContentFilterHandler {
+	return ContentFilterHandler{
+		contentFilterService: contentFilterService,
+	}
+}
+
+// GetTerms lists the blocklist of the content filter
+func (h *ContentFilterHandler) GetTerms(c *gin.Context) {
+	terms, err := h.contentFilterService.GetTerms(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, "Falha ao buscar lista de bloqueio")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Lista de bloqueio obtida com sucesso",
+		"data":    terms,
+	})
+}
+
+// AddTerm adds a term, with its variants, to the blocklist
+func (h *ContentFilterHandler) AddTerm(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, "Usuário não autenticado")
+		return
+	}
+
+	var creation models.BlockedTermCreation
+	if err := c.ShouldBindJSON(&creation); err != nil {
+		c.JSON(http.StatusBadRequest, "Dados inválidos")
+		return
+	}
+
+	term, err := h.contentFilterService.AddTerm(c, userID.(string), creation)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrBlockedTermRequired) || errors.Is(err, services.ErrInvalidFilterAction):
+			c.JSON(http.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrBlockedTermExists):
+			c.JSON(http.StatusConflict, err.Error())
+		default:
+			c.JSON(http.StatusInternalServerError, "Falha ao adicionar termo")
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "Termo adicionado com sucesso",
+		"data":    term,
+	})
+}
+
+// DeleteTerm removes a term from the blocklist
+func (h *ContentFilterHandler) DeleteTerm(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.contentFilterService.DeleteTerm(c, id); err != nil {
+		if errors.Is(err, services.ErrBlockedTermNotFound) {
+			c.JSON(http.StatusNotFound, err.Error())
+			return
+		}
+		c.JSON(http.StatusInternalServerError, "Falha ao remover termo")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Termo removido com sucesso",
+		"data": gin.H{
+			"id": id,
+		},
+	})
+}
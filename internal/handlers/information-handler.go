@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -28,7 +29,7 @@ func (h *InformationHandler) Create(c *gin.Context) {
 	}
 
 	var information models.Information
-	if err := c.ShouldBindJSON(information); err != nil {
+	if err := c.ShouldBindJSON(&information); err != nil {
 		c.JSON(http.StatusBadRequest, "Dados inválidos")
 		return
 	}
@@ -45,6 +46,10 @@ func (h *InformationHandler) Create(c *gin.Context) {
 
 	createdInfo, err := h.informationService.CreateInformation(c, information, information.AuthorID)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidAttachments) {
+			c.JSON(http.StatusBadRequest, err.Error())
+			return
+		}
 		c.JSON(http.StatusInternalServerError, "Falha ao criar informação")
 		return
 	}
@@ -95,6 +100,8 @@ func (h *InformationHandler) Update(c *gin.Context) {
 		Content  string   `json:"content"`
 		ImageURL string   `json:"image_url"`
 		Tags     []string `json:"tags"`
+		// Attachments replaces the attached media when present, an empty list removes them
+		Attachments *[]string `json:"attachments"`
 	}
 
 	if err := c.ShouldBindJSON(&updateData); err != nil {
@@ -115,11 +122,19 @@ func (h *InformationHandler) Update(c *gin.Context) {
 	if updateData.Content != "" {
 		info.Content = updateData.Content
 	}
+	if updateData.Attachments != nil {
+		info.Attachments = *updateData.Attachments
+	}
 
 	// Registrar quem atualizou
+	editorID, _ := c.Get("userID")
 
-	updatedInfo, err := h.informationService.UpdateInformation(c, string(info.ID), info)
+	updatedInfo, err := h.informationService.UpdateInformation(c, string(info.ID), editorID.(string), info)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidAttachments) {
+			c.JSON(http.StatusBadRequest, err.Error())
+			return
+		}
 		c.JSON(http.StatusInternalServerError, "Falha ao atualizar informação")
 		return
 	}
@@ -145,7 +160,9 @@ func (h *InformationHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	err := h.informationService.DeleteInformation(c, infoID)
+	adminID, _ := c.Get("userID")
+
+	err := h.informationService.DeleteInformation(c, infoID, adminID.(string))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, "Falha ao excluir informação")
 		return
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/anamalala/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead is the room left in the request body for the multipart headers around the file
+const multipartOverhead = 1 << 20
+
+type MediaHandler struct {
+	mediaService services.MediaService
+}
+
+func NewMediaHandler(mediaService services.MediaService) MediaHandler {
+	return MediaHandler{
+		mediaService: mediaService,
+	}
+}
+
+// Upload stores a file sent as the "file" field of a multipart form.
+// The returned ID is used in the attachments of posts and information.
+func (h *MediaHandler) Upload(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, "Usuário não autenticado")
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.mediaService.MaxUploadSize()+multipartOverhead)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, services.ErrMediaTooLarge.Error())
+			return
+		}
+		c.JSON(http.StatusBadRequest, "Ficheiro não fornecido")
+		return
+	}
+	if header.Size > h.mediaService.MaxUploadSize() {
+		c.JSON(http.StatusRequestEntityTooLarge, services.ErrMediaTooLarge.Error())
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Ficheiro inválido")
+		return
+	}
+	defer file.Close()
+
+	media, err := h.mediaService.Upload(c, userID.(string), header.Filename, file)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrMediaTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, err.Error())
+		case errors.Is(err, services.ErrMediaTypeNotAllowed):
+			c.JSON(http.StatusUnsupportedMediaType, err.Error())
+		case errors.Is(err, services.ErrMediaEmpty) || errors.Is(err, services.ErrInvalidImage):
+			c.JSON(http.StatusBadRequest, err.Error())
+		default:
+			c.JSON(http.StatusInternalServerError, "Falha ao guardar ficheiro")
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "Ficheiro enviado com sucesso",
+		"data":    media,
+	})
+}
+
+// Download serves a file, or one of its thumbnails when ?width= is given, through a signed
+// URL returned with the media. The signature is the authorization, so the route does not require a session.
+func (h *MediaHandler) Download(c *gin.Context) {
+	media, content, err := h.mediaService.Open(c, c.Param("id"), c.Query("width"), c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidMediaURL):
+			c.JSON(http.StatusForbidden, err.Error())
+		case errors.Is(err, services.ErrMediaNotFound):
+			c.JSON(http.StatusNotFound, err.Error())
+		default:
+			c.JSON(http.StatusInternalServerError, "Falha ao obter ficheiro")
+		}
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, media.Size, media.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("inline", map[string]string{"filename": media.Filename}),
+		"Cache-Control":          "private",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/anamalala/internal/models"
+	"github.com/anamalala/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ModerationHandler struct {
+	moderationService services.ModerationService
+}
+
+func NewModerationHandler(moderationService services.ModerationService) ModerationHandler {
+	return ModerationHandler{
+		moderationService: moderationService,
+	}
+}
+
+// moderationDecision is the optional body of the approve, remove and dismiss endpoints
+type moderationDecision struct {
+	Note string `json:"note"`
+}
+
+// ReportPost reports a post to the moderators
+func (h *ModerationHandler) ReportPost(c *gin.Context) {
+	h.report(c, h.moderationService.ReportPost)
+}
+
+// ReportComment reports a comment or reply to the moderators
+func (h *ModerationHandler) ReportComment(c *gin.Context) {
+	h.report(c, h.moderationService.ReportComment)
+}
+
+// report binds the report and files it against the content in the :id parameter.
+// The response only confirms the report: reporters do not see the other reports.
+func (h *ModerationHandler) report(c *gin.Context, file func(ctx context.Context, id, reporterID string, creation models.ReportCreation) (models.ModerationItem, error)) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, "Usuário não autenticado")
+		return
+	}
+
+	var creation models.ReportCreation
+	if err := c.ShouldBindJSON(&creation); err != nil {
+		c.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	item, err := file(c, c.Param("id"), userID.(string), creation)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrReportedContentNotFound):
+			c.JSON(http.StatusNotFound, err.Error())
+		case errors.Is(err, services.ErrAlreadyReported):
+			c.JSON(http.StatusConflict, err.Error())
+		case errors.Is(err, services.ErrInvalidReportReason) || errors.Is(err, services.ErrReportOwnContent):
+			c.JSON(http.StatusBadRequest, err.Error())
+		default:
+			c.JSON(http.StatusInternalServerError, "Falha ao registar denúncia")
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "Denúncia registada com sucesso",
+		"data": gin.H{
+			"content_type": item.ContentType,
+			"content_id":   item.ContentID,
+			"hidden":       item.Hidden,
+		},
+	})
+}
+
+// GetQueue lists the moderation queue, most reported first; ?status= selects resolved items
+func (h *ModerationHandler) GetQueue(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 {
+		limit = 20
+	}
+
+	items, total, err := h.moderationService.GetQueue(c, c.Query("status"), page, limit)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidModerationStatus) {
+			c.JSON(http.StatusBadRequest, err.Error())
+			return
+		}
+		c.JSON(http.StatusInternalServerError, "Falha ao buscar denúncias")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Denúncias obtidas com sucesso",
+		"data": gin.H{
+			"items":      items,
+			"total":      total,
+			"page":       page,
+			"limit":      limit,
+			"totalPages": (total + limit - 1) / limit,
+		},
+	})
+}
+
+// GetItem returns a moderation queue item with all of its reports
+func (h *ModerationHandler) GetItem(c *gin.Context) {
+	item, err := h.moderationService.GetItem(c, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, services.ErrModerationItemNotFound) {
+			c.JSON(http.StatusNotFound, err.Error())
+			return
+		}
+		c.JSON(http.StatusInternalServerError, "Falha ao buscar denúncia")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Denúncia obtida com sucesso",
+		"data":    item,
+	})
+}
+
+// Approve keeps the reported content and closes the reports
+func (h *ModerationHandler) Approve(c *gin.Context) {
+	h.resolve(c, services.ModerationActionApprove)
+}
+
+// Remove deletes the reported content
+func (h *ModerationHandler) Remove(c *gin.Context) {
+	h.resolve(c, services.ModerationActionRemove)
+}
+
+// Dismiss closes the reports as unfounded
+func (h *ModerationHandler) Dismiss(c *gin.Context) {
+	h.resolve(c, services.ModerationActionDismiss)
+}
+
+// resolve applies a moderator decision to the item in the :id parameter
+func (h *ModerationHandler) resolve(c *gin.Context, action string) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, "Usuário não autenticado")
+		return
+	}
+
+	// The note is optional, so an empty body is accepted
+	var decision moderationDecision
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&decision); err != nil {
+			c.JSON(http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	item, err := h.moderationService.Resolve(c, c.Param("id"), userID.(string), action, decision.Note)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrModerationItemNotFound):
+			c.JSON(http.StatusNotFound, err.Error())
+		case errors.Is(err, services.ErrModerationItemResolved):
+			c.JSON(http.StatusConflict, err.Error())
+		default:
+			c.JSON(http.StatusInternalServerError, "Falha ao decidir denúncia")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Denúncia decidida com sucesso",
+		"data":    item,
+	})
+}
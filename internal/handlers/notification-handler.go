@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/anamalala/internal/models"
+	"github.com/anamalala/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	notificationService *services.NotificationService
+}
+
+func NewNotificationHandler(notificationService *services.NotificationService) NotificationHandler {
+	return NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// GetNotifications lista as notificações do usuário, das mais recentes para as mais antigas
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, "Usuário não autenticado")
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 {
+		limit = 20
+	}
+	unreadOnly := c.Query("unread") == "true"
+
+	notifications, total, err := h.notificationService.GetUserNotifications(c, userID.(string), page, limit, unreadOnly)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, "Falha ao buscar notificações")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Notificações obtidas com sucesso",
+		"data": gin.H{
+			"notifications": notifications,
+			"total":         total,
+			"page":          page,
+			"limit":         limit,
+			"totalPages":    (total + limit - 1) / limit,
+		},
+	})
+}
+
+// GetUnreadCount devolve o número de notificações por ler
+func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, "Usuário não autenticado")
+		return
+	}
+
+	count, err := h.notificationService.GetUnreadNotificationsCount(c, userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, "Falha ao contar notificações")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Total de notificações por ler obtido com sucesso",
+		"data": gin.H{
+			"unread": count,
+		},
+	})
+}
+
+// MarkAsRead marca uma notificação como lida
+func (h *NotificationHandler) MarkAsRead(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, "Usuário não autenticado")
+		return
+	}
+
+	notificationID := c.Param("id")
+	if notificationID == "" {
+		c.JSON(http.StatusBadRequest, "ID da notificação não fornecido")
+		return
+	}
+
+	if err := h.notificationService.MarkAsRead(c, notificationID, userID.(string)); err != nil {
+		notificationErrorResponse(c, err, "Falha ao marcar notificação como lida")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Notificação marcada como lida",
+	})
+}
+
+// MarkAllAsRead marca todas as notificações do usuário como lidas
+func (h *NotificationHandler) MarkAllAsRead(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, "Usuário não autenticado")
+		return
+	}
+
+	count, err := h.notificationService.MarkAllAsRead(c, userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, "Falha ao marcar notificações como lidas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Todas as notificações foram marcadas como lidas",
+		"data": gin.H{
+			"total": count,
+		},
+	})
+}
+
+// DeleteNotification remove uma notificação do usuário
+func (h *NotificationHandler) DeleteNotification(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, "Usuário não autenticado")
+		return
+	}
+
+	notificationID := c.Param("id")
+	if notificationID == "" {
+		c.JSON(http.StatusBadRequest, "ID da notificação não fornecido")
+		return
+	}
+
+	if err := h.notificationService.DeleteNotification(c, notificationID, userID.(string)); err != nil {
+		notificationErrorResponse(c, err, "Falha ao excluir notificação")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Notificação excluída com sucesso",
+	})
+}
+
+// GetPreferences devolve as preferências de notificação do usuário
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, "Usuário não autenticado")
+		return
+	}
+
+	preferences, err := h.notificationService.GetPreferences(c, userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, "Falha ao buscar preferências de notificação")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Preferências de notificação obtidas com sucesso",
+		"data":    preferences,
+	})
+}
+
+// UpdatePreferences altera os canais por tipo de notificação e as horas de silêncio do usuário
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, "Usuário não autenticado")
+		return
+	}
+
+	var request models.NotificationPreferences
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, "Dados inválidos")
+		return
+	}
+
+	preferences, err := h.notificationService.UpdatePreferences(c, userID.(string), request)
+	if err != nil {
+		notificationErrorResponse(c, err, "Falha ao atualizar preferências de notificação")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Preferências de notificação atualizadas com sucesso",
+		"data":    preferences,
+	})
+}
+
+// notificationErrorResponse converte os erros de notificação no estado HTTP adequado
+func notificationErrorResponse(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrNotificationNotFound):
+		c.JSON(http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrNotificationForbidden):
+		c.JSON(http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrInvalidPreferences):
+		c.JSON(http.StatusBadRequest, err.Error())
+	default:
+		c.JSON(http.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/anamalala/internal/models"
+	"github.com/anamalala/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type SanctionHandler struct {
+	sanctionService services.SanctionService
+}
+
+func NewSanctionHandler(sanctionService services.SanctionService) SanctionHandler {
+	return SanctionHandler{
+		sanctionService: sanctionService,
+	}
+}
+
+// Issue applies a warning, mute or ban to the user in the :id parameter
+func (h *SanctionHandler) Issue(c *gin.Context) {
+	adminID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, "Usuário não autenticado")
+		return
+	}
+
+	var creation models.SanctionCreation
+	if err := c.ShouldBindJSON(&creation); err != nil {
+		c.JSON(http.StatusBadRequest, "Dados inválidos")
+		return
+	}
+
+	sanction, err := h.sanctionService.Issue(c, adminID.(string), c.Param("id"), creation)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrSanctionUserNotFound):
+			c.JSON(http.StatusNotFound, err.Error())
+		case errors.Is(err, services.ErrSanctionStaff):
+			c.JSON(http.StatusForbidden, err.Error())
+		case errors.Is(err, services.ErrInvalidSanctionType) || errors.Is(err, services.ErrSanctionReasonRequired) ||
+			errors.Is(err, services.ErrInvalidSanctionDuration) || errors.Is(err, services.ErrSanctionSelf):
+			c.JSON(http.StatusBadRequest, err.Error())
+		default:
+			c.JSON(http.StatusInternalServerError, "Falha ao aplicar sanção")
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "Sanção aplicada com sucesso",
+		"data":    sanction,
+	})
+}
+
+// GetHistory lists the sanctions of the user in the :id parameter, newest first
+func (h *SanctionHandler) GetHistory(c *gin.Context) {
+	h.history(c, c.Param("id"))
+}
+
+// GetMySanctions lists the sanctions of the authenticated user, newest first
+func (h *SanctionHandler) GetMySanctions(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, "Usuário não autenticado")
+		return
+	}
+	h.history(c, userID.(string))
+}
+
+// history writes a page of the sanction history of a user
+func (h *SanctionHandler) history(c *gin.Context, userID string) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 {
+		limit = 20
+	}
+
+	sanctions, total, err := h.sanctionService.GetHistory(c, userID, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, "Falha ao buscar sanções")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Sanções obtidas com sucesso",
+		"data": gin.H{
+			"sanctions":  sanctions,
+			"total":      total,
+			"page":       page,
+			"limit":      limit,
+			"totalPages": (total + limit - 1) / limit,
+		},
+	})
+}
+
+// Lift ends the sanction in the :id parameter before it expires
+func (h *SanctionHandler) Lift(c *gin.Context) {
+	adminID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, "Usuário não autenticado")
+		return
+	}
+
+	var request struct {
+		Reason string `json:"reason"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, "Dados inválidos")
+			return
+		}
+	}
+
+	sanction, err := h.sanctionService.Lift(c, adminID.(string), c.Param("id"), request.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrSanctionNotFound):
+			c.JSON(http.StatusNotFound, err.Error())
+		case errors.Is(err, services.ErrSanctionNotInForce):
+			c.JSON(http.StatusConflict, err.Error())
+		case errors.Is(err, services.ErrSanctionStaff):
+			c.JSON(http.StatusForbidden, err.Error())
+		default:
+			c.JSON(http.StatusInternalServerError, "Falha ao levantar sanção")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Sanção levantada com sucesso",
+		"data":    sanction,
+	})
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/anamalala/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type SessionHandler struct {
+	sessionService services.SessionService
+}
+
+func NewSessionHandler(sessionService services.SessionService) SessionHandler {
+	return SessionHandler{
+		sessionService: sessionService,
+	}
+}
+
+// GetSessions lista os dispositivos com sessão ativa do usuário
+func (h *SessionHandler) GetSessions(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, "Usuário não autenticado")
+		return
+	}
+	sessionID, _ := c.Get("sessionID")
+	currentSessionID, _ := sessionID.(string)
+
+	sessions, err := h.sessionService.GetUserSessions(c, userID.(string), currentSessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, "Falha ao buscar sessões")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Sessões obtidas com sucesso",
+		"data": gin.H{
+			"sessions": sessions,
+			"total":    len(sessions),
+		},
+	})
+}
+
+// RevokeSession termina a sessão de um dispositivo
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, "Usuário não autenticado")
+		return
+	}
+
+	sessionID := c.Param("id")
+	if sessionID == "" {
+		c.JSON(http.StatusBadRequest, "ID da sessão não fornecido")
+		return
+	}
+
+	err := h.sessionService.RevokeSession(c, userID.(string), sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, "Sessão não encontrada")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Sessão terminada com sucesso",
+	})
+}
+
+// RevokeAllSessions termina as sessões de todos os dispositivos do usuário
+func (h *SessionHandler) RevokeAllSessions(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, "Usuário não autenticado")
+		return
+	}
+
+	total, err := h.sessionService.RevokeAllSessions(c, userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, "Falha ao terminar sessões")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Todas as sessões foram terminadas",
+		"data": gin.H{
+			"total": total,
+		},
+	})
+}
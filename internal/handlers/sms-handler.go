@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/anamalala/internal/models"
+	"github.com/anamalala/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type SMSHandler struct {
+	smsOutboxService   services.SMSOutboxService
+	smsCampaignService services.SMSCampaignService
+	webhookToken       string
+}
+
+func NewSMSHandler(
+	smsOutboxService services.SMSOutboxService,
+	smsCampaignService services.SMSCampaignService,
+	webhookToken string,
+) SMSHandler {
+	return SMSHandler{
+		smsOutboxService:   smsOutboxService,
+		smsCampaignService: smsCampaignService,
+		webhookToken:       webhookToken,
+	}
+}
+
+// DeliveryReport recebe os relatórios de entrega enviados pelo provedor de SMS.
+// O token partilhado deve vir no parâmetro token do URL de callback; sem token
+// configurado, todos os relatórios são recusados.
+func (h *SMSHandler) DeliveryReport(c *gin.Context) {
+	if h.webhookToken == "" {
+		c.JSON(http.StatusForbidden, "Relatórios de entrega desativados")
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(c.Query("token")), []byte(h.webhookToken)) != 1 {
+		c.JSON(http.StatusUnauthorized, "Token inválido")
+		return
+	}
+
+	if err := c.Request.ParseForm(); err != nil {
+		c.JSON(http.StatusBadRequest, "Dados inválidos")
+		return
+	}
+
+	if err := h.smsOutboxService.HandleDeliveryReport(c, c.Request.PostForm); err != nil {
+		c.JSON(http.StatusBadRequest, "Relatório de entrega inválido")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Relatório de entrega recebido",
+	})
+}
+
+// CreateCampaign cria uma campanha de SMS para o público indicado.
+// Com dry_run devolve apenas o número de destinatários e o custo estimado.
+func (h *SMSHandler) CreateCampaign(c *gin.Context) {
+	adminID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, "Usuário não autenticado")
+		return
+	}
+
+	var request models.SMSCampaignRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request.Message == "" {
+		c.JSON(http.StatusBadRequest, "Dados inválidos")
+		return
+	}
+
+	if request.DryRun {
+		estimate, err := h.smsCampaignService.Estimate(c, adminID.(string), request)
+		if err != nil {
+			campaignErrorResponse(c, err, "Falha ao estimar campanha")
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"status":  "success",
+			"message": "Estimativa calculada com sucesso",
+			"data":    estimate,
+		})
+		return
+	}
+
+	campaign, err := h.smsCampaignService.CreateCampaign(c, adminID.(string), request)
+	if err != nil {
+		campaignErrorResponse(c, err, "Falha ao criar campanha")
+		return
+	}
+
+	message := "Campanha enviada para a fila com sucesso"
+	if campaign.State == models.SMSCampaignScheduled {
+		message = "Campanha agendada com sucesso"
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": message,
+		"data":    campaign,
+	})
+}
+
+// CancelCampaign cancela uma campanha agendada antes do envio
+func (h *SMSHandler) CancelCampaign(c *gin.Context) {
+	campaignID := c.Param("id")
+	if campaignID == "" {
+		c.JSON(http.StatusBadRequest, "ID da campanha não fornecido")
+		return
+	}
+
+	adminID, _ := c.Get("userID")
+
+	if err := h.smsCampaignService.CancelCampaign(c, adminID.(string), campaignID); err != nil {
+		campaignErrorResponse(c, err, "Falha ao cancelar campanha")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Campanha cancelada com sucesso",
+	})
+}
+
+// GetCampaigns lista as campanhas de SMS enviadas
+func (h *SMSHandler) GetCampaigns(c *gin.Context) {
+	adminID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, "Usuário não autenticado")
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	campaigns, total, err := h.smsCampaignService.ListCampaigns(c, adminID.(string), page, limit)
+	if err != nil {
+		campaignErrorResponse(c, err, "Falha ao buscar campanhas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Campanhas obtidas com sucesso",
+		"data": gin.H{
+			"campaigns":  campaigns,
+			"total":      total,
+			"page":       page,
+			"limit":      limit,
+			"totalPages": (total + limit - 1) / limit,
+		},
+	})
+}
+
+// GetCampaignStatus mostra quantas mensagens de uma campanha estão em fila, enviadas, falhadas ou entregues
+func (h *SMSHandler) GetCampaignStatus(c *gin.Context) {
+	adminID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, "Usuário não autenticado")
+		return
+	}
+
+	campaignID := c.Param("id")
+	if campaignID == "" {
+		c.JSON(http.StatusBadRequest, "ID da campanha não fornecido")
+		return
+	}
+
+	status, err := h.smsCampaignService.GetCampaignStatus(c, adminID.(string), campaignID)
+	if err != nil {
+		campaignErrorResponse(c, err, "Falha ao buscar campanha")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Estado da campanha obtido com sucesso",
+		"data":    status,
+	})
+}
+
+// campaignErrorResponse converte os erros de campanha no estado HTTP adequado
+func campaignErrorResponse(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrCampaignNotFound):
+		c.JSON(http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrCampaignNotCancellable):
+		c.JSON(http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrCampaignOutsideProvince),
+		errors.Is(err, services.ErrCampaignNotOwner):
+		c.JSON(http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrCampaignNoRecipients),
+		errors.Is(err, services.ErrCampaignInvalidTemplate),
+		errors.Is(err, services.ErrCampaignInvalidSchedule):
+		c.JSON(http.StatusBadRequest, err.Error())
+	default:
+		c.JSON(http.StatusInternalServerError, fallback)
+	}
+}
@@ -9,12 +9,14 @@ import (
 )
 
 type UserHandler struct {
-	userService services.UserService
+	userService     services.UserService
+	presenceService *services.PresenceService
 }
 
-func NewUserHandler(userService services.UserService) UserHandler {
+func NewUserHandler(userService services.UserService, presenceService *services.PresenceService) UserHandler {
 	return UserHandler{
-		userService: userService,
+		userService:     userService,
+		presenceService: presenceService,
 	}
 }
 
@@ -160,23 +162,20 @@ func (h *UserHandler) GetAllUsers(c *gin.Context) {
 		},
 	})
 }
+// GetTotalOnline devolve quantos usuários estão online, no total e por província
 func (h *UserHandler) GetTotalOnline(c *gin.Context) {
-	users, total, err := h.userService.GetAllUsers(c, 0, 0)
+	stats, err := h.presenceService.GetOnlineStats(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, "Falha ao buscar usuários")
+		c.JSON(http.StatusInternalServerError, "Falha ao contar usuários online")
 		return
 	}
 
-	// Remover senhas da resposta
-	for i := range users {
-		users[i].Password = ""
-	}
-
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "success",
-		"message": "Usuários obtidos com sucesso",
+		"message": "Usuários online obtidos com sucesso",
 		"data": gin.H{
-			"total": total,
+			"total":     stats.Total,
+			"provinces": stats.Provinces,
 		},
 	})
 }
@@ -3,21 +3,26 @@ package middlewares
 import (
 	"net/http"
 
+	"github.com/anamalala/internal/models"
+	"github.com/anamalala/internal/services"
 	"github.com/anamalala/internal/utils"
 	"github.com/gin-gonic/gin"
 )
 
 type AdminMiddlewares struct {
-	tokenUtil utils.TokenUtil
+	tokenUtil       utils.TokenUtil
+	sanctionService services.SanctionService
 }
 
-func NewAdminMiddleware(tokenUtil utils.TokenUtil) AdminMiddlewares {
+func NewAdminMiddleware(tokenUtil utils.TokenUtil, sanctionService services.SanctionService) AdminMiddlewares {
 	return AdminMiddlewares{
-		tokenUtil: tokenUtil,
+		tokenUtil:       tokenUtil,
+		sanctionService: sanctionService,
 	}
 }
 
-// AdminMiddleware verifica se o usuário é um administrador
+// AdminMiddleware verifica se o usuário pertence à equipa: um administrador ou outro role com permissões.
+// Cada rota administrativa exige ainda a sua permissão com RequirePermission.
 func (md *AdminMiddlewares) AdminMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Verificar se o usuário está autenticado
@@ -35,28 +40,51 @@ func (md *AdminMiddlewares) AdminMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Verificar se o usuário é um administrador
-		if userRole != "admin" {
+		// Verificar se o usuário tem algum role da equipa
+		if !models.Role(userRole).IsStaff() {
 			c.JSON(http.StatusForbidden, gin.H{"error": "acesso restrito a administradores"})
 			c.Abort()
 			return
 		}
 
+		// Os handlers administrativos verificam esta chave
+		c.Set("isAdmin", true)
+
 		c.Next()
 	}
 }
 
-// BannedUserMiddleware verifica se o usuário está banido e impede o acesso
-func (md *AdminMiddlewares) BannedUserMiddleware(userService interface {
-	IsBanned(userID string) (bool, error)
-}) gin.HandlerFunc {
+// RequirePermission verifica se o role do usuário concede todas as permissões indicadas.
+// Deve ser usado depois do AuthMiddleware.
+func (md *AdminMiddlewares) RequirePermission(permissions ...models.Permission) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Verificar se o usuário está autenticado
 		if !isAuthenticated(c) {
-			c.Next()
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "não autenticado"})
+			c.Abort()
 			return
 		}
 
+		userRole, _ := getUserRole(c)
+		for _, permission := range permissions {
+			if !models.Role(userRole).Can(permission) {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":      "permissão insuficiente",
+					"permission": permission,
+				})
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// BannedUserMiddleware impede os usuários suspensos ou em modo só de leitura de escrever.
+// Deve ser usado nas rotas que criam ou alteram conteúdo, depois do AuthMiddleware.
+func (md *AdminMiddlewares) BannedUserMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
 		// Obter o ID do usuário
 		userID, exists := getUserID(c)
 		if !exists {
@@ -64,16 +92,24 @@ func (md *AdminMiddlewares) BannedUserMiddleware(userService interface {
 			return
 		}
 
-		// Verificar se o usuário está banido
-		banned, err := userService.IsBanned(userID)
+		// Verificar se o usuário tem uma sanção em vigor
+		sanction, err := md.sanctionService.Restriction(c, userID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao verificar status do usuário"})
 			c.Abort()
 			return
 		}
 
-		if banned {
-			c.JSON(http.StatusForbidden, gin.H{"error": "usuário está banido"})
+		if sanction.ID != "" {
+			message := "conta em modo só de leitura"
+			if sanction.Type == models.SanctionBan {
+				message = "usuário está banido"
+			}
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":      message,
+				"reason":     sanction.Reason,
+				"expires_at": sanction.ExpiresAt,
+			})
 			c.Abort()
 			return
 		}
@@ -82,8 +118,9 @@ func (md *AdminMiddlewares) BannedUserMiddleware(userService interface {
 	}
 }
 
-// ContentOwnerOrAdminMiddleware verifica se o usuário é o proprietário do conteúdo ou um administrador
-func (md *AdminMiddlewares) ContentOwnerOrAdminMiddleware(getOwnerIDFunc func(contentID string) (string, error)) gin.HandlerFunc {
+// ContentOwnerOrPermissionMiddleware verifica se o usuário é o proprietário do conteúdo
+// ou tem um role com a permissão indicada, como post.delete.any
+func (md *AdminMiddlewares) ContentOwnerOrPermissionMiddleware(permission models.Permission, getOwnerIDFunc func(contentID string) (string, error)) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Verificar se o usuário está autenticado
 		if !isAuthenticated(c) {
@@ -96,8 +133,8 @@ func (md *AdminMiddlewares) ContentOwnerOrAdminMiddleware(getOwnerIDFunc func(co
 		userID, _ := getUserID(c)
 		userRole, _ := getUserRole(c)
 
-		// Se o usuário for administrador, permitir acesso
-		if userRole == "admin" {
+		// Se o role do usuário conceder a permissão, permitir acesso
+		if models.Role(userRole).Can(permission) {
 			c.Next()
 			return
 		}
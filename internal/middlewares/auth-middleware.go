@@ -11,14 +11,18 @@ import (
 )
 
 type AuthMiddlewares struct {
-	tokenUtil   utils.TokenUtil
-	userservice services.UserService
+	tokenUtil       utils.TokenUtil
+	userservice     services.UserService
+	sessionService  services.SessionService
+	presenceService *services.PresenceService
 }
 
-func NewAuthMiddleware(tokenUtil utils.TokenUtil, userservice services.UserService) AuthMiddlewares {
+func NewAuthMiddleware(tokenUtil utils.TokenUtil, userservice services.UserService, sessionService services.SessionService, presenceService *services.PresenceService) AuthMiddlewares {
 	return AuthMiddlewares{
-		tokenUtil:   tokenUtil,
-		userservice: userservice,
+		tokenUtil:       tokenUtil,
+		userservice:     userservice,
+		sessionService:  sessionService,
+		presenceService: presenceService,
 	}
 }
 
@@ -61,19 +65,45 @@ func (m *AuthMiddlewares) AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		if !user.IsLoggedIn {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "usuario nao autenticado, entre de novo"})
+		if user.ID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "usuario inválido"})
 			c.Abort()
 			return
 		}
 
-		// Adicionar ID do usuário e role ao contexto
+		// As sessões são revogadas na suspensão; esta verificação cobre tokens ainda válidos
+		if !user.Active {
+			c.JSON(http.StatusForbidden, gin.H{"error": "conta suspensa"})
+			c.Abort()
+			return
+		}
+
+		// Verificar se a sessão do dispositivo continua ativa
+		active, err := m.sessionService.IsSessionActive(c, claims.SessionID, claims.UserID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao verificar sessão"})
+			c.Abort()
+			return
+		}
+
+		if !active {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "sessão terminada, entre de novo"})
+			c.Abort()
+			return
+		}
+
+		// Registar atividade para a presença online
+		m.presenceService.Touch(claims.UserID)
+
+		// Adicionar ID do usuário, role e sessão ao contexto.
+		// O role vem da conta e não do token, para que uma mudança de role valha logo.
 		c.Set("userID", claims.UserID)
-		c.Set("userRole", claims.Role)
+		c.Set("userRole", string(user.Role))
+		c.Set("sessionID", claims.SessionID)
 
 		// Adicionar ao contexto para uso nos serviços
 		ctx := context.WithValue(c.Request.Context(), "userID", claims.UserID)
-		ctx = context.WithValue(ctx, "userRole", claims.Role)
+		ctx = context.WithValue(ctx, "userRole", string(user.Role))
 		c.Request = c.Request.WithContext(ctx)
 
 		c.Next()
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+)
+
+// AuditAction identifies the kind of admin action recorded in the audit log
+type AuditAction string
+
+const (
+	AuditUserWarn    AuditAction = "user.warn"
+	AuditUserMute    AuditAction = "user.mute"
+	AuditUserUnmute  AuditAction = "user.unmute"
+	AuditUserBan     AuditAction = "user.ban"
+	AuditUserUnban   AuditAction = "user.unban"
+	AuditUserPromote AuditAction = "user.promote"
+	AuditUserDemote  AuditAction = "user.demote"
+	AuditUserDelete  AuditAction = "user.delete"
+
+	AuditPostDelete    AuditAction = "post.delete"
+	AuditCommentDelete AuditAction = "comment.delete"
+	// AuditModerationResolve records the decision taken on an item of the moderation queue
+	AuditModerationResolve AuditAction = "moderation.resolve"
+
+	AuditInfoCreate AuditAction = "info.create"
+	AuditInfoUpdate AuditAction = "info.update"
+	AuditInfoDelete AuditAction = "info.delete"
+
+	// AuditSMSSend records an SMS campaign, sent at once or scheduled
+	AuditSMSSend   AuditAction = "sms.send"
+	AuditSMSCancel AuditAction = "sms.cancel"
+)
+
+// Kinds of targets of the admin actions
+const (
+	AuditTargetUser           = "user"
+	AuditTargetPost           = "post"
+	AuditTargetComment        = "comment"
+	AuditTargetInformation    = "information"
+	AuditTargetSMSCampaign    = "sms_campaign"
+	AuditTargetModerationItem = "moderation_item"
+)
+
+// AuditEntry represents an admin action in the audit log. Entries are only ever appended.
+// Before and After hold the target as it was before and after the action, and are
+// empty when the target did not exist before (a creation) or no longer exists (a deletion).
+// AdminName is copied when the entry is recorded so it survives the admin account.
+type AuditEntry struct {
+	ID         string         `bson:"_id,omitempty" json:"id"`
+	AdminID    string         `bson:"admin_id" json:"admin_id"`
+	AdminName  string         `bson:"admin_name" json:"admin_name"`
+	Action     AuditAction    `bson:"action" json:"action"`
+	TargetType string         `bson:"target_type" json:"target_type"`
+	TargetID   string         `bson:"target_id" json:"target_id"`
+	Reason     string         `bson:"reason,omitempty" json:"reason,omitempty"`
+	Before     map[string]any `bson:"before,omitempty" json:"before,omitempty"`
+	After      map[string]any `bson:"after,omitempty" json:"after,omitempty"`
+	CreatedAt  time.Time      `bson:"created_at" json:"created_at"`
+}
+
+// AuditEntries represents a slice of AuditEntry
+type AuditEntries []AuditEntry
+
+// AuditFilter selects audit entries; empty fields match every entry.
+// From is inclusive and To is exclusive.
+type AuditFilter struct {
+	AdminID string
+	Action  AuditAction
+	From    time.Time
+	To      time.Time
+}
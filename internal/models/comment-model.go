@@ -8,6 +8,7 @@ import (
 type Comment struct {
 	ID          string    `bson:"_id,omitempty" json:"id,omitempty"`
 	ReferenceID   string    `bson:"reference_id" json:"reference_id"`
+	PostID      string    `bson:"post_id,omitempty" json:"post_id,omitempty"`
 	UserID      string    `bson:"user_id" json:"user_id"`
 	Author      Author    `bson:"author" json:"author"`
 	Content     string    `bson:"content" json:"content" validate:"required"`
@@ -15,8 +16,17 @@ type Comment struct {
 	UpdatedAt   time.Time `bson:"updated_at" json:"updated_at"`
 	DeletedAt   time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
 	Comments    Comments  `bson:"comments,omitempty" json:"comments,omitempty"`
+	ReplyCount  int       `bson:"reply_count,omitempty" json:"reply_count"`
 	Likes       int       `bson:"likes" json:"likes"`
 	Reference   string    `bson:"reference" json:"reference"`
+	// Ancestors holds the IDs of the comments above this one, from the top-level comment down to the parent
+	Ancestors   []string  `bson:"ancestors,omitempty" json:"ancestors,omitempty"`
+	Depth       int       `bson:"depth" json:"depth"`
+	// Deleted marks a removed comment kept as a placeholder because it still has replies
+	Deleted     bool      `bson:"deleted,omitempty" json:"deleted,omitempty"`
+	// Hidden is set while the comment waits for a moderator, after passing the report threshold
+	// or being held by the content filter
+	Hidden      bool      `bson:"hidden,omitempty" json:"hidden,omitempty"`
 	LikedUserId []string  `bson:"likeduserid,omitempty" json:"likeduserid,omitempty"`
 }
 
@@ -35,5 +45,18 @@ type CommentCreation struct {
 	Content string `json:"content" validate:"required"`
 }
 
+// CommentPreview sets how many comments of each post, and replies of each comment,
+// are loaded together with a list of posts
+type CommentPreview struct {
+	Comments int64
+	Replies  int64
+}
+
+// CommentReplies holds the first replies to a comment in a thread and its total number of replies
+type CommentReplies struct {
+	Replies Comments
+	Total   int
+}
+
 // Comments represents a slice of Comment
 type Comments []Comment
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+// BlockedTerm represents a word or expression of the content filter blocklist, managed by the admins.
+// Variants hold other spellings and the same term in the local languages; a trailing "*"
+// also matches the words starting with it. Action is one of reject, mask or hold.
+type BlockedTerm struct {
+	ID         string    `bson:"_id,omitempty" json:"id"`
+	Term       string    `bson:"term" json:"term"`
+	Normalized string    `bson:"normalized" json:"-"`
+	Variants   []string  `bson:"variants,omitempty" json:"variants,omitempty"`
+	Language   string    `bson:"language,omitempty" json:"language,omitempty"`
+	Action     string    `bson:"action" json:"action"`
+	CreatedBy  string    `bson:"created_by" json:"created_by"`
+	CreatedAt  time.Time `bson:"created_at" json:"created_at"`
+}
+
+// BlockedTerms represents a slice of BlockedTerm
+type BlockedTerms []BlockedTerm
+
+// BlockedTermCreation represents data for adding a term to the blocklist
+type BlockedTermCreation struct {
+	Term     string   `json:"term" validate:"required"`
+	Variants []string `json:"variants"`
+	Language string   `json:"language"`
+	Action   string   `json:"action"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cursor marks a position in a list ordered by (created_at, _id).
+// Clients receive it as an opaque string and send it back to get the next page.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// IsZero reports whether the cursor points to the start of the list
+func (c Cursor) IsZero() bool {
+	return c.ID == "" && c.CreatedAt.IsZero()
+}
+
+// Encode returns the opaque string sent to clients
+func (c Cursor) Encode() string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixMilli(), 10) + ":" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseCursor decodes a cursor produced by Encode; an empty string is the start of the list
+func ParseCursor(value string) (Cursor, error) {
+	if value == "" {
+		return Cursor{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return Cursor{}, err
+	}
+
+	millis, id, found := strings.Cut(string(raw), ":")
+	if !found || id == "" {
+		return Cursor{}, errors.New("cursor malformed")
+	}
+	ms, err := strconv.ParseInt(millis, 10, 64)
+	if err != nil {
+		return Cursor{}, err
+	}
+
+	return Cursor{CreatedAt: time.UnixMilli(ms), ID: id}, nil
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor Cursor
+	}{
+		{"object id", Cursor{CreatedAt: time.UnixMilli(1760000000123), ID: "652f1c2e9d3b4a0012345678"}},
+		{"id with separator", Cursor{CreatedAt: time.UnixMilli(1760000000000), ID: "a:b"}},
+		{"before 1970", Cursor{CreatedAt: time.UnixMilli(-1000), ID: "old"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCursor(tt.cursor.Encode())
+			if err != nil {
+				t.Fatalf("ParseCursor: %v", err)
+			}
+			if !got.CreatedAt.Equal(tt.cursor.CreatedAt) || got.ID != tt.cursor.ID {
+				t.Errorf("expected %+v, got %+v", tt.cursor, got)
+			}
+		})
+	}
+}
+
+func TestCursorEncodeTruncatesToMilliseconds(t *testing.T) {
+	createdAt := time.Date(2026, 10, 16, 12, 0, 0, 123456789, time.UTC)
+
+	got, err := ParseCursor(Cursor{CreatedAt: createdAt, ID: "post-1"}.Encode())
+	if err != nil {
+		t.Fatalf("ParseCursor: %v", err)
+	}
+	// MongoDB stores dates with millisecond precision, so the cursor does the same
+	if want := createdAt.Truncate(time.Millisecond); !got.CreatedAt.Equal(want) {
+		t.Errorf("expected %v, got %v", want, got.CreatedAt)
+	}
+}
+
+func TestCursorEncodeIsURLSafe(t *testing.T) {
+	encoded := Cursor{CreatedAt: time.UnixMilli(1760000000123), ID: "??>>~~"}.Encode()
+
+	for _, r := range encoded {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			t.Fatalf("expected an URL safe cursor, got %q", encoded)
+		}
+	}
+}
+
+func TestParseCursor(t *testing.T) {
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		want    Cursor
+		wantErr bool
+	}{
+		{"empty is the start of the list", "", Cursor{}, false},
+		{"valid", encode("1760000000123:post-1"), Cursor{CreatedAt: time.UnixMilli(1760000000123), ID: "post-1"}, false},
+		{"not base64", "not a cursor!", Cursor{}, true},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte("1760000000123:post-1")), Cursor{}, true},
+		{"missing separator", encode("1760000000123"), Cursor{}, true},
+		{"missing id", encode("1760000000123:"), Cursor{}, true},
+		{"time not a number", encode("yesterday:post-1"), Cursor{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCursor(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if !got.CreatedAt.Equal(tt.want.CreatedAt) || got.ID != tt.want.ID {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+
+	if cursor, _ := ParseCursor(""); !cursor.IsZero() {
+		t.Errorf("expected the empty cursor to be zero, got %+v", cursor)
+	}
+}
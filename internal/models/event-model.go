@@ -0,0 +1,150 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Event types broadcast to chatroom clients
+const (
+	EventNewPost       = "new_post"
+	EventDeletePost    = "delete_post"
+	EventNewComment    = "new_comment"
+	EventDeleteComment = "delete_comment"
+	EventLikePost      = "like_post"
+	EventLikeComment   = "like_comment"
+	EventPresence      = "presence"
+)
+
+// Topics that are not tied to a post or a user
+const (
+	TopicFeed     = "feed"
+	TopicPresence = "presence"
+)
+
+// Prefixes of the per-post and per-user topics
+const (
+	postTopicPrefix = "post:"
+	userTopicPrefix = "user:"
+)
+
+// PostTopic returns the topic of a post thread
+func PostTopic(postID string) string {
+	return postTopicPrefix + postID
+}
+
+// UserTopic returns the topic of the events about a user's own content
+func UserTopic(userID string) string {
+	return userTopicPrefix + userID
+}
+
+// ParseTopic splits a topic into its kind (feed, presence, post or user) and ID
+func ParseTopic(topic string) (kind string, id string, ok bool) {
+	switch {
+	case topic == TopicFeed:
+		return TopicFeed, "", true
+	case topic == TopicPresence:
+		return TopicPresence, "", true
+	case strings.HasPrefix(topic, postTopicPrefix) && len(topic) > len(postTopicPrefix):
+		return "post", strings.TrimPrefix(topic, postTopicPrefix), true
+	case strings.HasPrefix(topic, userTopicPrefix) && len(topic) > len(userTopicPrefix):
+		return "user", strings.TrimPrefix(topic, userTopicPrefix), true
+	}
+	return "", "", false
+}
+
+// CommentTopics returns the topics that receive the events of a comment.
+// Comments created before post_id was stored fall back to the feed.
+func CommentTopics(comment Comment) []string {
+	switch {
+	case comment.PostID != "":
+		return []string{PostTopic(comment.PostID)}
+	case comment.Reference == "post" && comment.ReferenceID != "":
+		return []string{PostTopic(comment.ReferenceID)}
+	}
+	return []string{TopicFeed}
+}
+
+// PresenceChange is the payload of a presence event
+type PresenceChange struct {
+	UserID     string    `json:"userID"`
+	Online     bool      `json:"online"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+// Event represents a chatroom change broadcast to every API replica.
+// Topics lists who receives it; an event without topics goes to every connection.
+type Event struct {
+	Type    string   `json:"type"`
+	Topics  []string `json:"topics,omitempty"`
+	Payload any      `json:"payload"`
+}
+
+// The constructors below build the chatroom events with the same topics and payload
+// whichever bus carries them, so clients see no difference between the backends.
+
+// NewPostEvent announces a post shown in the feed, when created or approved by a moderator
+func NewPostEvent(post Post) Event {
+	return Event{Type: EventNewPost, Topics: []string{TopicFeed}, Payload: post}
+}
+
+// DeletePostEvent tells clients to remove a deleted or hidden post
+func DeletePostEvent(postID string) Event {
+	return Event{
+		Type:    EventDeletePost,
+		Topics:  []string{TopicFeed, PostTopic(postID)},
+		Payload: map[string]any{"postID": postID},
+	}
+}
+
+// NewCommentEvent announces a comment or reply, when created or approved by a moderator
+func NewCommentEvent(comment Comment) Event {
+	payload := map[string]any{"comment": comment}
+	if comment.Reference == "comment" {
+		payload["referenceId"] = comment.ReferenceID
+	} else {
+		payload["postID"] = comment.ReferenceID
+	}
+	return Event{Type: EventNewComment, Topics: CommentTopics(comment), Payload: payload}
+}
+
+// DeleteCommentEvent tells clients to remove a deleted or hidden comment.
+// A placeholder stays in the thread, so clients should blank it instead of removing it.
+func DeleteCommentEvent(comment Comment, placeholder bool) Event {
+	return Event{
+		Type:   EventDeleteComment,
+		Topics: CommentTopics(comment),
+		Payload: map[string]any{
+			"commentID":    comment.ID,
+			"reference_id": comment.ReferenceID,
+			"placeholder":  placeholder,
+		},
+	}
+}
+
+// LikePostEvent announces that userID liked or unliked a post
+func LikePostEvent(post Post, userID string) Event {
+	return Event{
+		Type:   EventLikePost,
+		Topics: []string{TopicFeed, PostTopic(post.ID), UserTopic(post.UserID)},
+		Payload: map[string]any{
+			"post":   post,
+			"postID": post.ID,
+			"userID": userID,
+		},
+	}
+}
+
+// LikeCommentEvent announces that userID liked or unliked a comment
+func LikeCommentEvent(comment Comment, userID string) Event {
+	return Event{
+		Type:   EventLikeComment,
+		Topics: append(CommentTopics(comment), UserTopic(comment.UserID)),
+		Payload: map[string]any{
+			"commentID":    comment.ID,
+			"reference":    comment.Reference,
+			"reference_id": comment.ReferenceID,
+			"userID":       userID,
+		},
+	}
+}
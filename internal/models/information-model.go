@@ -25,6 +25,8 @@ type Information struct {
 	CreatedAt   time.Time       `bson:"created_at" json:"created_at"`
 	UpdatedAt   time.Time       `bson:"updated_at" json:"updated_at"`
 	PublishedAt time.Time       `bson:"published_at,omitempty" json:"published_at,omitempty"`
+	// Media describes the attachments, with signed download URLs, when the information is returned to clients
+	Media MediaList `bson:"-" json:"media,omitempty"`
 }
 
 // InformationResponse represents the information data returned to clients
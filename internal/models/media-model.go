@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+// Media represents a file uploaded by a user and kept in the media storage.
+// Files with the same content are stored once; each user who uploads them gets their own Media.
+type Media struct {
+	ID          string    `bson:"_id,omitempty" json:"id"`
+	UserID      string    `bson:"user_id" json:"user_id"`
+	Hash        string    `bson:"hash" json:"-"`
+	StorageKey  string    `bson:"storage_key" json:"-"`
+	Filename    string    `bson:"filename" json:"filename"`
+	ContentType string    `bson:"content_type" json:"content_type"`
+	Kind        PostType  `bson:"kind" json:"kind"`
+	Size        int64     `bson:"size" json:"size"`
+	Width       int       `bson:"width,omitempty" json:"width,omitempty"`
+	Height      int       `bson:"height,omitempty" json:"height,omitempty"`
+	CreatedAt   time.Time `bson:"created_at" json:"created_at"`
+	// Variants are the smaller versions generated for images, narrowest first
+	Variants []MediaVariant `bson:"variants,omitempty" json:"variants,omitempty"`
+	// URL is a signed download link, filled when the media is returned to clients
+	URL          string    `bson:"-" json:"url,omitempty"`
+	URLExpiresAt time.Time `bson:"-" json:"url_expires_at,omitempty"`
+}
+
+// MediaVariant represents a thumbnail of an image, stored next to the original
+type MediaVariant struct {
+	Width       int    `bson:"width" json:"width"`
+	Height      int    `bson:"height" json:"height"`
+	ContentType string `bson:"content_type" json:"content_type"`
+	StorageKey  string `bson:"storage_key" json:"-"`
+	Size        int64  `bson:"size" json:"size"`
+	URL         string `bson:"-" json:"url,omitempty"`
+}
+
+// MediaList represents a slice of Media
+type MediaList []Media
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+)
+
+// ReportReason represents the category chosen by a user when reporting content
+type ReportReason string
+
+const (
+	ReportReasonSpam           ReportReason = "spam"
+	ReportReasonHarassment     ReportReason = "harassment"
+	ReportReasonHateSpeech     ReportReason = "hate_speech"
+	ReportReasonViolence       ReportReason = "violence"
+	ReportReasonSexualContent  ReportReason = "sexual_content"
+	ReportReasonMisinformation ReportReason = "misinformation"
+	ReportReasonOther          ReportReason = "other"
+	// ReportReasonContentFilter marks content held by the content filter; users cannot choose it
+	ReportReasonContentFilter ReportReason = "content_filter"
+)
+
+// Valid reports whether the reason is one of the known categories
+func (r ReportReason) Valid() bool {
+	switch r {
+	case ReportReasonSpam, ReportReasonHarassment, ReportReasonHateSpeech, ReportReasonViolence,
+		ReportReasonSexualContent, ReportReasonMisinformation, ReportReasonOther:
+		return true
+	}
+	return false
+}
+
+// ModerationStatus represents the state of an item in the moderation queue
+type ModerationStatus string
+
+const (
+	ModerationStatusPending ModerationStatus = "pending"
+	// Approved content was reviewed and kept
+	ModerationStatusApproved ModerationStatus = "approved"
+	// Removed content was reviewed and deleted
+	ModerationStatusRemoved ModerationStatus = "removed"
+	// Dismissed reports were closed without judging the content
+	ModerationStatusDismissed ModerationStatus = "dismissed"
+)
+
+// Report represents a single user report on a post or comment.
+// Reports made by the content filter have no ReporterID.
+type Report struct {
+	ReporterID string       `bson:"reporter_id" json:"reporter_id"`
+	Reason     ReportReason `bson:"reason" json:"reason"`
+	Details    string       `bson:"details,omitempty" json:"details,omitempty"`
+	CreatedAt  time.Time    `bson:"created_at" json:"created_at"`
+}
+
+// ReportCreation represents data for reporting a post or comment
+type ReportCreation struct {
+	Reason  ReportReason `json:"reason" validate:"required"`
+	Details string       `json:"details"`
+}
+
+// ModerationItem represents reported content waiting for, or resolved by, a moderator.
+// All the reports on the same content while it is pending are grouped in one item,
+// with at most one report per reporter. Excerpt keeps a copy of the content as it was first reported.
+type ModerationItem struct {
+	ID          string           `bson:"_id,omitempty" json:"id"`
+	ContentType string           `bson:"content_type" json:"content_type"`
+	ContentID   string           `bson:"content_id" json:"content_id"`
+	PostID      string           `bson:"post_id,omitempty" json:"post_id,omitempty"`
+	AuthorID    string           `bson:"author_id" json:"author_id"`
+	Excerpt     string           `bson:"excerpt" json:"excerpt"`
+	Status      ModerationStatus `bson:"status" json:"status"`
+	Reports     []Report         `bson:"reports" json:"reports"`
+	ReportCount int              `bson:"report_count" json:"report_count"`
+	Hidden      bool             `bson:"hidden" json:"hidden"`
+	ResolvedBy  string           `bson:"resolved_by,omitempty" json:"resolved_by,omitempty"`
+	ResolvedAt  time.Time        `bson:"resolved_at,omitempty" json:"resolved_at,omitempty"`
+	Note        string           `bson:"note,omitempty" json:"note,omitempty"`
+	CreatedAt   time.Time        `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time        `bson:"updated_at" json:"updated_at"`
+}
+
+// ModerationItems represents a slice of ModerationItem
+type ModerationItems []ModerationItem
@@ -12,6 +12,19 @@ const (
 	NotificationTypeChat   NotificationType = "chat"
 	NotificationTypeInfo   NotificationType = "info"
 	NotificationTypeAdmin  NotificationType = "admin"
+	NotificationTypeComment NotificationType = "comment"
+	NotificationTypeReply   NotificationType = "reply"
+	NotificationTypeLike    NotificationType = "like"
+	NotificationTypeModeration NotificationType = "moderation"
+)
+
+// NotificationPriority represents how urgently a notification must reach the user
+type NotificationPriority string
+
+const (
+	NotificationPriorityNormal NotificationPriority = "normal"
+	// High priority notifications fall back to SMS when the user is offline
+	NotificationPriorityHigh NotificationPriority = "high"
 )
 
 // Notification represents a notification for a user
@@ -19,6 +32,7 @@ type Notification struct {
 	ID        string           `bson:"_id,omitempty" json:"id,omitempty"`
 	UserID    string           `bson:"user_id" json:"user_id"`
 	Type      NotificationType `bson:"type" json:"type"`
+	Priority  NotificationPriority `bson:"priority,omitempty" json:"priority,omitempty"`
 	Title     string           `bson:"title" json:"title"`
 	Message   string           `bson:"message" json:"message"`
 	Read      bool             `bson:"read" json:"read"`
@@ -43,6 +57,7 @@ type NotificationResponse struct {
 type NotificationCreation struct {
 	UserID    string           `json:"user_id" validate:"required"`
 	Type      NotificationType `json:"type" validate:"required"`
+	Priority  NotificationPriority `json:"priority,omitempty"`
 	Title     string           `json:"title" validate:"required"`
 	Message   string           `json:"message" validate:"required"`
 	Reference string          `json:"reference,omitempty"`
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+)
+
+// NotificationChannels represents the channels through which a notification type may reach the user
+type NotificationChannels struct {
+	// InApp keeps the notification in the user's notification list
+	InApp bool `bson:"in_app" json:"in_app"`
+	// WebSocket pushes the notification to open connections
+	WebSocket bool `bson:"websocket" json:"websocket"`
+	// SMS allows high priority notifications and campaigns to be sent by SMS
+	SMS bool `bson:"sms" json:"sms"`
+}
+
+// QuietHours represents a daily period in which no SMS is sent to the user.
+// Start and End use the HH:MM format; a period such as 22:00-07:00 crosses midnight.
+type QuietHours struct {
+	Enabled  bool   `bson:"enabled" json:"enabled"`
+	Start    string `bson:"start" json:"start"`
+	End      string `bson:"end" json:"end"`
+	Timezone string `bson:"timezone" json:"timezone"`
+}
+
+// NotificationPreferences represents the notification settings of a user
+type NotificationPreferences struct {
+	UserID     string                                    `bson:"_id" json:"user_id"`
+	Types      map[NotificationType]NotificationChannels `bson:"types" json:"types"`
+	QuietHours QuietHours                                `bson:"quiet_hours" json:"quiet_hours"`
+	UpdatedAt  time.Time                                 `bson:"updated_at" json:"updated_at"`
+}
+
+// NotificationTypes lists every notification type users can configure
+var NotificationTypes = []NotificationType{
+	NotificationTypeSystem,
+	NotificationTypeChat,
+	NotificationTypeInfo,
+	NotificationTypeAdmin,
+	NotificationTypeComment,
+	NotificationTypeReply,
+	NotificationTypeLike,
+	NotificationTypeModeration,
+}
+
+// DefaultNotificationPreferences returns the settings used until the user changes them.
+// SMS is only enabled by default for system, information and administrative messages.
+func DefaultNotificationPreferences(userID string) NotificationPreferences {
+	types := make(map[NotificationType]NotificationChannels, len(NotificationTypes))
+	for _, notificationType := range NotificationTypes {
+		types[notificationType] = NotificationChannels{InApp: true, WebSocket: true}
+	}
+	for _, notificationType := range []NotificationType{NotificationTypeSystem, NotificationTypeInfo, NotificationTypeAdmin} {
+		channels := types[notificationType]
+		channels.SMS = true
+		types[notificationType] = channels
+	}
+
+	return NotificationPreferences{
+		UserID: userID,
+		Types:  types,
+		QuietHours: QuietHours{
+			Start:    "22:00",
+			End:      "07:00",
+			Timezone: "Africa/Maputo",
+		},
+	}
+}
+
+// Channels returns the channels enabled for a notification type, using the defaults for unknown entries
+func (p NotificationPreferences) Channels(notificationType NotificationType) NotificationChannels {
+	if channels, ok := p.Types[notificationType]; ok {
+		return channels
+	}
+	return DefaultNotificationPreferences(p.UserID).Types[notificationType]
+}
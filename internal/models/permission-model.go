@@ -0,0 +1,95 @@
+package models
+
+import (
+	"slices"
+)
+
+// Permission names an action reserved to the staff, granted through roles
+type Permission string
+
+const (
+	PermUserView   Permission = "user.view"
+	PermUserBan    Permission = "user.ban"
+	PermUserDelete Permission = "user.delete"
+	// PermRoleAssign allows changing the role of other users, including granting admin
+	PermRoleAssign Permission = "role.assign"
+
+	PermPostDeleteAny    Permission = "post.delete.any"
+	PermCommentDeleteAny Permission = "comment.delete.any"
+	PermModerationReview Permission = "moderation.review"
+	PermContentFilter    Permission = "content_filter.manage"
+
+	PermInfoPublish      Permission = "info.publish"
+	PermSuggestionManage Permission = "suggestion.manage"
+	PermSMSBroadcast     Permission = "sms.broadcast"
+
+	PermStatsView Permission = "stats.view"
+	PermAuditView Permission = "audit.view"
+)
+
+// allPermissions lists every permission, in the order they are shown to admins
+var allPermissions = []Permission{
+	PermUserView, PermUserBan, PermUserDelete, PermRoleAssign,
+	PermPostDeleteAny, PermCommentDeleteAny, PermModerationReview, PermContentFilter,
+	PermInfoPublish, PermSuggestionManage, PermSMSBroadcast,
+	PermStatsView, PermAuditView,
+}
+
+// rolePermissions are the permissions bundled in each staff role.
+// Admins hold every permission and plain users none.
+var rolePermissions = map[Role][]Permission{
+	RoleModerator: {
+		PermUserView, PermUserBan,
+		PermPostDeleteAny, PermCommentDeleteAny, PermModerationReview, PermContentFilter,
+	},
+	RoleEditor: {
+		PermInfoPublish, PermSuggestionManage,
+	},
+	RoleProvincialCoordinator: {
+		PermSMSBroadcast,
+	},
+}
+
+// Roles lists the roles that can be assigned to a user
+func Roles() []Role {
+	return []Role{RoleUser, RoleModerator, RoleEditor, RoleProvincialCoordinator, RoleAdmin}
+}
+
+// Valid reports whether the role is one of the known roles
+func (r Role) Valid() bool {
+	return slices.Contains(Roles(), r)
+}
+
+// Permissions returns the permissions granted to the role
+func (r Role) Permissions() []Permission {
+	if r == RoleAdmin {
+		return slices.Clone(allPermissions)
+	}
+	return append([]Permission{}, rolePermissions[r]...)
+}
+
+// Can reports whether the role grants the permission
+func (r Role) Can(permission Permission) bool {
+	return r == RoleAdmin || slices.Contains(rolePermissions[r], permission)
+}
+
+// ProvinceScoped reports whether the role only acts on the users of its own province
+func (r Role) ProvinceScoped() bool {
+	return r == RoleProvincialCoordinator
+}
+
+// IsStaff reports whether the role grants any permission
+func (r Role) IsStaff() bool {
+	return r == RoleAdmin || len(rolePermissions[r]) > 0
+}
+
+// RoleDefinition describes a role and its permissions
+type RoleDefinition struct {
+	Role        Role         `json:"role"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// RoleAssignment represents data for changing the role of a user
+type RoleAssignment struct {
+	Role Role `json:"role" validate:"required"`
+}
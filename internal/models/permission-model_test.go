@@ -0,0 +1,70 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRolePermissions(t *testing.T) {
+	tests := []struct {
+		role           Role
+		want           []Permission
+		wantStaff      bool
+		wantProvincial bool
+	}{
+		{RoleUser, []Permission{}, false, false},
+		{RoleModerator, []Permission{PermUserView, PermUserBan, PermPostDeleteAny, PermCommentDeleteAny, PermModerationReview, PermContentFilter}, true, false},
+		{RoleEditor, []Permission{PermInfoPublish, PermSuggestionManage}, true, false},
+		{RoleProvincialCoordinator, []Permission{PermSMSBroadcast}, true, true},
+		{RoleAdmin, allPermissions, true, false},
+		{Role("owner"), []Permission{}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			if got := tt.role.Permissions(); !slices.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+			if got := tt.role.IsStaff(); got != tt.wantStaff {
+				t.Errorf("expected staff %v, got %v", tt.wantStaff, got)
+			}
+			if got := tt.role.ProvinceScoped(); got != tt.wantProvincial {
+				t.Errorf("expected province scoped %v, got %v", tt.wantProvincial, got)
+			}
+
+			// Can agrees with Permissions for every permission
+			for _, permission := range allPermissions {
+				if got, want := tt.role.Can(permission), slices.Contains(tt.want, permission); got != want {
+					t.Errorf("expected Can(%s) %v, got %v", permission, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRolePermissionsReturnsACopy(t *testing.T) {
+	for _, role := range []Role{RoleAdmin, RoleModerator} {
+		permissions := role.Permissions()
+		permissions[0] = PermRoleAssign
+
+		if role == RoleModerator && role.Can(PermRoleAssign) {
+			t.Errorf("expected changing the returned slice not to grant %s", PermRoleAssign)
+		}
+		if role == RoleAdmin && allPermissions[0] != PermUserView {
+			t.Errorf("expected the admin permissions to stay unchanged, got %v", allPermissions)
+		}
+	}
+}
+
+func TestRoleValid(t *testing.T) {
+	for _, role := range Roles() {
+		if !role.Valid() {
+			t.Errorf("expected %s to be valid", role)
+		}
+	}
+	for _, role := range []Role{"", "owner", "Admin"} {
+		if role.Valid() {
+			t.Errorf("expected %q to be invalid", role)
+		}
+	}
+}
@@ -21,12 +21,20 @@ type Post struct {
 	Author  Author   `bson:"author" json:"author"`
 	Content string   `bson:"content" json:"content" validate:"required"`
 	Type    PostType `bson:"type" json:"type"`
-	// Attachments []string  `bson:"attachments,omitempty" json:"attachments,omitempty"`
+	// Attachments holds the IDs of the media attached to the post
+	Attachments []string  `bson:"attachments,omitempty" json:"attachments,omitempty"`
 	Comments    []Comment `bson:"comments" json:"comments"`
 	CreatedAt   time.Time `bson:"created_at" json:"created_at"`
 	UpdatedAt   time.Time `bson:"updated_at" json:"updated_at"`
 	Likes       int       `bson:"likes" json:"likes"`
 	LikedUserId []string  `bson:"likeduserid" json:"likeduserid"`
+	// CommentCount is filled when posts are loaded with a preview of their comments
+	CommentCount int `bson:"comment_count,omitempty" json:"comment_count"`
+	// Media describes the attachments, with signed download URLs, when the post is returned to clients
+	Media MediaList `bson:"-" json:"media,omitempty"`
+	// Hidden is set while the post waits for a moderator, after passing the report threshold
+	// or being held by the content filter
+	Hidden bool `bson:"hidden,omitempty" json:"hidden,omitempty"`
 }
 
 type Author struct {
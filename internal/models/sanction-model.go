@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+)
+
+// SanctionType represents the kind of sanction applied to a user
+type SanctionType string
+
+const (
+	// SanctionWarning is only recorded in the history of the user
+	SanctionWarning SanctionType = "warning"
+	// SanctionMute makes the account read-only: the user can still read but not post, comment or like
+	SanctionMute SanctionType = "mute"
+	// SanctionBan suspends the account: the sessions are ended and the user cannot log in
+	SanctionBan SanctionType = "ban"
+)
+
+// Valid reports whether the type is one of the known sanctions
+func (t SanctionType) Valid() bool {
+	return t == SanctionWarning || t == SanctionMute || t == SanctionBan
+}
+
+// Sanction represents a warning, mute or ban applied to a user by an admin.
+// A zero ExpiresAt means the mute or ban lasts until it is lifted by hand.
+// LiftedBy is empty when the sanction was lifted by the scheduler after expiring.
+type Sanction struct {
+	ID         string       `bson:"_id,omitempty" json:"id"`
+	UserID     string       `bson:"user_id" json:"user_id"`
+	Type       SanctionType `bson:"type" json:"type"`
+	Reason     string       `bson:"reason" json:"reason"`
+	IssuedBy   string       `bson:"issued_by" json:"issued_by"`
+	CreatedAt  time.Time    `bson:"created_at" json:"created_at"`
+	ExpiresAt  time.Time    `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+	Lifted     bool         `bson:"lifted" json:"lifted"`
+	LiftedAt   time.Time    `bson:"lifted_at,omitempty" json:"lifted_at,omitempty"`
+	LiftedBy   string       `bson:"lifted_by,omitempty" json:"lifted_by,omitempty"`
+	LiftReason string       `bson:"lift_reason,omitempty" json:"lift_reason,omitempty"`
+}
+
+// InForce reports whether the sanction still restricts the user at the given time
+func (s Sanction) InForce(now time.Time) bool {
+	if s.Type == SanctionWarning || s.Lifted {
+		return false
+	}
+	return s.ExpiresAt.IsZero() || now.Before(s.ExpiresAt)
+}
+
+// Sanctions represents a slice of Sanction
+type Sanctions []Sanction
+
+// SanctionCreation represents data for sanctioning a user.
+// DurationHours is ignored for warnings; zero makes a mute or ban permanent.
+type SanctionCreation struct {
+	Type          SanctionType `json:"type" validate:"required"`
+	Reason        string       `json:"reason" validate:"required"`
+	DurationHours int          `json:"duration_hours"`
+}
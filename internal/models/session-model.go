@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+// Session represents an authenticated device session.
+// Each session owns a family of refresh tokens: every refresh rotates the
+// token, and presenting a token that was already rotated revokes the session.
+type Session struct {
+	ID               string    `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID           string    `bson:"user_id" json:"user_id"`
+	RefreshTokenHash string    `bson:"refresh_token_hash" json:"-"`
+	Device           string    `bson:"device" json:"device"`
+	IP               string    `bson:"ip" json:"ip"`
+	Revoked          bool      `bson:"revoked" json:"revoked"`
+	CreatedAt        time.Time `bson:"created_at" json:"created_at"`
+	LastUsedAt       time.Time `bson:"last_used_at" json:"last_used_at"`
+	ExpiresAt        time.Time `bson:"expires_at" json:"expires_at"`
+	RevokedAt        time.Time `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	Current          bool      `bson:"-" json:"current"`
+}
+
+// TokenPair represents the access and refresh tokens issued to a client
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// Sessions represents a slice of Session
+type Sessions []Session
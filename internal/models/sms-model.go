@@ -0,0 +1,104 @@
+package models
+
+import (
+	"time"
+)
+
+// SMSStatus represents the delivery status of an outbox message
+type SMSStatus string
+
+const (
+	SMSStatusQueued    SMSStatus = "queued"
+	SMSStatusSent      SMSStatus = "sent"
+	SMSStatusFailed    SMSStatus = "failed"
+	SMSStatusDelivered SMSStatus = "delivered"
+)
+
+// SMSMessage represents a single SMS waiting in, or processed by, the outbox
+type SMSMessage struct {
+	ID                string    `bson:"_id,omitempty" json:"id,omitempty"`
+	CampaignID        string    `bson:"campaign_id,omitempty" json:"campaign_id,omitempty"`
+	Recipient         string    `bson:"recipient" json:"recipient"`
+	Message           string    `bson:"message" json:"message"`
+	Status            SMSStatus `bson:"status" json:"status"`
+	Attempts          int       `bson:"attempts" json:"attempts"`
+	NextAttemptAt     time.Time `bson:"next_attempt_at" json:"next_attempt_at"`
+	LockedUntil       time.Time `bson:"locked_until" json:"-"`
+	ProviderMessageID string    `bson:"provider_message_id,omitempty" json:"provider_message_id,omitempty"`
+	LastError         string    `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	CreatedAt         time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt         time.Time `bson:"updated_at" json:"updated_at"`
+	SentAt            time.Time `bson:"sent_at,omitempty" json:"sent_at,omitempty"`
+	DeliveredAt       time.Time `bson:"delivered_at,omitempty" json:"delivered_at,omitempty"`
+}
+
+// SMSCampaignState represents the lifecycle of a campaign
+type SMSCampaignState string
+
+const (
+	SMSCampaignScheduled   SMSCampaignState = "scheduled"
+	SMSCampaignDispatching SMSCampaignState = "dispatching"
+	SMSCampaignDispatched  SMSCampaignState = "dispatched"
+	SMSCampaignCancelled   SMSCampaignState = "cancelled"
+	SMSCampaignFailed      SMSCampaignState = "failed"
+)
+
+// SMSAudience selects the users that receive a campaign.
+// Empty criteria are ignored; the ones provided are combined.
+// Suspended users are left out unless Active is explicitly set.
+type SMSAudience struct {
+	Provinces []string `bson:"provinces,omitempty" json:"provinces,omitempty"`
+	Roles     []Role   `bson:"roles,omitempty" json:"roles,omitempty"`
+	Active    *bool    `bson:"active,omitempty" json:"active,omitempty"`
+	UserIDs   []string `bson:"user_ids,omitempty" json:"user_ids,omitempty"`
+}
+
+// SMSCampaign represents a group of messages sent together by an administrator
+type SMSCampaign struct {
+	ID           string           `bson:"_id,omitempty" json:"id,omitempty"`
+	CreatedBy    string           `bson:"created_by" json:"created_by"`
+	Title        string           `bson:"title,omitempty" json:"title,omitempty"`
+	Message      string           `bson:"message" json:"message"`
+	Audience     SMSAudience      `bson:"audience" json:"audience"`
+	State        SMSCampaignState `bson:"state" json:"state"`
+	Total        int              `bson:"total" json:"total"`
+	ScheduledAt  time.Time        `bson:"scheduled_at" json:"scheduled_at"`
+	DispatchedAt time.Time        `bson:"dispatched_at,omitempty" json:"dispatched_at,omitempty"`
+	CancelledAt  time.Time        `bson:"cancelled_at,omitempty" json:"cancelled_at,omitempty"`
+	LastError    string           `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	CreatedAt    time.Time        `bson:"created_at" json:"created_at"`
+	UpdatedAt    time.Time        `bson:"updated_at" json:"updated_at"`
+	// LockedUntil is the end of the lease of the instance dispatching the campaign
+	LockedUntil time.Time `bson:"locked_until,omitempty" json:"-"`
+}
+
+// SMSCampaignRequest represents the data used to create a campaign.
+// Message may contain placeholders such as {{name}} and {{province}}.
+type SMSCampaignRequest struct {
+	Title       string      `json:"title"`
+	Message     string      `json:"message" validate:"required"`
+	Audience    SMSAudience `json:"audience"`
+	ScheduledAt *time.Time  `json:"scheduled_at,omitempty"`
+	DryRun      bool        `json:"dry_run"`
+}
+
+// SMSCampaignEstimate represents the result of a campaign dry-run
+type SMSCampaignEstimate struct {
+	Recipients    int     `json:"recipients"`
+	Segments      int     `json:"segments"`
+	EstimatedCost float64 `json:"estimated_cost"`
+	Currency      string  `json:"currency"`
+	Sample        string  `json:"sample,omitempty"`
+}
+
+// SMSCampaignStatus represents a campaign with its message counts per status
+type SMSCampaignStatus struct {
+	Campaign SMSCampaign       `json:"campaign"`
+	Counts   map[SMSStatus]int `json:"counts"`
+}
+
+// SMSMessages represents a slice of SMSMessage
+type SMSMessages []SMSMessage
+
+// SMSCampaigns represents a slice of SMSCampaign
+type SMSCampaigns []SMSCampaign
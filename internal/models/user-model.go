@@ -10,6 +10,12 @@ type Role string
 const (
 	RoleUser  Role = "user"
 	RoleAdmin Role = "admin"
+	// RoleModerator reviews reports, removes content and sanctions users
+	RoleModerator Role = "moderator"
+	// RoleEditor publishes the information articles and answers suggestions
+	RoleEditor Role = "editor"
+	// RoleProvincialCoordinator sends SMS campaigns to the users of their own province
+	RoleProvincialCoordinator Role = "provincial_coordinator"
 )
 
 type UserRequest struct {
@@ -21,19 +27,27 @@ type UserRequest struct {
 
 // User represents a user in the system
 type User struct {
-	ID              string    `bson:"_id,omitempty" json:"id,omitempty"`
-	Name            string    `bson:"name" json:"name" validate:"required"`
-	Province        string    `bson:"province" json:"province" validate:"required"`
-	Contact         string    `bson:"contact" json:"contact" validate:"required,unique"`
-	Password        string    `bson:"password" json:"-" validate:"required"`
-	Role            Role      `bson:"role" json:"role"`
-	Active          bool      `bson:"active" json:"active"`
-	CreatedAt       time.Time `bson:"created_at" json:"created_at"`
-	UpdatedAt       time.Time `bson:"updated_at" json:"updated_at"`
-	LastLoginAt     time.Time `bson:"last_login_at" json:"last_login_at,omitempty"`
-	IsLoggedIn      bool      `bson:"is_logged_in" json:"is_logged_in,omitempty"`
-	ResetCode       string
-	ResetCodeExpiry time.Time
+	ID          string    `bson:"_id,omitempty" json:"id,omitempty"`
+	Name        string    `bson:"name" json:"name" validate:"required"`
+	Province    string    `bson:"province" json:"province" validate:"required"`
+	Contact     string    `bson:"contact" json:"contact" validate:"required,unique"`
+	Password    string    `bson:"password" json:"-" validate:"required"`
+	Role        Role      `bson:"role" json:"role"`
+	Active      bool      `bson:"active" json:"active"`
+	CreatedAt   time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `bson:"updated_at" json:"updated_at"`
+	LastLoginAt time.Time `bson:"last_login_at" json:"last_login_at,omitempty"`
+	// LastSeenAt is updated by API activity and whenever a WebSocket connection opens or closes
+	LastSeenAt time.Time `bson:"last_seen_at,omitempty" json:"last_seen_at,omitempty"`
+	// PendingVerification is true until the contact is confirmed with an SMS code
+	PendingVerification bool      `bson:"pending_verification" json:"pending_verification,omitempty"`
+	VerifiedAt          time.Time `bson:"verified_at,omitempty" json:"verified_at,omitempty"`
+}
+
+// OnlineStats represents the number of users online, in total and by province
+type OnlineStats struct {
+	Total     int64            `json:"total"`
+	Provinces map[string]int64 `json:"provinces"`
 }
 
 // PasswordReset represents password reset data
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+// VerificationPurpose represents what a one-time code is used for
+type VerificationPurpose string
+
+const (
+	VerificationPurposeContact       VerificationPurpose = "contact"
+	VerificationPurposePasswordReset VerificationPurpose = "password_reset"
+)
+
+// VerificationCode represents a one-time code sent to a contact by SMS
+type VerificationCode struct {
+	ID          string              `bson:"_id,omitempty" json:"id,omitempty"`
+	Contact     string              `bson:"contact" json:"contact"`
+	Purpose     VerificationPurpose `bson:"purpose" json:"purpose"`
+	CodeHash    string              `bson:"code_hash" json:"-"`
+	Attempts    int                 `bson:"attempts" json:"attempts"`
+	SendCount   int                 `bson:"send_count" json:"send_count"`
+	ExpiresAt   time.Time           `bson:"expires_at" json:"expires_at"`
+	LastSentAt  time.Time           `bson:"last_sent_at" json:"last_sent_at"`
+	LockedUntil time.Time           `bson:"locked_until,omitempty" json:"locked_until,omitempty"`
+	CreatedAt   time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// ContactVerification represents a contact verification request
+type ContactVerification struct {
+	Contact string `json:"contact" validate:"required"`
+	Code    string `json:"code" validate:"required"`
+}
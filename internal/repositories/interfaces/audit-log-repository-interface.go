@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/anamalala/internal/models"
+)
+
+// AuditLogRepository defines the interface for the audit log repository.
+// The log is append-only: entries are never updated nor deleted.
+// Each calls fn for every entry matching the filter, newest first, without loading them all at once.
+type AuditLogRepository interface {
+	Append(ctx context.Context, entry models.AuditEntry) (models.AuditEntry, error)
+	List(ctx context.Context, filter models.AuditFilter, page, limit int64) (models.AuditEntries, int64, error)
+	Each(ctx context.Context, filter models.AuditFilter, fn func(models.AuditEntry) error) error
+}
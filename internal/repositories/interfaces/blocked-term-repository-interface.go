@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/anamalala/internal/models"
+)
+
+// BlockedTermRepository defines the interface for the content filter blocklist repository
+type BlockedTermRepository interface {
+	Create(ctx context.Context, term models.BlockedTerm) (models.BlockedTerm, error)
+	FindByID(ctx context.Context, id string) (models.BlockedTerm, error)
+	FindByNormalized(ctx context.Context, normalized string) (models.BlockedTerm, error)
+	FindAll(ctx context.Context) (models.BlockedTerms, error)
+	Delete(ctx context.Context, id string) error
+}
@@ -2,18 +2,25 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/anamalala/internal/models"
 )
 
 // CommentRepository defines the interface for comment repository
 type CommentRepository interface {
-	Create(ctx context.Context, comment models.Comment) error
+	Create(ctx context.Context, comment models.Comment) (models.Comment, error)
 	FindByID(ctx context.Context, id string) (models.Comment, error)
+	FindVisibleByID(ctx context.Context, id string) (models.Comment, error)
 	Update(ctx context.Context, comment models.Comment) error
 	RemoveLike(ctx context.Context, commentObjectID, userObjectID string) error
 	AddLike(ctx context.Context, commentObjectID, userObjectID string) error
 	Delete(ctx context.Context, id string) error
+	MarkDeleted(ctx context.Context, id string) error
+	SetHidden(ctx context.Context, id string, hidden bool) error
 	ListByPostID(ctx context.Context, postID string, page, limit int64) (models.Comments, int64, error)
 	ListByCommentID(ctx context.Context, postID string, page, limit int64) (models.Comments, int64, error)
+	ListPageByReference(ctx context.Context, reference, referenceID string, after models.Cursor, limit int64) (models.Comments, error)
+	CountCreatedSince(ctx context.Context, since time.Time) (int64, error)
+	ListThread(ctx context.Context, root models.Comment, maxDepth int, limit int64, maxComments int) (map[string]models.CommentReplies, error)
 }
@@ -0,0 +1,15 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+)
+
+// ContentFingerprintRepository defines the interface for the fingerprints of recent messages,
+// used by the content filter to detect a user sending the same message again.
+// Count returns how many times the user sent the fingerprint since the given time;
+// Record stores the fingerprint of a message once it has been saved.
+type ContentFingerprintRepository interface {
+	Count(ctx context.Context, userID, fingerprint string, since time.Time) (int64, error)
+	Record(ctx context.Context, userID, fingerprint string) error
+}
@@ -0,0 +1,15 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/anamalala/internal/models"
+)
+
+// EventBus defines the interface for the bus that carries chatroom events between API replicas
+type EventBus interface {
+	// Publish sends an event to the subscribers of every replica
+	Publish(ctx context.Context, event models.Event) error
+	// Subscribe calls handler for each event until ctx is cancelled or the bus fails
+	Subscribe(ctx context.Context, handler func(models.Event)) error
+}
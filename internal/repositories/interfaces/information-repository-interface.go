@@ -8,7 +8,7 @@ import (
 
 // InformationRepository defines the interface for information repository
 type InformationRepository interface {
-	Create(ctx context.Context, info models.Information) error
+	Create(ctx context.Context, info models.Information) (models.Information, error)
 	FindByID(ctx context.Context, id string) (models.Information, error)
 	Update(ctx context.Context, info models.Information) error
 	Delete(ctx context.Context, id string) error
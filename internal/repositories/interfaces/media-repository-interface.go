@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/anamalala/internal/models"
+)
+
+// MediaRepository defines the interface for media repository.
+// Each user has at most one media per content hash: Create returns the existing media
+// instead when the user already stored one with the same hash.
+type MediaRepository interface {
+	Create(ctx context.Context, media models.Media) (models.Media, error)
+	FindByID(ctx context.Context, id string) (models.Media, error)
+	FindByIDs(ctx context.Context, ids []string) (models.MediaList, error)
+	FindByHash(ctx context.Context, hash string) (models.Media, error)
+	FindByUserHash(ctx context.Context, userID, hash string) (models.Media, error)
+}
@@ -0,0 +1,21 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/anamalala/internal/models"
+)
+
+// ModerationRepository defines the interface for the moderation queue repository.
+// AddReport adds the report to the pending item of the content, creating it from item when there is none,
+// and reports false when the reporter had already reported that content.
+// Resolve claims a pending item and reports false when it was already resolved;
+// Reopen undoes a claim whose decision could not be applied.
+type ModerationRepository interface {
+	AddReport(ctx context.Context, item models.ModerationItem, report models.Report) (models.ModerationItem, bool, error)
+	FindByID(ctx context.Context, id string) (models.ModerationItem, error)
+	List(ctx context.Context, status models.ModerationStatus, page, limit int64) (models.ModerationItems, int64, error)
+	MarkHidden(ctx context.Context, id string) error
+	Resolve(ctx context.Context, id string, status models.ModerationStatus, resolvedBy, note string) (bool, error)
+	Reopen(ctx context.Context, id string, status models.ModerationStatus, resolvedBy string) error
+}
@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/anamalala/internal/models"
+)
+
+// NotificationPreferencesRepository defines the interface for notification preferences repository
+type NotificationPreferencesRepository interface {
+	FindByUserID(ctx context.Context, userID string) (models.NotificationPreferences, error)
+	FindByUserIDs(ctx context.Context, userIDs []string) (map[string]models.NotificationPreferences, error)
+	Upsert(ctx context.Context, preferences models.NotificationPreferences) error
+}
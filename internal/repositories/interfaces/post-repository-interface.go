@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/anamalala/internal/models"
 )
@@ -10,9 +11,15 @@ import (
 type PostRepository interface {
 	Create(ctx context.Context, post models.Post) (models.Post, error)
 	FindByID(ctx context.Context, id string) (models.Post, error)
+	FindVisibleByID(ctx context.Context, id string) (models.Post, error)
 	Update(ctx context.Context, post models.Post) error
 	Delete(ctx context.Context, id string) error
+	SetHidden(ctx context.Context, id string, hidden bool) error
 	List(ctx context.Context, page, limit int64) (models.Posts, int64, error)
+	ListPage(ctx context.Context, after models.Cursor, limit int64) (models.Posts, error)
+	ListPageWithComments(ctx context.Context, after models.Cursor, limit int64, preview models.CommentPreview) (models.Posts, error)
+	FindByIDWithComments(ctx context.Context, id string, preview models.CommentPreview) (models.Post, error)
+	CountCreatedSince(ctx context.Context, since time.Time) (int64, error)
 	AddComment(ctx context.Context, postID, commentID string) error
 	RemoveComment(ctx context.Context, postID, commentID string) error
 	AddLike(ctx context.Context, postID, userId string) error
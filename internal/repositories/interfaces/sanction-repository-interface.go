@@ -0,0 +1,20 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/anamalala/internal/models"
+)
+
+// SanctionRepository defines the interface for sanction repository.
+// Lift only changes sanctions that were not lifted yet and reports whether it did,
+// so a sanction is lifted, and its user notified, once.
+type SanctionRepository interface {
+	Create(ctx context.Context, sanction models.Sanction) (models.Sanction, error)
+	FindByID(ctx context.Context, id string) (models.Sanction, error)
+	ListByUser(ctx context.Context, userID string, page, limit int64) (models.Sanctions, int64, error)
+	ListInForce(ctx context.Context, userID string, now time.Time) (models.Sanctions, error)
+	ListExpired(ctx context.Context, now time.Time, limit int64) (models.Sanctions, error)
+	Lift(ctx context.Context, id, liftedBy, reason string) (bool, error)
+}
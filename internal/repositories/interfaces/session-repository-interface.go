@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/anamalala/internal/models"
+)
+
+// SessionRepository defines the interface for session repository
+type SessionRepository interface {
+	Create(ctx context.Context, session models.Session) (models.Session, error)
+	FindByID(ctx context.Context, id string) (models.Session, error)
+	Rotate(ctx context.Context, id, oldTokenHash, newTokenHash, ip string, expiresAt time.Time) (bool, error)
+	Revoke(ctx context.Context, id string) error
+	RevokeAllByUserID(ctx context.Context, userID string) (int64, error)
+	ListActiveByUserID(ctx context.Context, userID string) (models.Sessions, error)
+}
@@ -0,0 +1,21 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/anamalala/internal/models"
+)
+
+// SMSCampaignRepository defines the interface for SMS campaign repository.
+// List only returns the campaigns aimed at exactly the given province, or every campaign when it is empty.
+// ClaimDue leases a due campaign, or one whose dispatch was interrupted, to the caller.
+type SMSCampaignRepository interface {
+	Create(ctx context.Context, campaign models.SMSCampaign) (models.SMSCampaign, error)
+	FindByID(ctx context.Context, id string) (models.SMSCampaign, error)
+	List(ctx context.Context, province string, page, limit int64) (models.SMSCampaigns, int64, error)
+	ClaimDue(ctx context.Context, lease time.Duration) (models.SMSCampaign, error)
+	MarkDispatched(ctx context.Context, id string, total int) error
+	MarkFailed(ctx context.Context, id, lastError string) error
+	Cancel(ctx context.Context, id string) (bool, error)
+}
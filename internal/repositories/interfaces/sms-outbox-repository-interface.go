@@ -0,0 +1,19 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/anamalala/internal/models"
+)
+
+// SMSOutboxRepository defines the interface for the SMS outbox repository
+type SMSOutboxRepository interface {
+	Enqueue(ctx context.Context, messages models.SMSMessages) error
+	ClaimNext(ctx context.Context, lease time.Duration) (models.SMSMessage, error)
+	MarkSent(ctx context.Context, id, providerMessageID string) error
+	MarkRetry(ctx context.Context, id string, nextAttemptAt time.Time, lastError string) error
+	MarkFailed(ctx context.Context, id, lastError string) error
+	UpdateDeliveryStatus(ctx context.Context, providerMessageID string, status models.SMSStatus, reason string) error
+	CountByCampaign(ctx context.Context, campaignID string) (map[models.SMSStatus]int, error)
+}
@@ -24,4 +24,11 @@ type UserRepository interface {
 	GetAllContacts(ctx context.Context) ([]string, error)
 	GetContactsByProvince(ctx context.Context, province string) ([]string, error)
 	ListByRole(ctx context.Context, role string, page, limit int64) (models.Users, int64, error)
+	FindByAudience(ctx context.Context, audience models.SMSAudience) (models.Users, error)
+	AddConnection(ctx context.Context, userID string, at time.Time) (bool, error)
+	RemoveConnection(ctx context.Context, userID string, at time.Time) (int, error)
+	MarkActive(ctx context.Context, userID string, at time.Time) (bool, error)
+	FindIdleOnline(ctx context.Context, since time.Time) ([]string, error)
+	SetOfflineIfIdle(ctx context.Context, userID string, since time.Time) (bool, error)
+	CountOnlineByProvince(ctx context.Context) (map[string]int64, error)
 }
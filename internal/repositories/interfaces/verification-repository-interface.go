@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/anamalala/internal/models"
+)
+
+// VerificationRepository defines the interface for verification code repository
+type VerificationRepository interface {
+	Save(ctx context.Context, code models.VerificationCode) error
+	FindByContact(ctx context.Context, contact string, purpose models.VerificationPurpose) (models.VerificationCode, error)
+	RegisterAttempt(ctx context.Context, id string, maxAttempts int) (bool, error)
+	Lock(ctx context.Context, id string, until time.Time) error
+	Delete(ctx context.Context, id string) error
+}
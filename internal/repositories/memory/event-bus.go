@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/anamalala/internal/models"
+)
+
+// EventBus implements the interfaces.EventBus interface inside a single process
+type EventBus struct {
+	mu       sync.RWMutex
+	nextID   int
+	handlers map[int]func(models.Event)
+}
+
+// NewEventBus creates a new in-process EventBus
+func NewEventBus() *EventBus {
+	return &EventBus{
+		handlers: make(map[int]func(models.Event)),
+	}
+}
+
+// Publish delivers the event to the local subscribers
+func (b *EventBus) Publish(ctx context.Context, event models.Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, handler := range b.handlers {
+		handler(event)
+	}
+	return nil
+}
+
+// Subscribe registers handler and blocks until ctx is cancelled
+func (b *EventBus) Subscribe(ctx context.Context, handler func(models.Event)) error {
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.handlers[id] = handler
+	b.mu.Unlock()
+
+	<-ctx.Done()
+
+	b.mu.Lock()
+	delete(b.handlers, id)
+	b.mu.Unlock()
+
+	return ctx.Err()
+}
@@ -0,0 +1,112 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/anamalala/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AuditLogRepository implements the interfaces.AuditLogRepository interface
+type AuditLogRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAuditLogRepository creates a new AuditLogRepository.
+// Nested documents in the snapshots are decoded as maps so they are returned as JSON objects.
+func NewAuditLogRepository(client *Client) *AuditLogRepository {
+	collectionOptions := options.Collection().SetBSONOptions(&options.BSONOptions{DefaultDocumentM: true})
+	return &AuditLogRepository{
+		collection: client.database.Collection(AuditLogsCollection, collectionOptions),
+	}
+}
+
+// Append inserts a new entry in the audit log
+func (r *AuditLogRepository) Append(ctx context.Context, entry models.AuditEntry) (models.AuditEntry, error) {
+	entry.ID = primitive.NewObjectID().Hex()
+	entry.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, entry)
+	if err != nil {
+		return models.AuditEntry{}, err
+	}
+	return entry, nil
+}
+
+// List returns a paginated list of the entries matching the filter, newest first
+func (r *AuditLogRepository) List(ctx context.Context, filter models.AuditFilter, page, limit int64) (models.AuditEntries, int64, error) {
+	query := auditQuery(filter)
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+	if page > 0 && limit > 0 {
+		findOptions.SetSkip((page - 1) * limit)
+		findOptions.SetLimit(limit)
+	}
+
+	entries := models.AuditEntries{}
+	cursor, err := r.collection.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, 0, err
+	}
+
+	total, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+// Each calls fn for every entry matching the filter, newest first, and stops at the first error
+func (r *AuditLogRepository) Each(ctx context.Context, filter models.AuditFilter, fn func(models.AuditEntry) error) error {
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, auditQuery(filter), findOptions)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var entry models.AuditEntry
+		if err := cursor.Decode(&entry); err != nil {
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// auditQuery builds the query matching the filter
+func auditQuery(filter models.AuditFilter) bson.M {
+	query := bson.M{}
+	if filter.AdminID != "" {
+		query["admin_id"] = filter.AdminID
+	}
+	if filter.Action != "" {
+		query["action"] = filter.Action
+	}
+
+	createdAt := bson.M{}
+	if !filter.From.IsZero() {
+		createdAt["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		createdAt["$lt"] = filter.To
+	}
+	if len(createdAt) > 0 {
+		query["created_at"] = createdAt
+	}
+	return query
+}
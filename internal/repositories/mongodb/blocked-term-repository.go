@@ -0,0 +1,83 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/anamalala/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BlockedTermRepository implements the interfaces.BlockedTermRepository interface
+type BlockedTermRepository struct {
+	collection *mongo.Collection
+}
+
+// NewBlockedTermRepository creates a new BlockedTermRepository
+func NewBlockedTermRepository(client *Client) *BlockedTermRepository {
+	return &BlockedTermRepository{
+		collection: client.GetCollection(BlockedTermsCollection),
+	}
+}
+
+// Create inserts a new blocked term
+func (r *BlockedTermRepository) Create(ctx context.Context, term models.BlockedTerm) (models.BlockedTerm, error) {
+	term.ID = primitive.NewObjectID().Hex()
+	term.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, term)
+	if err != nil {
+		return models.BlockedTerm{}, err
+	}
+	return term, nil
+}
+
+// FindByID finds a blocked term by ID
+func (r *BlockedTermRepository) FindByID(ctx context.Context, id string) (models.BlockedTerm, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+// FindByNormalized finds a blocked term by its normalized form
+func (r *BlockedTermRepository) FindByNormalized(ctx context.Context, normalized string) (models.BlockedTerm, error) {
+	return r.findOne(ctx, bson.M{"normalized": normalized})
+}
+
+// FindAll returns the whole blocklist in alphabetical order
+func (r *BlockedTermRepository) FindAll(ctx context.Context) (models.BlockedTerms, error) {
+	terms := models.BlockedTerms{}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "normalized", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &terms); err != nil {
+		return nil, err
+	}
+	return terms, nil
+}
+
+// Delete removes a blocked term
+func (r *BlockedTermRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// findOne returns the blocked term matching filter, or an empty one when there is none
+func (r *BlockedTermRepository) findOne(ctx context.Context, filter bson.M) (models.BlockedTerm, error) {
+	var term models.BlockedTerm
+	err := r.collection.FindOne(ctx, filter).Decode(&term)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.BlockedTerm{}, nil
+		}
+		return models.BlockedTerm{}, err
+	}
+	return term, nil
+}
@@ -0,0 +1,237 @@
+package mongodb
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anamalala/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ChangeStreamBus implements the interfaces.EventBus interface with MongoDB change streams.
+// Chatroom events are derived from the writes to the posts and comments collections, so every
+// replica watching the database sees them, including the one that made the write. Other events
+// are stored in the events collection and reach the replicas the same way.
+// Change streams require a replica set or a sharded cluster.
+type ChangeStreamBus struct {
+	database *mongo.Database
+	events   *mongo.Collection
+
+	mu          sync.Mutex
+	resumeToken bson.Raw
+}
+
+// storedEvent is an event published through the events collection.
+// The payload is kept as JSON so it reaches clients exactly as it was published.
+type storedEvent struct {
+	ID        string    `bson:"_id"`
+	Type      string    `bson:"type"`
+	Topics    []string  `bson:"topics"`
+	Payload   string    `bson:"payload"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+// collectionEvents are the event types derived from the posts and comments collections
+var collectionEvents = map[string]bool{
+	models.EventNewPost:       true,
+	models.EventDeletePost:    true,
+	models.EventNewComment:    true,
+	models.EventDeleteComment: true,
+	models.EventLikePost:      true,
+	models.EventLikeComment:   true,
+}
+
+// changeEvent is the subset of a change stream document used to build events
+type changeEvent struct {
+	OperationType string `bson:"operationType"`
+	Namespace     struct {
+		Coll string `bson:"coll"`
+	} `bson:"ns"`
+	DocumentKey struct {
+		ID string `bson:"_id"`
+	} `bson:"documentKey"`
+	FullDocument      bson.Raw `bson:"fullDocument"`
+	UpdateDescription struct {
+		UpdatedFields bson.M `bson:"updatedFields"`
+	} `bson:"updateDescription"`
+}
+
+// NewChangeStreamBus creates a new ChangeStreamBus
+func NewChangeStreamBus(client *Client) *ChangeStreamBus {
+	return &ChangeStreamBus{
+		database: client.database,
+		events:   client.GetCollection(EventsCollection),
+	}
+}
+
+// Publish stores the event in the events collection. Chatroom events are skipped because
+// the write that produced them already reaches every replica through the change stream.
+func (b *ChangeStreamBus) Publish(ctx context.Context, event models.Event) error {
+	if collectionEvents[event.Type] {
+		return nil
+	}
+
+	payload, err := json.Marshal(event.Payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.events.InsertOne(ctx, storedEvent{
+		ID:        primitive.NewObjectID().Hex(),
+		Type:      event.Type,
+		Topics:    event.Topics,
+		Payload:   string(payload),
+		CreatedAt: time.Now(),
+	})
+	return err
+}
+
+// Subscribe watches the posts, comments and events collections and calls handler for each derived event.
+// When called again after a failure it resumes after the last event delivered.
+func (b *ChangeStreamBus) Subscribe(ctx context.Context, handler func(models.Event)) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"ns.coll":       bson.M{"$in": []string{PostsCollection, CommentsCollection, EventsCollection}},
+			"operationType": bson.M{"$in": []string{"insert", "update"}},
+		}}},
+	}
+
+	streamOptions := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	b.mu.Lock()
+	if b.resumeToken != nil {
+		streamOptions.SetResumeAfter(b.resumeToken)
+	}
+	b.mu.Unlock()
+
+	stream, err := b.database.Watch(ctx, pipeline, streamOptions)
+	if err != nil {
+		return err
+	}
+	defer stream.Close(context.Background())
+
+	for stream.Next(ctx) {
+		var change changeEvent
+		if err := stream.Decode(&change); err != nil {
+			return err
+		}
+
+		if event, ok := eventFromChange(change); ok {
+			handler(event)
+		}
+
+		b.mu.Lock()
+		b.resumeToken = stream.ResumeToken()
+		b.mu.Unlock()
+	}
+
+	if err := stream.Err(); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// eventFromChange maps a change on posts, comments or events to the event sent to clients.
+// The events are built with the same constructors used by the in-memory bus publishers.
+func eventFromChange(change changeEvent) (models.Event, bool) {
+	fields := change.UpdateDescription.UpdatedFields
+
+	switch change.Namespace.Coll {
+	case EventsCollection:
+		var stored storedEvent
+		if change.OperationType != "insert" || bson.Unmarshal(change.FullDocument, &stored) != nil {
+			return models.Event{}, false
+		}
+		return models.Event{
+			Type:    stored.Type,
+			Topics:  stored.Topics,
+			Payload: json.RawMessage(stored.Payload),
+		}, true
+	case PostsCollection:
+		var post models.Post
+		if change.FullDocument != nil {
+			if err := bson.Unmarshal(change.FullDocument, &post); err != nil {
+				return models.Event{}, false
+			}
+		}
+
+		switch {
+		case change.OperationType == "insert" && post.Hidden:
+			// Held by the content filter until a moderator approves it
+			return models.Event{}, false
+		case change.OperationType == "insert" || isShown(fields) && post.ID != "":
+			return models.NewPostEvent(post), true
+		case isSoftDelete(fields) || isHidden(fields):
+			return models.DeletePostEvent(change.DocumentKey.ID), true
+		case hasUpdatedField(fields, "likes") && post.ID != "":
+			return models.LikePostEvent(post, likeUserID(fields)), true
+		}
+	case CommentsCollection:
+		var comment models.Comment
+		if change.FullDocument != nil {
+			if err := bson.Unmarshal(change.FullDocument, &comment); err != nil {
+				return models.Event{}, false
+			}
+		}
+
+		switch {
+		case change.OperationType == "insert" && comment.Hidden:
+			// Held by the content filter until a moderator approves it
+			return models.Event{}, false
+		case change.OperationType == "insert" || isShown(fields) && comment.ID != "":
+			return models.NewCommentEvent(comment), true
+		case isSoftDelete(fields) || isPlaceholder(fields) || isHidden(fields):
+			comment.ID = change.DocumentKey.ID
+			return models.DeleteCommentEvent(comment, isPlaceholder(fields)), true
+		case hasUpdatedField(fields, "likes") && comment.ID != "":
+			return models.LikeCommentEvent(comment, likeUserID(fields)), true
+		}
+	}
+
+	return models.Event{}, false
+}
+
+// isSoftDelete reports whether the update set deleted_at, which is how posts and comments are deleted
+func isSoftDelete(fields bson.M) bool {
+	value, ok := fields["deleted_at"]
+	return ok && value != nil
+}
+
+// isPlaceholder reports whether the update turned a comment with replies into a deleted placeholder
+func isPlaceholder(fields bson.M) bool {
+	value, ok := fields["deleted"].(bool)
+	return ok && value
+}
+
+// isHidden reports whether the update hid a post or comment pending moderation
+func isHidden(fields bson.M) bool {
+	value, ok := fields["hidden"].(bool)
+	return ok && value
+}
+
+// isShown reports whether the update showed again a post or comment approved by a moderator
+func isShown(fields bson.M) bool {
+	value, ok := fields["hidden"].(bool)
+	return ok && !value
+}
+
+// likeUserID returns the user who liked or unliked, recorded by AddLike and RemoveLike
+func likeUserID(fields bson.M) string {
+	userID, _ := fields["last_like_user_id"].(string)
+	return userID
+}
+
+// hasUpdatedField reports whether the update touched field or one of its elements
+func hasUpdatedField(fields bson.M, field string) bool {
+	for key := range fields {
+		if key == field || strings.HasPrefix(key, field+".") {
+			return true
+		}
+	}
+	return false
+}
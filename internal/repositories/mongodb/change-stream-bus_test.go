@@ -0,0 +1,88 @@
+package mongodb
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/anamalala/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// newChange monta um documento de change stream com o documento completo e os campos alterados
+func newChange(t *testing.T, coll, operation, id string, document any, fields bson.M) changeEvent {
+	t.Helper()
+
+	raw, err := bson.Marshal(document)
+	if err != nil {
+		t.Fatalf("bson.Marshal: %v", err)
+	}
+
+	var change changeEvent
+	change.OperationType = operation
+	change.Namespace.Coll = coll
+	change.DocumentKey.ID = id
+	change.FullDocument = raw
+	change.UpdateDescription.UpdatedFields = fields
+	return change
+}
+
+func TestEventFromChangeMatchesPublishedEvents(t *testing.T) {
+	post := models.Post{ID: "post-1", UserID: "author-1", Content: "Olá", Likes: 1, LikedUserId: []string{"liker-1"}}
+	comment := models.Comment{ID: "comment-1", ReferenceID: "post-1", PostID: "post-1", Reference: "post", UserID: "author-2", Content: "Oi"}
+	reply := models.Comment{ID: "reply-1", ReferenceID: "comment-1", PostID: "post-1", Reference: "comment", UserID: "author-3", Content: "Oi"}
+	hiddenPost := post
+	hiddenPost.Hidden = true
+
+	tests := []struct {
+		name   string
+		change changeEvent
+		want   models.Event
+	}{
+		{"new post", newChange(t, PostsCollection, "insert", "post-1", post, nil), models.NewPostEvent(post)},
+		{"approved post", newChange(t, PostsCollection, "update", "post-1", post, bson.M{"hidden": false}), models.NewPostEvent(post)},
+		{"hidden post", newChange(t, PostsCollection, "update", "post-1", hiddenPost, bson.M{"hidden": true}), models.DeletePostEvent("post-1")},
+		{"post like", newChange(t, PostsCollection, "update", "post-1", post, bson.M{"likes": 1, "likeduserid.0": "liker-1", "last_like_user_id": "liker-1"}), models.LikePostEvent(post, "liker-1")},
+		{"new comment", newChange(t, CommentsCollection, "insert", "comment-1", comment, nil), models.NewCommentEvent(comment)},
+		{"new reply", newChange(t, CommentsCollection, "insert", "reply-1", reply, nil), models.NewCommentEvent(reply)},
+		{"approved comment", newChange(t, CommentsCollection, "update", "comment-1", comment, bson.M{"hidden": false}), models.NewCommentEvent(comment)},
+		{"comment placeholder", newChange(t, CommentsCollection, "update", "comment-1", comment, bson.M{"deleted": true, "content": ""}), models.DeleteCommentEvent(comment, true)},
+		{"comment like", newChange(t, CommentsCollection, "update", "comment-1", comment, bson.M{"likes": 0, "last_like_user_id": "liker-2"}), models.LikeCommentEvent(comment, "liker-2")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := eventFromChange(tt.change)
+			if !ok {
+				t.Fatalf("expected an event")
+			}
+			if got.Type != tt.want.Type {
+				t.Errorf("expected type %q, got %q", tt.want.Type, got.Type)
+			}
+			if !slices.Equal(got.Topics, tt.want.Topics) {
+				t.Errorf("expected topics %v, got %v", tt.want.Topics, got.Topics)
+			}
+
+			// Os clientes recebem o payload em JSON, que deve ser igual nos dois barramentos
+			gotJSON, _ := json.Marshal(got.Payload)
+			wantJSON, _ := json.Marshal(tt.want.Payload)
+			if string(gotJSON) != string(wantJSON) {
+				t.Errorf("expected payload %s, got %s", wantJSON, gotJSON)
+			}
+		})
+	}
+}
+
+func TestEventFromChangeSkipsHeldContent(t *testing.T) {
+	post := models.Post{ID: "post-1", UserID: "author-1", Hidden: true}
+	comment := models.Comment{ID: "comment-1", ReferenceID: "post-1", Reference: "post", Hidden: true}
+
+	for _, change := range []changeEvent{
+		newChange(t, PostsCollection, "insert", "post-1", post, nil),
+		newChange(t, CommentsCollection, "insert", "comment-1", comment, nil),
+	} {
+		if event, ok := eventFromChange(change); ok {
+			t.Errorf("expected no event for held content, got %+v", event)
+		}
+	}
+}
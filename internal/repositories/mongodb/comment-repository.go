@@ -25,22 +25,27 @@ func NewCommentRepository(client *Client) *CommentRepository {
 }
 
 // Create inserts a new comment into the database
-func (r *CommentRepository) Create(ctx context.Context, comment models.Comment) error {
+func (r *CommentRepository) Create(ctx context.Context, comment models.Comment) (models.Comment, error) {
 	comment.ID = primitive.NewObjectID().Hex()
 	comment.CreatedAt = time.Now()
 	comment.UpdatedAt = time.Now()
 
 	_, err := r.collection.InsertOne(ctx, comment)
-	return err
+	return comment, err
 }
 
-// FindByID finds a comment by ID
+// FindByID finds a comment by ID, including comments hidden for moderation
 func (r *CommentRepository) FindByID(ctx context.Context, id string) (models.Comment, error) {
+	return r.findOne(ctx, bson.M{"_id": id, "deleted_at": nil})
+}
+
+// FindVisibleByID finds a comment by ID unless it is hidden for moderation
+func (r *CommentRepository) FindVisibleByID(ctx context.Context, id string) (models.Comment, error) {
+	return r.findOne(ctx, bson.M{"_id": id, "deleted_at": nil, "hidden": bson.M{"$ne": true}})
+}
+
+func (r *CommentRepository) findOne(ctx context.Context, filter bson.M) (models.Comment, error) {
 	var comment models.Comment
-	filter := bson.M{
-		"_id":        id,
-		"deleted_at": nil,
-	}
 
 	err := r.collection.FindOne(ctx, filter).Decode(&comment)
 	if err != nil {
@@ -148,38 +153,200 @@ func (r *CommentRepository) ListByPostID(ctx context.Context, postID string, pag
 	return comments, total, nil
 }
 
+// RemoveLike removes the like of a user from a comment
 func (r *CommentRepository) RemoveLike(ctx context.Context, commentObjectID, userObjectID string) error {
-	now := time.Now()
-	comment := models.Comment{}
-	filter := bson.M{"_id": commentObjectID}
-	result := r.collection.FindOne(ctx, filter)
-	err := result.Decode(comment)
-	if err != nil {
-		return err
-	}
-	if comment.Likes > 0 {
-		comment.Likes = comment.Likes - 1
+	filter := bson.M{"_id": commentObjectID, "likeduserid": userObjectID}
+	update := bson.M{
+		"$inc":  bson.M{"likes": -1},
+		"$pull": bson.M{"likeduserid": userObjectID},
+		"$set":  bson.M{"last_like_user_id": userObjectID, "updated_at": time.Now()},
 	}
-	comment.UpdatedAt = now
-	_, err = r.collection.UpdateOne(ctx, filter, comment)
+	_, err := r.collection.UpdateOne(ctx, filter, update)
 	return err
 }
 
+// AddLike adds the like of a user to a comment.
+// last_like_user_id records who liked so the change stream bus can tell clients.
 func (r *CommentRepository) AddLike(ctx context.Context, commentObjectID, userObjectID string) error {
-	now := time.Now()
+	filter := bson.M{"_id": commentObjectID, "likeduserid": bson.M{"$ne": userObjectID}}
+	update := bson.M{
+		"$inc":  bson.M{"likes": 1},
+		"$push": bson.M{"likeduserid": userObjectID},
+		"$set":  bson.M{"last_like_user_id": userObjectID, "updated_at": time.Now()},
+	}
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// ListPageByReference returns up to limit comments on a post or replies to a comment
+// created after the cursor, oldest first. A zero cursor starts at the oldest comment.
+func (r *CommentRepository) ListPageByReference(ctx context.Context, reference, referenceID string, after models.Cursor, limit int64) (models.Comments, error) {
+	comments := models.Comments{}
+
+	filter := bson.M{
+		"reference_id": referenceID,
+		"reference":    reference,
+		"deleted_at":   nil,
+		"hidden":       bson.M{"$ne": true},
+	}
+	if !after.IsZero() {
+		filter["$or"] = bson.A{
+			bson.M{"created_at": bson.M{"$gt": after.CreatedAt}},
+			bson.M{"created_at": after.CreatedAt, "_id": bson.M{"$gt": after.ID}},
+		}
+	}
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	findOptions.SetLimit(limit)
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &comments); err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+// CountCreatedSince counts the comments created since the given time
+func (r *CommentRepository) CountCreatedSince(ctx context.Context, since time.Time) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"deleted_at": nil, "created_at": bson.M{"$gte": since}})
+}
 
-	filter := bson.M{"_id": commentObjectID}
+// MarkDeleted turns a comment into a placeholder: it stays in the thread so its replies
+// keep their context, but its content, author and likes are removed
+func (r *CommentRepository) MarkDeleted(ctx context.Context, id string) error {
+	filter := bson.M{"_id": id}
 	update := bson.M{
-		"$inc": bson.M{
-			"likes":      1,
-			"updated_at": now,
+		"$set": bson.M{
+			"deleted":    true,
+			"content":    "",
+			"author":     models.Author{},
+			"likes":      0,
+			"updated_at": time.Now(),
+		},
+		"$unset": bson.M{
+			"user_id":           "",
+			"likeduserid":       "",
+			"last_like_user_id": "",
 		},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
 
-		"$push": bson.M{
-			"likeduserid": userObjectID,
-			"updated_at":  now,
+// SetHidden hides a comment from the listings while it waits for moderation, or shows it again
+func (r *CommentRepository) SetHidden(ctx context.Context, id string, hidden bool) error {
+	filter := bson.M{"_id": id}
+	update := bson.M{
+		"$set": bson.M{
+			"hidden":     hidden,
+			"updated_at": time.Now(),
 		},
 	}
+
 	_, err := r.collection.UpdateOne(ctx, filter, update)
 	return err
 }
+
+// ListThread returns the replies below root, up to maxDepth levels down, grouped by parent comment.
+// Each parent gets at most limit replies, oldest first, with the total number of its replies;
+// the comments on the last level only get their total. Each level is one bounded aggregation,
+// and no more than maxComments replies are loaded across all levels: once they are used up,
+// the thread stops going deeper and the last loaded replies only get their total.
+func (r *CommentRepository) ListThread(ctx context.Context, root models.Comment, maxDepth int, limit int64, maxComments int) (map[string]models.CommentReplies, error) {
+	thread := make(map[string]models.CommentReplies)
+
+	loaded := 0
+	parents := []string{root.ID}
+	for level := 0; len(parents) > 0; level++ {
+		levelLimit := min(limit, int64(maxComments-loaded))
+		last := level == maxDepth || levelLimit <= 0
+		if last {
+			levelLimit = 0
+		}
+
+		groups, err := r.repliesByParent(ctx, parents, levelLimit)
+		if err != nil {
+			return nil, err
+		}
+
+		// Parents are visited in thread order, so the budget goes to the oldest replies first
+		var next []string
+		for _, parentID := range parents {
+			replies, ok := groups[parentID]
+			if !ok {
+				continue
+			}
+			if remaining := maxComments - loaded; len(replies.Replies) > remaining {
+				replies.Replies = replies.Replies[:remaining]
+			}
+			loaded += len(replies.Replies)
+
+			thread[parentID] = replies
+			for _, reply := range replies.Replies {
+				next = append(next, reply.ID)
+			}
+		}
+		if last {
+			break
+		}
+		parents = next
+	}
+
+	return thread, nil
+}
+
+// repliesByParent counts the replies to each parent and returns the first limit of them, oldest first.
+// $topN keeps only limit replies per parent while grouping, so a popular comment never
+// builds its full list of replies.
+func (r *CommentRepository) repliesByParent(ctx context.Context, parentIDs []string, limit int64) (map[string]models.CommentReplies, error) {
+	group := bson.M{
+		"_id":   "$reference_id",
+		"total": bson.M{"$sum": 1},
+	}
+	if limit > 0 {
+		group["replies"] = bson.M{"$topN": bson.M{
+			"n":      limit,
+			"sortBy": bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}},
+			"output": "$$ROOT",
+		}}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"reference":    "comment",
+			"reference_id": bson.M{"$in": parentIDs},
+			"deleted_at":   nil,
+			"hidden":       bson.M{"$ne": true},
+		}}},
+		{{Key: "$group", Value: group}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		ParentID string          `bson:"_id"`
+		Total    int             `bson:"total"`
+		Replies  models.Comments `bson:"replies"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string]models.CommentReplies, len(results))
+	for _, result := range results {
+		groups[result.ParentID] = models.CommentReplies{Replies: result.Replies, Total: result.Total}
+	}
+	return groups, nil
+}
@@ -0,0 +1,51 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ContentFingerprintRepository implements the interfaces.ContentFingerprintRepository interface.
+// Fingerprints expire through a TTL index, so only the recent messages are kept.
+type ContentFingerprintRepository struct {
+	collection *mongo.Collection
+}
+
+// contentFingerprint is the fingerprint of a message sent by a user
+type contentFingerprint struct {
+	ID          string    `bson:"_id"`
+	UserID      string    `bson:"user_id"`
+	Fingerprint string    `bson:"fingerprint"`
+	CreatedAt   time.Time `bson:"created_at"`
+}
+
+// NewContentFingerprintRepository creates a new ContentFingerprintRepository
+func NewContentFingerprintRepository(client *Client) *ContentFingerprintRepository {
+	return &ContentFingerprintRepository{
+		collection: client.GetCollection(ContentFingerprintsCollection),
+	}
+}
+
+// Count counts the messages of the user with the same fingerprint since the given time
+func (r *ContentFingerprintRepository) Count(ctx context.Context, userID, fingerprint string, since time.Time) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{
+		"user_id":     userID,
+		"fingerprint": fingerprint,
+		"created_at":  bson.M{"$gte": since},
+	})
+}
+
+// Record stores the fingerprint of a message the user has just saved
+func (r *ContentFingerprintRepository) Record(ctx context.Context, userID, fingerprint string) error {
+	_, err := r.collection.InsertOne(ctx, contentFingerprint{
+		ID:          primitive.NewObjectID().Hex(),
+		UserID:      userID,
+		Fingerprint: fingerprint,
+		CreatedAt:   time.Now(),
+	})
+	return err
+}
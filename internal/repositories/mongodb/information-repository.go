@@ -25,7 +25,7 @@ func NewInformationRepository(client *Client) *InformationRepository {
 }
 
 // Create inserts a new information post into the database
-func (r *InformationRepository) Create(ctx context.Context, info models.Information) error {
+func (r *InformationRepository) Create(ctx context.Context, info models.Information) (models.Information, error) {
 	info.ID = primitive.NewObjectID().Hex()
 	info.CreatedAt = time.Now()
 	info.UpdatedAt = time.Now()
@@ -36,7 +36,10 @@ func (r *InformationRepository) Create(ctx context.Context, info models.Informat
 	}
 
 	_, err := r.collection.InsertOne(ctx, info)
-	return err
+	if err != nil {
+		return models.Information{}, err
+	}
+	return info, nil
 }
 
 // FindByID finds an information post by ID
@@ -0,0 +1,86 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/anamalala/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MediaRepository implements the interfaces.MediaRepository interface
+type MediaRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMediaRepository creates a new MediaRepository
+func NewMediaRepository(client *Client) *MediaRepository {
+	return &MediaRepository{
+		collection: client.GetCollection(MediaCollection),
+	}
+}
+
+// Create inserts a new media. The hash is unique per user, so when two uploads of the
+// same content by the same user race the second one gets the media stored by the first.
+func (r *MediaRepository) Create(ctx context.Context, media models.Media) (models.Media, error) {
+	media.ID = primitive.NewObjectID().Hex()
+	media.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, media)
+	if mongo.IsDuplicateKeyError(err) {
+		return r.FindByUserHash(ctx, media.UserID, media.Hash)
+	}
+	return media, err
+}
+
+// FindByID finds a media by ID
+func (r *MediaRepository) FindByID(ctx context.Context, id string) (models.Media, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+// FindByHash finds a media by the hash of its content, uploaded by any user
+func (r *MediaRepository) FindByHash(ctx context.Context, hash string) (models.Media, error) {
+	return r.findOne(ctx, bson.M{"hash": hash})
+}
+
+// FindByUserHash finds the media a user uploaded with the given content hash
+func (r *MediaRepository) FindByUserHash(ctx context.Context, userID, hash string) (models.Media, error) {
+	return r.findOne(ctx, bson.M{"hash": hash, "user_id": userID})
+}
+
+// FindByIDs returns the media with the given IDs, in no particular order
+func (r *MediaRepository) FindByIDs(ctx context.Context, ids []string) (models.MediaList, error) {
+	media := models.MediaList{}
+	if len(ids) == 0 {
+		return media, nil
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &media); err != nil {
+		return nil, err
+	}
+
+	return media, nil
+}
+
+// findOne returns the media matching filter, or a zero Media when there is none
+func (r *MediaRepository) findOne(ctx context.Context, filter bson.M) (models.Media, error) {
+	var media models.Media
+	err := r.collection.FindOne(ctx, filter).Decode(&media)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.Media{}, nil
+		}
+		return models.Media{}, err
+	}
+
+	return media, nil
+}
@@ -0,0 +1,181 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/anamalala/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ModerationRepository implements the interfaces.ModerationRepository interface
+type ModerationRepository struct {
+	collection *mongo.Collection
+}
+
+// NewModerationRepository creates a new ModerationRepository
+func NewModerationRepository(client *Client) *ModerationRepository {
+	return &ModerationRepository{
+		collection: client.GetCollection(ModerationQueueCollection),
+	}
+}
+
+// AddReport pushes the report onto the pending item of the content unless the reporter is already in it.
+// When the content has no pending item a new one is created from item.
+func (r *ModerationRepository) AddReport(ctx context.Context, item models.ModerationItem, report models.Report) (models.ModerationItem, bool, error) {
+	pending := bson.M{
+		"content_type": item.ContentType,
+		"content_id":   item.ContentID,
+		"status":       models.ModerationStatusPending,
+	}
+
+	filter := bson.M{"reports.reporter_id": bson.M{"$ne": report.ReporterID}}
+	for key, value := range pending {
+		filter[key] = value
+	}
+	update := bson.M{
+		"$push": bson.M{"reports": report},
+		"$inc":  bson.M{"report_count": 1},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+	updateOptions := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var updated models.ModerationItem
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, updateOptions).Decode(&updated)
+	if err == nil {
+		return updated, true, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return models.ModerationItem{}, false, err
+	}
+
+	// Either the reporter is already in the pending item or there is no pending item yet
+	var existing models.ModerationItem
+	err = r.collection.FindOne(ctx, pending).Decode(&existing)
+	if err == nil {
+		return existing, false, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return models.ModerationItem{}, false, err
+	}
+
+	item.ID = primitive.NewObjectID().Hex()
+	item.Status = models.ModerationStatusPending
+	item.Reports = []models.Report{report}
+	item.ReportCount = 1
+	item.CreatedAt = time.Now()
+	item.UpdatedAt = item.CreatedAt
+
+	if _, err := r.collection.InsertOne(ctx, item); err != nil {
+		// Another report created the pending item first
+		if mongo.IsDuplicateKeyError(err) {
+			return r.AddReport(ctx, item, report)
+		}
+		return models.ModerationItem{}, false, err
+	}
+	return item, true, nil
+}
+
+// FindByID finds a moderation item by ID
+func (r *ModerationRepository) FindByID(ctx context.Context, id string) (models.ModerationItem, error) {
+	var item models.ModerationItem
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&item)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.ModerationItem{}, nil
+		}
+		return models.ModerationItem{}, err
+	}
+
+	return item, nil
+}
+
+// List returns a paginated list of moderation items with the given status, or all when status is empty.
+// Pending items with the most reports come first; resolved items are listed newest first.
+func (r *ModerationRepository) List(ctx context.Context, status models.ModerationStatus, page, limit int64) (models.ModerationItems, int64, error) {
+	items := models.ModerationItems{}
+
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+
+	findOptions := options.Find()
+	if status == models.ModerationStatusPending {
+		findOptions.SetSort(bson.D{{Key: "report_count", Value: -1}, {Key: "created_at", Value: 1}})
+	} else {
+		findOptions.SetSort(bson.D{{Key: "updated_at", Value: -1}})
+	}
+	if page > 0 && limit > 0 {
+		findOptions.SetSkip((page - 1) * limit)
+		findOptions.SetLimit(limit)
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, 0, err
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return items, total, nil
+}
+
+// MarkHidden records that the reported content was hidden after passing the report threshold
+func (r *ModerationRepository) MarkHidden(ctx context.Context, id string) error {
+	update := bson.M{
+		"$set": bson.M{
+			"hidden":     true,
+			"updated_at": time.Now(),
+		},
+	}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
+
+// Resolve closes a pending item with the moderator's decision.
+// It reports false when the item was not pending, so a decision is only applied once.
+func (r *ModerationRepository) Resolve(ctx context.Context, id string, status models.ModerationStatus, resolvedBy, note string) (bool, error) {
+	now := time.Now()
+	filter := bson.M{"_id": id, "status": models.ModerationStatusPending}
+	update := bson.M{
+		"$set": bson.M{
+			"status":      status,
+			"resolved_by": resolvedBy,
+			"resolved_at": now,
+			"note":        note,
+			"updated_at":  now,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// Reopen puts an item resolved by resolvedBy with status back in the pending queue.
+// It fails with a duplicate key error if a new pending item was opened for the content meanwhile.
+func (r *ModerationRepository) Reopen(ctx context.Context, id string, status models.ModerationStatus, resolvedBy string) error {
+	filter := bson.M{"_id": id, "status": status, "resolved_by": resolvedBy}
+	update := bson.M{
+		"$set":   bson.M{"status": models.ModerationStatusPending, "updated_at": time.Now()},
+		"$unset": bson.M{"resolved_by": "", "resolved_at": "", "note": ""},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
@@ -17,6 +18,18 @@ const (
 	InformationCollection  = "information"
 	SuggestionsCollection  = "suggestions"
 	NotificationsCollection = "notifications"
+	SessionsCollection      = "sessions"
+	VerificationCodesCollection = "verification_codes"
+	SMSOutboxCollection     = "sms_outbox"
+	SMSCampaignsCollection  = "sms_campaigns"
+	NotificationPreferencesCollection = "notification_preferences"
+	EventsCollection        = "events"
+	MediaCollection         = "media"
+	ModerationQueueCollection = "moderation_queue"
+	BlockedTermsCollection  = "blocked_terms"
+	ContentFingerprintsCollection = "content_fingerprints"
+	SanctionsCollection     = "sanctions"
+	AuditLogsCollection     = "audit_logs"
 )
 
 // Client represents a MongoDB client with its database
@@ -79,6 +92,12 @@ func (c *Client) CreateIndexes(ctx context.Context) error {
 				"password_reset.token": 1,
 			},
 		},
+		{
+			Keys: bson.D{
+				{Key: "online", Value: 1},
+				{Key: "province", Value: 1},
+			},
+		},
 	}
 	_, err := userCollection.Indexes().CreateMany(ctx, userIndexes)
 	if err != nil {
@@ -94,8 +113,9 @@ func (c *Client) CreateIndexes(ctx context.Context) error {
 			},
 		},
 		{
-			Keys: map[string]interface{}{
-				"created_at": -1,
+			Keys: bson.D{
+				{Key: "created_at", Value: -1},
+				{Key: "_id", Value: -1},
 			},
 		},
 	}
@@ -117,6 +137,20 @@ func (c *Client) CreateIndexes(ctx context.Context) error {
 				"user_id": 1,
 			},
 		},
+		{
+			Keys: bson.D{
+				{Key: "reference", Value: 1},
+				{Key: "reference_id", Value: 1},
+				{Key: "created_at", Value: 1},
+				{Key: "_id", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "ancestors", Value: 1},
+				{Key: "created_at", Value: 1},
+			},
+		},
 	}
 	_, err = commentCollection.Indexes().CreateMany(ctx, commentIndexes)
 	if err != nil {
@@ -195,5 +229,236 @@ func (c *Client) CreateIndexes(ctx context.Context) error {
 		return err
 	}
 
+	// Session indexes
+	sessionCollection := c.GetCollection(SessionsCollection)
+	sessionIndexes := []mongo.IndexModel{
+		{
+			Keys: map[string]interface{}{
+				"user_id": 1,
+			},
+		},
+		{
+			Keys: map[string]interface{}{
+				"expires_at": 1,
+			},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+	_, err = sessionCollection.Indexes().CreateMany(ctx, sessionIndexes)
+	if err != nil {
+		return err
+	}
+
+	// Verification code indexes
+	verificationCollection := c.GetCollection(VerificationCodesCollection)
+	verificationIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "contact", Value: 1},
+				{Key: "purpose", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	_, err = verificationCollection.Indexes().CreateMany(ctx, verificationIndexes)
+	if err != nil {
+		return err
+	}
+
+	// SMS outbox indexes
+	outboxCollection := c.GetCollection(SMSOutboxCollection)
+	outboxIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "next_attempt_at", Value: 1},
+			},
+		},
+		{
+			Keys: map[string]interface{}{
+				"campaign_id": 1,
+			},
+		},
+		{
+			// A campaign sends at most one message to each recipient, even when dispatched again
+			Keys: bson.D{
+				{Key: "campaign_id", Value: 1},
+				{Key: "recipient", Value: 1},
+			},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"campaign_id": bson.M{"$exists": true}}),
+		},
+		{
+			Keys: map[string]interface{}{
+				"provider_message_id": 1,
+			},
+			Options: options.Index().SetSparse(true),
+		},
+	}
+	_, err = outboxCollection.Indexes().CreateMany(ctx, outboxIndexes)
+	if err != nil {
+		return err
+	}
+
+	// SMS campaign indexes
+	campaignCollection := c.GetCollection(SMSCampaignsCollection)
+	campaignIndexes := []mongo.IndexModel{
+		{
+			Keys: map[string]interface{}{
+				"created_at": -1,
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "state", Value: 1},
+				{Key: "scheduled_at", Value: 1},
+			},
+		},
+	}
+	_, err = campaignCollection.Indexes().CreateMany(ctx, campaignIndexes)
+	if err != nil {
+		return err
+	}
+
+	// Event bus indexes: published events only need to live long enough to reach the other replicas
+	eventCollection := c.GetCollection(EventsCollection)
+	eventIndexes := []mongo.IndexModel{
+		{
+			Keys: map[string]interface{}{
+				"created_at": 1,
+			},
+			Options: options.Index().SetExpireAfterSeconds(3600),
+		},
+	}
+	_, err = eventCollection.Indexes().CreateMany(ctx, eventIndexes)
+	if err != nil {
+		return err
+	}
+
+	// Media indexes: each user has one media per content hash, and the file itself is stored once
+	mediaCollection := c.GetCollection(MediaCollection)
+	mediaIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "hash", Value: 1},
+				{Key: "user_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	_, err = mediaCollection.Indexes().CreateMany(ctx, mediaIndexes)
+	if err != nil {
+		return err
+	}
+
+	// Moderation queue indexes: a content has at most one pending item
+	moderationCollection := c.GetCollection(ModerationQueueCollection)
+	moderationIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "content_type", Value: 1},
+				{Key: "content_id", Value: 1},
+			},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"status": "pending"}),
+		},
+		{
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "report_count", Value: -1},
+			},
+		},
+	}
+	_, err = moderationCollection.Indexes().CreateMany(ctx, moderationIndexes)
+	if err != nil {
+		return err
+	}
+
+	// Blocklist indexes: each term is stored once, whatever its spelling
+	blockedTermCollection := c.GetCollection(BlockedTermsCollection)
+	blockedTermIndexes := []mongo.IndexModel{
+		{
+			Keys: map[string]interface{}{
+				"normalized": 1,
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	_, err = blockedTermCollection.Indexes().CreateMany(ctx, blockedTermIndexes)
+	if err != nil {
+		return err
+	}
+
+	// Content fingerprint indexes: fingerprints only need to outlive the duplicate detection window
+	fingerprintCollection := c.GetCollection(ContentFingerprintsCollection)
+	fingerprintIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "fingerprint", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+		{
+			Keys: map[string]interface{}{
+				"created_at": 1,
+			},
+			Options: options.Index().SetExpireAfterSeconds(86400),
+		},
+	}
+	_, err = fingerprintCollection.Indexes().CreateMany(ctx, fingerprintIndexes)
+	if err != nil {
+		return err
+	}
+
+	// Sanction indexes: the history of each user and the sanctions waiting to be lifted
+	sanctionCollection := c.GetCollection(SanctionsCollection)
+	sanctionIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "lifted", Value: 1},
+				{Key: "expires_at", Value: 1},
+			},
+		},
+	}
+	_, err = sanctionCollection.Indexes().CreateMany(ctx, sanctionIndexes)
+	if err != nil {
+		return err
+	}
+
+	// Audit log indexes: the log is read newest first, by admin or by action
+	auditCollection := c.GetCollection(AuditLogsCollection)
+	auditIndexes := []mongo.IndexModel{
+		{
+			Keys: map[string]interface{}{
+				"created_at": -1,
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "admin_id", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "action", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+	}
+	_, err = auditCollection.Indexes().CreateMany(ctx, auditIndexes)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
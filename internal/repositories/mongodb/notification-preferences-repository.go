@@ -0,0 +1,71 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/anamalala/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NotificationPreferencesRepository implements the interfaces.NotificationPreferencesRepository interface
+type NotificationPreferencesRepository struct {
+	collection *mongo.Collection
+}
+
+// NewNotificationPreferencesRepository creates a new NotificationPreferencesRepository
+func NewNotificationPreferencesRepository(client *Client) *NotificationPreferencesRepository {
+	return &NotificationPreferencesRepository{
+		collection: client.GetCollection(NotificationPreferencesCollection),
+	}
+}
+
+// FindByUserID finds the preferences of a user.
+// It returns a zero value when the user never saved any preferences.
+func (r *NotificationPreferencesRepository) FindByUserID(ctx context.Context, userID string) (models.NotificationPreferences, error) {
+	var preferences models.NotificationPreferences
+	err := r.collection.FindOne(ctx, bson.M{"_id": userID}).Decode(&preferences)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.NotificationPreferences{}, nil
+		}
+		return models.NotificationPreferences{}, err
+	}
+
+	return preferences, nil
+}
+
+// FindByUserIDs returns the saved preferences of several users keyed by user ID
+func (r *NotificationPreferencesRepository) FindByUserIDs(ctx context.Context, userIDs []string) (map[string]models.NotificationPreferences, error) {
+	result := make(map[string]models.NotificationPreferences)
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": userIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var preferences []models.NotificationPreferences
+	if err := cursor.All(ctx, &preferences); err != nil {
+		return nil, err
+	}
+
+	for _, p := range preferences {
+		result[p.UserID] = p
+	}
+
+	return result, nil
+}
+
+// Upsert creates or replaces the preferences of a user
+func (r *NotificationPreferencesRepository) Upsert(ctx context.Context, preferences models.NotificationPreferences) error {
+	preferences.UpdatedAt = time.Now()
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": preferences.UserID}, preferences, options.Replace().SetUpsert(true))
+	return err
+}
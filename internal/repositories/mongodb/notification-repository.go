@@ -2,9 +2,14 @@ package mongodb
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/anamalala/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // NotificationRepository implements the interfaces.NotificationRepository interface
@@ -12,36 +17,126 @@ type NotificationRepository struct {
 	collection *mongo.Collection
 }
 
-// NewSuggestionRepository creates a new SuggestionRepository
+// NewNotificationRepository creates a new NotificationRepository
 func NewNotificationRepository(client *Client) *NotificationRepository {
 	return &NotificationRepository{
-		collection: client.GetCollection("notifications"),
+		collection: client.GetCollection(NotificationsCollection),
 	}
 }
 
-func (r *NotificationRepository) Create(ctx context.Context, notification models.Notification) error {
-	return nil
+// Create inserts a new notification into the database
+func (r *NotificationRepository) Create(ctx context.Context, notification models.Notification) (models.Notification, error) {
+	notification.ID = primitive.NewObjectID().Hex()
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now()
+	}
+
+	_, err := r.collection.InsertOne(ctx, notification)
+	return notification, err
 }
+
+// CreateMany inserts several notifications at once
 func (r *NotificationRepository) CreateMany(ctx context.Context, notifications []models.Notification) error {
-	return nil
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	documents := make([]interface{}, len(notifications))
+	for i, notification := range notifications {
+		notification.ID = primitive.NewObjectID().Hex()
+		if notification.CreatedAt.IsZero() {
+			notification.CreatedAt = now
+		}
+		documents[i] = notification
+	}
+
+	_, err := r.collection.InsertMany(ctx, documents)
+	return err
 }
+
+// FindByID finds a notification by ID
 func (r *NotificationRepository) FindByID(ctx context.Context, id string) (models.Notification, error) {
-	return models.Notification{}, nil
+	var notification models.Notification
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&notification)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.Notification{}, nil
+		}
+		return models.Notification{}, err
+	}
+
+	return notification, nil
 }
-func (r *NotificationRepository) MarkAsRead(ctx context.Context, id string) error {
 
-	return nil
+// MarkAsRead marks a single notification as read
+func (r *NotificationRepository) MarkAsRead(ctx context.Context, id string) error {
+	filter := bson.M{"_id": id, "read": false}
+	update := bson.M{
+		"$set": bson.M{
+			"read":    true,
+			"read_at": time.Now(),
+		},
+	}
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
 }
-func (r *NotificationRepository) MarkAllAsRead(ctx context.Context, userID string) error {
 
-	return nil
+// MarkAllAsRead marks every unread notification of a user as read and returns how many changed
+func (r *NotificationRepository) MarkAllAsRead(ctx context.Context, userID string) (int64, error) {
+	filter := bson.M{"user_id": userID, "read": false}
+	update := bson.M{
+		"$set": bson.M{
+			"read":    true,
+			"read_at": time.Now(),
+		},
+	}
+	result, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
 }
+
+// Delete removes a notification
 func (r *NotificationRepository) Delete(ctx context.Context, id string) error {
-	return nil
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
 }
+
+// ListByUserID retrieves the notifications of a user with pagination, newest first
 func (r *NotificationRepository) ListByUserID(ctx context.Context, userID string, page, limit int64, unreadOnly bool) (models.Notifications, int64, error) {
-	return models.Notifications{}, 0, nil
+	notifications := models.Notifications{}
+
+	filter := bson.M{"user_id": userID}
+	if unreadOnly {
+		filter["read"] = false
+	}
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.M{"created_at": -1})
+	findOptions.SetSkip((page - 1) * limit)
+	findOptions.SetLimit(limit)
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &notifications); err != nil {
+		return nil, 0, err
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return notifications, total, nil
 }
+
+// CountUnread returns the number of unread notifications of a user
 func (r *NotificationRepository) CountUnread(ctx context.Context, userID string) (int64, error) {
-	return 0, nil
+	return r.collection.CountDocuments(ctx, bson.M{"user_id": userID, "read": false})
 }
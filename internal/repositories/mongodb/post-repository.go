@@ -37,14 +37,18 @@ func (r *PostRepository) Create(ctx context.Context, post models.Post) (models.P
 	return post, err
 }
 
-// FindByID finds a post by ID
+// FindByID finds a post by ID, including posts hidden for moderation
 func (r *PostRepository) FindByID(ctx context.Context, id string) (models.Post, error) {
-	var post models.Post
+	return r.findOne(ctx, bson.M{"_id": id, "deleted_at": nil})
+}
 
-	filter := bson.M{
-		"_id":        id,
-		"deleted_at": nil,
-	}
+// FindVisibleByID finds a post by ID unless it is hidden for moderation
+func (r *PostRepository) FindVisibleByID(ctx context.Context, id string) (models.Post, error) {
+	return r.findOne(ctx, bson.M{"_id": id, "deleted_at": nil, "hidden": bson.M{"$ne": true}})
+}
+
+func (r *PostRepository) findOne(ctx context.Context, filter bson.M) (models.Post, error) {
+	var post models.Post
 
 	err := r.collection.FindOne(ctx, filter).Decode(&post)
 	if err != nil {
@@ -83,6 +87,19 @@ func (r *PostRepository) Delete(ctx context.Context, id string) error {
 	return err
 }
 
+// SetHidden hides a post from the listings while it waits for moderation, or shows it again
+func (r *PostRepository) SetHidden(ctx context.Context, id string, hidden bool) error {
+	filter := bson.M{"_id": id}
+	update := bson.M{
+		"$set": bson.M{
+			"hidden":     hidden,
+			"updated_at": time.Now(),
+		},
+	}
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
 // List returns a paginated list of posts
 func (r *PostRepository) List(ctx context.Context, page, limit int64) (models.Posts, int64, error) {
 
@@ -139,42 +156,167 @@ func (r *PostRepository) RemoveComment(ctx context.Context, postID, commentID st
 	return err
 }
 
+// AddLike adds the like of a user to a post.
+// last_like_user_id records who liked so the change stream bus can tell clients.
 func (r *PostRepository) AddLike(ctx context.Context, postID, userId string) error {
-	post, err := r.FindByID(ctx, postID)
-	if err != nil {
-		return err
+	filter := bson.M{"_id": postID, "likeduserid": bson.M{"$ne": userId}}
+	update := bson.M{
+		"$inc":  bson.M{"likes": 1},
+		"$push": bson.M{"likeduserid": userId},
+		"$set":  bson.M{"last_like_user_id": userId, "updated_at": time.Now()},
 	}
-	post.Likes = post.Likes + 1
-	post.LikedUserId = append(post.LikedUserId, string(userId))
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
 
-	err = r.Update(ctx, post)
-	if err != nil {
-		return err
+// RemoveLike removes the like of a user from a post
+func (r *PostRepository) RemoveLike(ctx context.Context, postID, userId string) error {
+	filter := bson.M{"_id": postID, "likeduserid": userId}
+	update := bson.M{
+		"$inc":  bson.M{"likes": -1},
+		"$pull": bson.M{"likeduserid": userId},
+		"$set":  bson.M{"last_like_user_id": userId, "updated_at": time.Now()},
 	}
+	_, err := r.collection.UpdateOne(ctx, filter, update)
 	return err
 }
 
-func (r *PostRepository) RemoveLike(ctx context.Context, postID, userId string) error {
-	post, err := r.FindByID(ctx, postID)
+// ListPage returns up to limit posts older than the cursor, newest first.
+// A zero cursor starts at the newest post.
+func (r *PostRepository) ListPage(ctx context.Context, after models.Cursor, limit int64) (models.Posts, error) {
+	posts := models.Posts{}
+
+	filter := bson.M{"deleted_at": nil, "hidden": bson.M{"$ne": true}}
+	if !after.IsZero() {
+		filter["$or"] = bson.A{
+			bson.M{"created_at": bson.M{"$lt": after.CreatedAt}},
+			bson.M{"created_at": after.CreatedAt, "_id": bson.M{"$lt": after.ID}},
+		}
+	}
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+	findOptions.SetLimit(limit)
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if post.Likes > 0 {
-		post.Likes = post.Likes - 1
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &posts); err != nil {
+		return nil, err
 	}
-	for i := 0; i < len(post.LikedUserId); {
-		if post.LikedUserId[i] == userId {
-			if len(post.LikedUserId) > i {
-				post.LikedUserId = append(post.LikedUserId[:i], post.LikedUserId[i+1:]...)
-				continue
-			}
+
+	return posts, nil
+}
+
+// CountCreatedSince counts the posts created since the given time
+func (r *PostRepository) CountCreatedSince(ctx context.Context, since time.Time) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"deleted_at": nil, "created_at": bson.M{"$gte": since}})
+}
+
+// ListPageWithComments works like ListPage but loads, in the same aggregation, the comment
+// count of each post and its first comments with their first replies
+func (r *PostRepository) ListPageWithComments(ctx context.Context, after models.Cursor, limit int64, preview models.CommentPreview) (models.Posts, error) {
+	posts := models.Posts{}
+
+	filter := bson.M{"deleted_at": nil, "hidden": bson.M{"$ne": true}}
+	if !after.IsZero() {
+		filter["$or"] = bson.A{
+			bson.M{"created_at": bson.M{"$lt": after.CreatedAt}},
+			bson.M{"created_at": after.CreatedAt, "_id": bson.M{"$lt": after.ID}},
 		}
-		i++
 	}
 
-	err = r.Update(ctx, post)
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$sort", Value: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}}},
+		{{Key: "$limit", Value: limit}},
+	}
+	pipeline = append(pipeline, commentPreviewStages("post", "comment_count", preview.Comments, preview.Replies)...)
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return err
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// FindByIDWithComments finds a post by ID together with its comment count and first comments
+func (r *PostRepository) FindByIDWithComments(ctx context.Context, id string, preview models.CommentPreview) (models.Post, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"_id": id, "deleted_at": nil, "hidden": bson.M{"$ne": true}}}},
+	}
+	pipeline = append(pipeline, commentPreviewStages("post", "comment_count", preview.Comments, preview.Replies)...)
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return models.Post{}, err
+	}
+	defer cursor.Close(ctx)
+
+	var post models.Post
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(&post); err != nil {
+			return models.Post{}, err
+		}
+	}
+
+	return post, cursor.Err()
+}
+
+// commentPreviewStages returns the stages that add to each document the number of comments
+// referencing it (in countField) and its first comments, oldest first, in the comments field.
+// When replies is greater than zero each previewed comment gets the same treatment one level down.
+func commentPreviewStages(reference, countField string, comments, replies int64) mongo.Pipeline {
+	match := bson.M{
+		"$expr": bson.M{"$and": bson.A{
+			bson.M{"$eq": bson.A{"$reference_id", "$$referenceId"}},
+			bson.M{"$eq": bson.A{"$reference", reference}},
+		}},
+		"deleted_at": nil,
+		"hidden":     bson.M{"$ne": true},
+	}
+
+	previewPipeline := bson.A{
+		bson.M{"$match": match},
+		bson.M{"$sort": bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}},
+		bson.M{"$limit": comments},
+	}
+	if replies > 0 {
+		for _, stage := range commentPreviewStages("comment", "reply_count", replies, 0) {
+			previewPipeline = append(previewPipeline, stage)
+		}
+	}
+
+	stages := mongo.Pipeline{
+		{{Key: "$lookup", Value: bson.M{
+			"from":     CommentsCollection,
+			"let":      bson.M{"referenceId": "$_id"},
+			"pipeline": bson.A{bson.M{"$match": match}, bson.M{"$count": "count"}},
+			"as":       "comment_stats",
+		}}},
+		{{Key: "$set", Value: bson.M{
+			countField: bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$comment_stats.count", 0}}, 0}},
+		}}},
+		{{Key: "$unset", Value: "comment_stats"}},
+	}
+
+	if comments > 0 {
+		stages = append(stages, bson.D{{Key: "$lookup", Value: bson.M{
+			"from":     CommentsCollection,
+			"let":      bson.M{"referenceId": "$_id"},
+			"pipeline": previewPipeline,
+			"as":       "comments",
+		}}})
+	}
+
+	return stages
 }
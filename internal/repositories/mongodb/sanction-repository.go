@@ -0,0 +1,134 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/anamalala/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SanctionRepository implements the interfaces.SanctionRepository interface
+type SanctionRepository struct {
+	collection *mongo.Collection
+}
+
+// NewSanctionRepository creates a new SanctionRepository
+func NewSanctionRepository(client *Client) *SanctionRepository {
+	return &SanctionRepository{
+		collection: client.GetCollection(SanctionsCollection),
+	}
+}
+
+// Create inserts a new sanction
+func (r *SanctionRepository) Create(ctx context.Context, sanction models.Sanction) (models.Sanction, error) {
+	sanction.ID = primitive.NewObjectID().Hex()
+	sanction.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, sanction)
+	if err != nil {
+		return models.Sanction{}, err
+	}
+	return sanction, nil
+}
+
+// FindByID finds a sanction by ID
+func (r *SanctionRepository) FindByID(ctx context.Context, id string) (models.Sanction, error) {
+	var sanction models.Sanction
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&sanction)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.Sanction{}, nil
+		}
+		return models.Sanction{}, err
+	}
+
+	return sanction, nil
+}
+
+// ListByUser returns a paginated history of the sanctions of a user, newest first
+func (r *SanctionRepository) ListByUser(ctx context.Context, userID string, page, limit int64) (models.Sanctions, int64, error) {
+	filter := bson.M{"user_id": userID}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	if page > 0 && limit > 0 {
+		findOptions.SetSkip((page - 1) * limit)
+		findOptions.SetLimit(limit)
+	}
+
+	sanctions, err := r.find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return sanctions, total, nil
+}
+
+// ListInForce returns the mutes and bans of a user that are neither lifted nor expired
+func (r *SanctionRepository) ListInForce(ctx context.Context, userID string, now time.Time) (models.Sanctions, error) {
+	filter := bson.M{
+		"user_id": userID,
+		"type":    bson.M{"$in": []models.SanctionType{models.SanctionMute, models.SanctionBan}},
+		"lifted":  false,
+		"$or": []bson.M{
+			{"expires_at": bson.M{"$exists": false}},
+			{"expires_at": bson.M{"$gt": now}},
+		},
+	}
+	return r.find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+}
+
+// ListExpired returns the mutes and bans whose expiry has passed but were not lifted yet, oldest first
+func (r *SanctionRepository) ListExpired(ctx context.Context, now time.Time, limit int64) (models.Sanctions, error) {
+	filter := bson.M{
+		"lifted":     false,
+		"expires_at": bson.M{"$lte": now},
+	}
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "expires_at", Value: 1}}).
+		SetLimit(limit)
+	return r.find(ctx, filter, findOptions)
+}
+
+// Lift marks a sanction as lifted unless it already was
+func (r *SanctionRepository) Lift(ctx context.Context, id, liftedBy, reason string) (bool, error) {
+	update := bson.M{
+		"$set": bson.M{
+			"lifted":      true,
+			"lifted_at":   time.Now(),
+			"lifted_by":   liftedBy,
+			"lift_reason": reason,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "lifted": false}, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// find returns the sanctions matching filter
+func (r *SanctionRepository) find(ctx context.Context, filter bson.M, findOptions *options.FindOptions) (models.Sanctions, error) {
+	sanctions := models.Sanctions{}
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &sanctions); err != nil {
+		return nil, err
+	}
+	return sanctions, nil
+}
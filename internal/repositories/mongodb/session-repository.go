@@ -0,0 +1,132 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/anamalala/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SessionRepository implements the interfaces.SessionRepository interface
+type SessionRepository struct {
+	collection *mongo.Collection
+}
+
+// NewSessionRepository creates a new SessionRepository
+func NewSessionRepository(client *Client) *SessionRepository {
+	return &SessionRepository{
+		collection: client.GetCollection(SessionsCollection),
+	}
+}
+
+// Create inserts a new session into the database
+func (r *SessionRepository) Create(ctx context.Context, session models.Session) (models.Session, error) {
+	if session.ID == "" {
+		session.ID = primitive.NewObjectID().Hex()
+	}
+	session.CreatedAt = time.Now()
+	session.LastUsedAt = session.CreatedAt
+	session.Revoked = false
+
+	_, err := r.collection.InsertOne(ctx, session)
+	return session, err
+}
+
+// FindByID finds a session by ID
+func (r *SessionRepository) FindByID(ctx context.Context, id string) (models.Session, error) {
+	var session models.Session
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&session)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.Session{}, nil
+		}
+		return models.Session{}, err
+	}
+
+	return session, nil
+}
+
+// Rotate atomically replaces the refresh token of an active session.
+// It returns false when the session is revoked or the old token is no longer current.
+func (r *SessionRepository) Rotate(ctx context.Context, id, oldTokenHash, newTokenHash, ip string, expiresAt time.Time) (bool, error) {
+	filter := bson.M{
+		"_id":                id,
+		"refresh_token_hash": oldTokenHash,
+		"revoked":            false,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"refresh_token_hash": newTokenHash,
+			"ip":                 ip,
+			"last_used_at":       time.Now(),
+			"expires_at":         expiresAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount == 1, nil
+}
+
+// Revoke marks a session as revoked
+func (r *SessionRepository) Revoke(ctx context.Context, id string) error {
+	filter := bson.M{"_id": id, "revoked": false}
+	update := bson.M{
+		"$set": bson.M{
+			"revoked":    true,
+			"revoked_at": time.Now(),
+		},
+	}
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// RevokeAllByUserID revokes every active session of a user
+func (r *SessionRepository) RevokeAllByUserID(ctx context.Context, userID string) (int64, error) {
+	filter := bson.M{"user_id": userID, "revoked": false}
+	update := bson.M{
+		"$set": bson.M{
+			"revoked":    true,
+			"revoked_at": time.Now(),
+		},
+	}
+	result, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// ListActiveByUserID returns the active sessions of a user, most recently used first
+func (r *SessionRepository) ListActiveByUserID(ctx context.Context, userID string) (models.Sessions, error) {
+	sessions := models.Sessions{}
+
+	filter := bson.M{
+		"user_id":    userID,
+		"revoked":    false,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.M{"last_used_at": -1})
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
@@ -0,0 +1,177 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/anamalala/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SMSCampaignRepository implements the interfaces.SMSCampaignRepository interface
+type SMSCampaignRepository struct {
+	collection *mongo.Collection
+}
+
+// NewSMSCampaignRepository creates a new SMSCampaignRepository
+func NewSMSCampaignRepository(client *Client) *SMSCampaignRepository {
+	return &SMSCampaignRepository{
+		collection: client.GetCollection(SMSCampaignsCollection),
+	}
+}
+
+// Create inserts a new campaign into the database
+func (r *SMSCampaignRepository) Create(ctx context.Context, campaign models.SMSCampaign) (models.SMSCampaign, error) {
+	campaign.ID = primitive.NewObjectID().Hex()
+	campaign.CreatedAt = time.Now()
+	campaign.UpdatedAt = campaign.CreatedAt
+	if campaign.ScheduledAt.IsZero() {
+		campaign.ScheduledAt = campaign.CreatedAt
+	}
+
+	_, err := r.collection.InsertOne(ctx, campaign)
+	return campaign, err
+}
+
+// FindByID finds a campaign by ID
+func (r *SMSCampaignRepository) FindByID(ctx context.Context, id string) (models.SMSCampaign, error) {
+	var campaign models.SMSCampaign
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&campaign)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.SMSCampaign{}, nil
+		}
+		return models.SMSCampaign{}, err
+	}
+
+	return campaign, nil
+}
+
+// List retrieves campaigns with pagination, newest first.
+// With a province, only the campaigns whose audience is exactly that province are returned.
+func (r *SMSCampaignRepository) List(ctx context.Context, province string, page, limit int64) (models.SMSCampaigns, int64, error) {
+	var campaigns models.SMSCampaigns
+
+	filter := bson.M{}
+	if province != "" {
+		filter["audience.provinces"] = bson.A{province}
+	}
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.M{"created_at": -1})
+	findOptions.SetSkip((page - 1) * limit)
+	findOptions.SetLimit(limit)
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &campaigns); err != nil {
+		return nil, 0, err
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return campaigns, total, nil
+}
+
+// ClaimDue moves the oldest scheduled campaign whose time has come to the dispatching state
+// and leases it to the caller. A campaign still dispatching after its lease expired was
+// interrupted and is claimed again. It returns a zero value when no campaign is due.
+func (r *SMSCampaignRepository) ClaimDue(ctx context.Context, lease time.Duration) (models.SMSCampaign, error) {
+	now := time.Now()
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{
+				"state":        models.SMSCampaignScheduled,
+				"scheduled_at": bson.M{"$lte": now},
+			},
+			bson.M{
+				"state":        models.SMSCampaignDispatching,
+				"locked_until": bson.M{"$not": bson.M{"$gt": now}},
+			},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"state":        models.SMSCampaignDispatching,
+			"locked_until": now.Add(lease),
+			"updated_at":   now,
+		},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.M{"scheduled_at": 1}).
+		SetReturnDocument(options.After)
+
+	var campaign models.SMSCampaign
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&campaign)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.SMSCampaign{}, nil
+		}
+		return models.SMSCampaign{}, err
+	}
+
+	return campaign, nil
+}
+
+// MarkDispatched records that the campaign messages were placed in the outbox
+func (r *SMSCampaignRepository) MarkDispatched(ctx context.Context, id string, total int) error {
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"state":         models.SMSCampaignDispatched,
+			"total":         total,
+			"dispatched_at": now,
+			"updated_at":    now,
+		},
+	}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
+
+// MarkFailed records that the campaign could not be dispatched
+func (r *SMSCampaignRepository) MarkFailed(ctx context.Context, id, lastError string) error {
+	update := bson.M{
+		"$set": bson.M{
+			"state":      models.SMSCampaignFailed,
+			"last_error": lastError,
+			"updated_at": time.Now(),
+		},
+	}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
+
+// Cancel cancels a campaign that is still scheduled.
+// It returns false when the campaign does not exist or was already dispatched.
+func (r *SMSCampaignRepository) Cancel(ctx context.Context, id string) (bool, error) {
+	now := time.Now()
+	filter := bson.M{
+		"_id":   id,
+		"state": models.SMSCampaignScheduled,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"state":        models.SMSCampaignCancelled,
+			"cancelled_at": now,
+			"updated_at":   now,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount == 1, nil
+}
@@ -0,0 +1,212 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/anamalala/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SMSOutboxRepository implements the interfaces.SMSOutboxRepository interface
+type SMSOutboxRepository struct {
+	collection *mongo.Collection
+}
+
+// NewSMSOutboxRepository creates a new SMSOutboxRepository
+func NewSMSOutboxRepository(client *Client) *SMSOutboxRepository {
+	return &SMSOutboxRepository{
+		collection: client.GetCollection(SMSOutboxCollection),
+	}
+}
+
+// Enqueue inserts messages into the outbox with the queued status
+func (r *SMSOutboxRepository) Enqueue(ctx context.Context, messages models.SMSMessages) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	documents := make([]interface{}, len(messages))
+	for i, message := range messages {
+		message.ID = primitive.NewObjectID().Hex()
+		message.Status = models.SMSStatusQueued
+		message.Attempts = 0
+		if message.NextAttemptAt.IsZero() {
+			message.NextAttemptAt = now
+		}
+		message.CreatedAt = now
+		message.UpdatedAt = now
+		documents[i] = message
+	}
+
+	// A campaign dispatched again after an interruption only adds the recipients still missing
+	_, err := r.collection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+	if onlyDuplicateKeys(err) {
+		return nil
+	}
+	return err
+}
+
+// onlyDuplicateKeys reports whether a bulk insert failed only because some documents already existed
+func onlyDuplicateKeys(err error) bool {
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil || len(bulkErr.WriteErrors) == 0 {
+		return false
+	}
+	for _, writeErr := range bulkErr.WriteErrors {
+		if !mongo.IsDuplicateKeyError(writeErr) {
+			return false
+		}
+	}
+	return true
+}
+
+// ClaimNext leases the next due queued message to the caller.
+// The lease keeps other workers away; if the worker dies the message becomes claimable again when it expires.
+func (r *SMSOutboxRepository) ClaimNext(ctx context.Context, lease time.Duration) (models.SMSMessage, error) {
+	now := time.Now()
+	filter := bson.M{
+		"status":          models.SMSStatusQueued,
+		"next_attempt_at": bson.M{"$lte": now},
+		"locked_until":    bson.M{"$lte": now},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"locked_until": now.Add(lease),
+			"updated_at":   now,
+		},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.M{"next_attempt_at": 1}).
+		SetReturnDocument(options.After)
+
+	var message models.SMSMessage
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&message)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.SMSMessage{}, nil
+		}
+		return models.SMSMessage{}, err
+	}
+
+	return message, nil
+}
+
+// MarkSent records a successful hand-off to the provider
+func (r *SMSOutboxRepository) MarkSent(ctx context.Context, id, providerMessageID string) error {
+	now := time.Now()
+	filter := bson.M{"_id": id}
+	update := bson.M{
+		"$set": bson.M{
+			"status":              models.SMSStatusSent,
+			"provider_message_id": providerMessageID,
+			"sent_at":             now,
+			"updated_at":          now,
+			"locked_until":        time.Time{},
+		},
+		"$inc":   bson.M{"attempts": 1},
+		"$unset": bson.M{"last_error": ""},
+	}
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// MarkRetry records a failed attempt and schedules the next one
+func (r *SMSOutboxRepository) MarkRetry(ctx context.Context, id string, nextAttemptAt time.Time, lastError string) error {
+	filter := bson.M{"_id": id}
+	update := bson.M{
+		"$set": bson.M{
+			"next_attempt_at": nextAttemptAt,
+			"last_error":      lastError,
+			"updated_at":      time.Now(),
+			"locked_until":    time.Time{},
+		},
+		"$inc": bson.M{"attempts": 1},
+	}
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// MarkFailed records a permanent failure
+func (r *SMSOutboxRepository) MarkFailed(ctx context.Context, id, lastError string) error {
+	filter := bson.M{"_id": id}
+	update := bson.M{
+		"$set": bson.M{
+			"status":       models.SMSStatusFailed,
+			"last_error":   lastError,
+			"updated_at":   time.Now(),
+			"locked_until": time.Time{},
+		},
+		"$inc": bson.M{"attempts": 1},
+	}
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// UpdateDeliveryStatus applies a delivery report received from the provider
+func (r *SMSOutboxRepository) UpdateDeliveryStatus(ctx context.Context, providerMessageID string, status models.SMSStatus, reason string) error {
+	now := time.Now()
+	set := bson.M{
+		"status":     status,
+		"updated_at": now,
+	}
+	if status == models.SMSStatusDelivered {
+		set["delivered_at"] = now
+	}
+	if reason != "" {
+		set["last_error"] = reason
+	}
+
+	filter := bson.M{"provider_message_id": providerMessageID}
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("no message found with the provided provider id")
+	}
+
+	return nil
+}
+
+// CountByCampaign returns the number of messages per status for a campaign
+func (r *SMSOutboxRepository) CountByCampaign(ctx context.Context, campaignID string) (map[models.SMSStatus]int, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"campaign_id": campaignID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$status",
+			"count": bson.M{"$sum": 1},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Status models.SMSStatus `bson:"_id"`
+		Count  int              `bson:"count"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	counts := map[models.SMSStatus]int{
+		models.SMSStatusQueued:    0,
+		models.SMSStatusSent:      0,
+		models.SMSStatusFailed:    0,
+		models.SMSStatusDelivered: 0,
+	}
+	for _, result := range results {
+		counts[result.Status] = result.Count
+	}
+
+	return counts, nil
+}
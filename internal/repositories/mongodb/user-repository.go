@@ -180,7 +180,11 @@ func (r *UserRepository) UpdatePassword(ctx context.Context, id string, password
 			"password":   password,
 			"updated_at": time.Now(),
 		},
-		"$unset": bson.M{"password_reset": ""},
+		"$unset": bson.M{
+			"password_reset":  "",
+			"resetcode":       "",
+			"resetcodeexpiry": "",
+		},
 	}
 
 	_, err := r.collection.UpdateOne(ctx, filter, update)
@@ -245,7 +249,8 @@ func (r *UserRepository) GetAllContacts(ctx context.Context) ([]string, error) {
 	var contacts []string
 	projection := bson.M{"contact": 1, "_id": 0}
 	findOptions := options.Find().SetProjection(projection)
-	cursor, err := r.collection.Find(ctx, bson.M{"active": true}, findOptions)
+	filter := bson.M{"active": true, "pending_verification": bson.M{"$ne": true}}
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -270,7 +275,7 @@ func (r *UserRepository) GetAllContacts(ctx context.Context) ([]string, error) {
 // GetContactsByProvince returns all user contact numbers for a province
 func (r *UserRepository) GetContactsByProvince(ctx context.Context, province string) ([]string, error) {
 	var contacts []string
-	filter := bson.M{"province": province, "active": true}
+	filter := bson.M{"province": province, "active": true, "pending_verification": bson.M{"$ne": true}}
 	projection := bson.M{"contact": 1, "_id": 0}
 	findOptions := options.Find().SetProjection(projection)
 	cursor, err := r.collection.Find(ctx, filter, findOptions)
@@ -296,6 +301,41 @@ func (r *UserRepository) GetContactsByProvince(ctx context.Context, province str
 	return contacts, nil
 }
 
+// FindByAudience returns the verified users matching the campaign audience.
+// Only active users are returned unless the audience sets Active.
+// Only the fields needed to address a message are loaded.
+func (r *UserRepository) FindByAudience(ctx context.Context, audience models.SMSAudience) (models.Users, error) {
+	var users models.Users
+
+	filter := bson.M{"pending_verification": bson.M{"$ne": true}}
+	if len(audience.Provinces) > 0 {
+		filter["province"] = bson.M{"$in": audience.Provinces}
+	}
+	if len(audience.Roles) > 0 {
+		filter["role"] = bson.M{"$in": audience.Roles}
+	}
+	filter["active"] = true
+	if audience.Active != nil {
+		filter["active"] = *audience.Active
+	}
+	if len(audience.UserIDs) > 0 {
+		filter["_id"] = bson.M{"$in": audience.UserIDs}
+	}
+
+	projection := bson.M{"_id": 1, "name": 1, "province": 1, "contact": 1}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetProjection(projection))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
 func (r *UserRepository) InactiveUsers(ctx context.Context, page, limit int64) (models.Users, int64, error) {
 	var users models.Users
 
@@ -324,3 +364,140 @@ func (r *UserRepository) InactiveUsers(ctx context.Context, page, limit int64) (
 
 	return users, total, nil
 }
+
+// AddConnection counts a new WebSocket connection of the user, on any replica, and marks them online.
+// It reports whether the user was offline before. The presence fields (online, connections and
+// last_active_at) are kept out of models.User so that Update never writes stale values over them.
+func (r *UserRepository) AddConnection(ctx context.Context, userID string, at time.Time) (bool, error) {
+	filter := bson.M{"_id": userID}
+	update := bson.M{
+		"$inc": bson.M{"connections": 1},
+		"$set": bson.M{"online": true, "last_seen_at": at},
+	}
+	return r.markOnline(ctx, filter, update)
+}
+
+// RemoveConnection counts a closed WebSocket connection and returns how many the user still has open
+func (r *UserRepository) RemoveConnection(ctx context.Context, userID string, at time.Time) (int, error) {
+	filter := bson.M{"_id": userID, "connections": bson.M{"$gt": 0}}
+	update := bson.M{
+		"$inc": bson.M{"connections": -1},
+		"$set": bson.M{"last_seen_at": at},
+	}
+	opts := options.FindOneAndUpdate().
+		SetProjection(bson.M{"connections": 1}).
+		SetReturnDocument(options.After)
+
+	var user struct {
+		Connections int `bson:"connections"`
+	}
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&user)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return user.Connections, nil
+}
+
+// MarkActive records activity of the user on the API and marks them online.
+// It reports whether the user was offline before.
+func (r *UserRepository) MarkActive(ctx context.Context, userID string, at time.Time) (bool, error) {
+	filter := bson.M{"_id": userID}
+	update := bson.M{"$set": bson.M{"online": true, "last_active_at": at, "last_seen_at": at}}
+	return r.markOnline(ctx, filter, update)
+}
+
+// markOnline applies an update that marks the user online and reports whether they were offline before
+func (r *UserRepository) markOnline(ctx context.Context, filter, update bson.M) (bool, error) {
+	opts := options.FindOneAndUpdate().
+		SetProjection(bson.M{"online": 1}).
+		SetReturnDocument(options.Before)
+
+	var user struct {
+		Online bool `bson:"online"`
+	}
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&user)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return false, nil
+		}
+		return false, err
+	}
+	return !user.Online, nil
+}
+
+// idleFilter matches the online users with no open connection and no API activity since the given time
+func idleFilter(since time.Time) bson.M {
+	return bson.M{
+		"online":         true,
+		"connections":    bson.M{"$not": bson.M{"$gt": 0}},
+		"last_active_at": bson.M{"$not": bson.M{"$gte": since}},
+	}
+}
+
+// FindIdleOnline returns the IDs of the online users with no open connection and no API activity since the given time
+func (r *UserRepository) FindIdleOnline(ctx context.Context, since time.Time) ([]string, error) {
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+	cursor, err := r.collection.Find(ctx, idleFilter(since), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []struct {
+		ID string `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]string, len(users))
+	for i, user := range users {
+		userIDs[i] = user.ID
+	}
+	return userIDs, nil
+}
+
+// SetOfflineIfIdle marks the user offline if they have no open connection and no API activity
+// since the given time. It reports whether this call changed the user to offline, so that
+// only one replica announces it.
+func (r *UserRepository) SetOfflineIfIdle(ctx context.Context, userID string, since time.Time) (bool, error) {
+	filter := idleFilter(since)
+	filter["_id"] = userID
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"online": false}})
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// CountOnlineByProvince counts the online users, grouped by province
+func (r *UserRepository) CountOnlineByProvince(ctx context.Context) (map[string]int64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"online": true}}},
+		{{Key: "$group", Value: bson.M{"_id": "$province", "count": bson.M{"$sum": 1}}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Province string `bson:"_id"`
+		Count    int64  `bson:"count"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(results))
+	for _, result := range results {
+		counts[result.Province] = result.Count
+	}
+	return counts, nil
+}
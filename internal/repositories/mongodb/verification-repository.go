@@ -0,0 +1,108 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/anamalala/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// VerificationRepository implements the interfaces.VerificationRepository interface
+type VerificationRepository struct {
+	collection *mongo.Collection
+}
+
+// NewVerificationRepository creates a new VerificationRepository
+func NewVerificationRepository(client *Client) *VerificationRepository {
+	return &VerificationRepository{
+		collection: client.GetCollection(VerificationCodesCollection),
+	}
+}
+
+// Save stores the current code for a contact and purpose, replacing any previous one
+func (r *VerificationRepository) Save(ctx context.Context, code models.VerificationCode) error {
+	filter := bson.M{
+		"contact": code.Contact,
+		"purpose": code.Purpose,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"code_hash":    code.CodeHash,
+			"attempts":     code.Attempts,
+			"send_count":   code.SendCount,
+			"expires_at":   code.ExpiresAt,
+			"last_sent_at": code.LastSentAt,
+			"locked_until": code.LockedUntil,
+		},
+		"$setOnInsert": bson.M{
+			"_id":        primitive.NewObjectID().Hex(),
+			"created_at": time.Now(),
+		},
+	}
+
+	opts := options.Update().SetUpsert(true)
+	_, err := r.collection.UpdateOne(ctx, filter, update, opts)
+	return err
+}
+
+// FindByContact finds the current code for a contact and purpose
+func (r *VerificationRepository) FindByContact(ctx context.Context, contact string, purpose models.VerificationPurpose) (models.VerificationCode, error) {
+	var code models.VerificationCode
+	filter := bson.M{
+		"contact": contact,
+		"purpose": purpose,
+	}
+
+	err := r.collection.FindOne(ctx, filter).Decode(&code)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.VerificationCode{}, nil
+		}
+		return models.VerificationCode{}, err
+	}
+
+	return code, nil
+}
+
+// RegisterAttempt atomically counts a verification attempt.
+// It returns false when the code has already reached the maximum number of attempts.
+func (r *VerificationRepository) RegisterAttempt(ctx context.Context, id string, maxAttempts int) (bool, error) {
+	filter := bson.M{
+		"_id":      id,
+		"attempts": bson.M{"$lt": maxAttempts},
+	}
+	update := bson.M{
+		"$inc": bson.M{"attempts": 1},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount == 1, nil
+}
+
+// Lock invalidates the current code and blocks new codes until the given time
+func (r *VerificationRepository) Lock(ctx context.Context, id string, until time.Time) error {
+	filter := bson.M{"_id": id}
+	update := bson.M{
+		"$set": bson.M{
+			"code_hash":    "",
+			"locked_until": until,
+		},
+	}
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// Delete deletes a code by ID
+func (r *VerificationRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/anamalala/internal/handlers"
 	"github.com/anamalala/internal/middlewares"
+	"github.com/anamalala/internal/models"
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,6 +17,13 @@ func SetupRoutes(
 	chatroomHandler handlers.ChatroomHandler,
 	suggestionHandler handlers.SuggestionHandler,
 	adminHandler handlers.AdminHandler,
+	sessionHandler handlers.SessionHandler,
+	smsHandler handlers.SMSHandler,
+	notificationHandler handlers.NotificationHandler,
+	mediaHandler handlers.MediaHandler,
+	moderationHandler handlers.ModerationHandler,
+	contentFilterHandler handlers.ContentFilterHandler,
+	sanctionHandler handlers.SanctionHandler,
 	authMiddleware middlewares.AuthMiddlewares,
 	adminMiddleware middlewares.AdminMiddlewares,
 ) {
@@ -31,14 +39,23 @@ func SetupRoutes(
 		auth := public.Group("/auth")
 		{
 			auth.POST("/register", authHandler.Register)
+			auth.POST("/verify-contact", authHandler.VerifyContact)
+			auth.POST("/resend-verification", authHandler.ResendVerificationCode)
 			auth.POST("/login", authHandler.Login)
-			auth.POST("/logout", authHandler.Logout)
+			auth.POST("/refresh", authHandler.RefreshToken)
+			auth.POST("/logout", authMiddleware.AuthMiddleware(), authHandler.Logout)
 			auth.POST("/reset-password-request", authHandler.RequestPasswordReset)
 			auth.POST("/reset-password", authHandler.ResetPassword)
 			auth.POST("/reset-password_code_confirm", authHandler.VerifyResetPasswordToken)
 
 		}
 
+		// Ficheiros enviados, através de links assinados
+		public.GET("/media/:id", mediaHandler.Download)
+
+		// Relatórios de entrega do provedor de SMS
+		public.POST("/sms/delivery-report", smsHandler.DeliveryReport)
+
 		// Informações (apenas leitura pública)
 		info := public.Group("/info").Use(authMiddleware.AuthMiddleware())
 		{
@@ -50,6 +67,8 @@ func SetupRoutes(
 	// Endpoints que exigem autenticação
 	authenticated := r.Group("/api/v1")
 	authenticated.Use(authMiddleware.AuthMiddleware())
+	// Rotas de escrita: recusadas a usuários suspensos ou em modo só de leitura
+	canWrite := adminMiddleware.BannedUserMiddleware()
 	{
 		// Perfil de usuário
 		user := authenticated.Group("/user")
@@ -57,6 +76,12 @@ func SetupRoutes(
 			user.GET("/profile", userHandler.GetProfile)
 			user.GET("/online_total", userHandler.GetTotalOnline)
 			user.PUT("/profile", userHandler.UpdateProfile)
+			user.GET("/sessions", sessionHandler.GetSessions)
+			user.DELETE("/sessions", sessionHandler.RevokeAllSessions)
+			user.DELETE("/sessions/:id", sessionHandler.RevokeSession)
+			user.GET("/notification-preferences", notificationHandler.GetPreferences)
+			user.PUT("/notification-preferences", notificationHandler.UpdatePreferences)
+			user.GET("/sanctions", sanctionHandler.GetMySanctions)
 		}
 
 		// Sala de bate-papo
@@ -64,54 +89,108 @@ func SetupRoutes(
 		{
 			chatroom.GET("/ws",chatroomHandler.HandleWebSocket)
 
-			chatroom.POST("/post", chatroomHandler.CreatePost)
+			chatroom.POST("/post", canWrite, chatroomHandler.CreatePost)
 			chatroom.GET("/posts", chatroomHandler.GetPosts)
 			chatroom.GET("/recent_post_total", chatroomHandler.GetRecentPostsTotal)
 			chatroom.GET("/post/:id", chatroomHandler.GetPostByID)
-			chatroom.POST("/post/:id/comment", chatroomHandler.CommentPost)
-			chatroom.POST("/comment/:id/comment", chatroomHandler.ReplayComment)
+			chatroom.POST("/post/:id/comment", canWrite, chatroomHandler.CommentPost)
+			chatroom.POST("/comment/:id/comment", canWrite, chatroomHandler.ReplayComment)
 			chatroom.GET("/post/:id/comments", chatroomHandler.GetCommentsByPostID)
-			chatroom.POST("/post/:id/like", chatroomHandler.LikePost)
-			chatroom.POST("/comment/:id/like", chatroomHandler.LikeComment)
+			chatroom.GET("/comment/:id/comments", chatroomHandler.GetReplies)
+			chatroom.GET("/comment/:id/thread", chatroomHandler.GetThread)
+			chatroom.POST("/post/:id/like", canWrite, chatroomHandler.LikePost)
+			chatroom.POST("/comment/:id/like", canWrite, chatroomHandler.LikeComment)
 			chatroom.DELETE("/post/:id", chatroomHandler.DeletePost)
 			chatroom.DELETE("/comment/:id", chatroomHandler.DeleteComment)
+			chatroom.POST("/post/:id/report", canWrite, moderationHandler.ReportPost)
+			chatroom.POST("/comment/:id/report", canWrite, moderationHandler.ReportComment)
+		}
+
+		// Notificações
+		notifications := authenticated.Group("/notifications")
+		{
+			notifications.GET("", notificationHandler.GetNotifications)
+			notifications.GET("/unread_total", notificationHandler.GetUnreadCount)
+			notifications.PUT("/read", notificationHandler.MarkAllAsRead)
+			notifications.PUT("/:id/read", notificationHandler.MarkAsRead)
+			notifications.DELETE("/:id", notificationHandler.DeleteNotification)
 		}
 
+		// Envio de ficheiros para anexar a postagens e informações
+		authenticated.POST("/media", canWrite, mediaHandler.Upload)
+
 		// Sugestões
 		suggestion := authenticated.Group("/suggestions")
 		{
-			suggestion.POST("", suggestionHandler.CreateSuggestion)
+			suggestion.POST("", canWrite, suggestionHandler.CreateSuggestion)
 		}
 
 	}
 
-	// Endpoints que exigem privilégios de administrador
+	// Endpoints da equipa de administração: cada rota exige a sua permissão
 	admin := r.Group("/api/v1/admin")
 	admin.Use(authMiddleware.AuthMiddleware(), adminMiddleware.AdminMiddleware())
+	can := adminMiddleware.RequirePermission
 	{
+		admin.GET("/permissions", adminHandler.GetMyPermissions)
+
 		// Gestão de usuários
-		admin.GET("/users", userHandler.GetAllUsers)
-		admin.GET("/users/province/:province", userHandler.GetUsersByProvince)
-		admin.POST("/users/:id/ban", adminHandler.BanUser)
-		admin.POST("/users/:id/unban", adminHandler.UnbanUser)
-		admin.POST("/users/:id/promote", adminHandler.PromoteToAdmin)
-		admin.DELETE("/users/:id", adminHandler.BanUser)
+		admin.GET("/users", can(models.PermUserView), userHandler.GetAllUsers)
+		admin.GET("/users/province/:province", can(models.PermUserView), userHandler.GetUsersByProvince)
+		admin.POST("/users/:id/ban", can(models.PermUserBan), adminHandler.BanUser)
+		admin.POST("/users/:id/unban", can(models.PermUserBan), adminHandler.UnbanUser)
+		admin.POST("/users/:id/promote", can(models.PermRoleAssign), adminHandler.PromoteToAdmin)
+		admin.DELETE("/users/:id", can(models.PermUserDelete), adminHandler.DeleteAccount)
+
+		// Roles e permissões
+		admin.GET("/roles", can(models.PermRoleAssign), adminHandler.GetRoles)
+		admin.GET("/roles/:role/users", can(models.PermRoleAssign), adminHandler.GetUsersByRole)
+		admin.PUT("/users/:id/role", can(models.PermRoleAssign), adminHandler.AssignRole)
+
+		// Sanções: advertências, modo só de leitura e suspensões
+		admin.POST("/users/:id/sanctions", can(models.PermUserBan), sanctionHandler.Issue)
+		admin.GET("/users/:id/sanctions", can(models.PermUserBan), sanctionHandler.GetHistory)
+		admin.POST("/sanctions/:id/lift", can(models.PermUserBan), sanctionHandler.Lift)
 
 		// Gestão de conteúdo (informações)
-		admin.POST("/info", infoHandler.Create)
-		admin.PUT("/info/:id", infoHandler.Update)
-		admin.DELETE("/info/:id", infoHandler.Delete)
+		admin.POST("/info", can(models.PermInfoPublish), infoHandler.Create)
+		admin.PUT("/info/:id", can(models.PermInfoPublish), infoHandler.Update)
+		admin.DELETE("/info/:id", can(models.PermInfoPublish), infoHandler.Delete)
 
 		// Moderação de conteúdo (posts e comentários)
-		admin.DELETE("/posts/:id", chatroomHandler.DeletePost)
-		admin.DELETE("/comments/:id", chatroomHandler.DeleteComment)
+		admin.DELETE("/posts/:id", can(models.PermPostDeleteAny), chatroomHandler.DeletePost)
+		admin.DELETE("/comments/:id", can(models.PermCommentDeleteAny), chatroomHandler.DeleteComment)
+
+		// Fila de denúncias
+		admin.GET("/moderation", can(models.PermModerationReview), moderationHandler.GetQueue)
+		admin.GET("/moderation/:id", can(models.PermModerationReview), moderationHandler.GetItem)
+		admin.POST("/moderation/:id/approve", can(models.PermModerationReview), moderationHandler.Approve)
+		admin.POST("/moderation/:id/remove", can(models.PermModerationReview), moderationHandler.Remove)
+		admin.POST("/moderation/:id/dismiss", can(models.PermModerationReview), moderationHandler.Dismiss)
+
+		// Lista de bloqueio do filtro de conteúdo
+		admin.GET("/content-filter/terms", can(models.PermContentFilter), contentFilterHandler.GetTerms)
+		admin.POST("/content-filter/terms", can(models.PermContentFilter), contentFilterHandler.AddTerm)
+		admin.DELETE("/content-filter/terms/:id", can(models.PermContentFilter), contentFilterHandler.DeleteTerm)
 
 		// Gestão de sugestões
-		admin.GET("/suggestions", suggestionHandler.GetAllSuggestions)
-		admin.GET("/suggestions/:id", suggestionHandler.GetSuggestionByID)
-		admin.PUT("/suggestions/:id/status", suggestionHandler.UpdateSuggestionStatus)
+		admin.GET("/suggestions", can(models.PermSuggestionManage), suggestionHandler.GetAllSuggestions)
+		admin.GET("/suggestions/:id", can(models.PermSuggestionManage), suggestionHandler.GetSuggestionByID)
+		admin.PUT("/suggestions/:id/status", can(models.PermSuggestionManage), suggestionHandler.UpdateSuggestionStatus)
+
+		// Campanhas de SMS
+		admin.POST("/sms/campaigns", can(models.PermSMSBroadcast), smsHandler.CreateCampaign)
+		admin.GET("/sms/campaigns", can(models.PermSMSBroadcast), smsHandler.GetCampaigns)
+		admin.GET("/sms/campaigns/:id", can(models.PermSMSBroadcast), smsHandler.GetCampaignStatus)
+		admin.POST("/sms/campaigns/:id/cancel", can(models.PermSMSBroadcast), smsHandler.CancelCampaign)
+		admin.POST("/sms/mass-message", can(models.PermSMSBroadcast), adminHandler.SendMassMessage)
 
 		// Estatísticas e dashboards
-		admin.GET("/stats/users", adminHandler.GetDashboardStats)
+		admin.GET("/stats/users", can(models.PermStatsView), adminHandler.GetDashboardStats)
+		admin.GET("/stats/websocket", can(models.PermStatsView), chatroomHandler.GetWebSocketMetrics)
+
+		// Histórico das ações dos administradores
+		admin.GET("/logs", can(models.PermAuditView), adminHandler.GetAdminLogs)
+		admin.GET("/logs/export", can(models.PermAuditView), adminHandler.ExportAdminLogs)
 	}
 }
@@ -3,112 +3,167 @@ package services
 import (
 	"context"
 	"errors"
-	"time"
+	"io"
 
 	"github.com/anamalala/internal/models"
 	"github.com/anamalala/internal/repositories/interfaces"
-	"github.com/anamalala/pkg/sms"
+)
+
+var (
+	// ErrInvalidRole indica um role que não existe
+	ErrInvalidRole = errors.New("role inválido")
+	// ErrRoleUnchanged indica que o usuário já tem o role pedido
+	ErrRoleUnchanged = errors.New("o usuário já tem este role")
+	// ErrRoleSelf indica um membro da equipa a alterar o seu próprio role
+	ErrRoleSelf = errors.New("não é possível alterar o seu próprio role")
+	// ErrRoleUserNotFound indica uma alteração de role de um usuário que não existe
+	ErrRoleUserNotFound = errors.New("usuário não encontrado")
+	// ErrDeleteUserNotFound indica a exclusão de um usuário que não existe
+	ErrDeleteUserNotFound = errors.New("usuário não encontrado")
 )
 
 type AdminService struct {
-	userRepo    interfaces.UserRepository
-	postRepo    interfaces.PostRepository
-	commentRepo interfaces.CommentRepository
-	smsService  *sms.Service
+	userRepo     interfaces.UserRepository
+	postRepo     interfaces.PostRepository
+	commentRepo  interfaces.CommentRepository
+	smsOutbox    SMSOutboxService
+	smsCampaigns SMSCampaignService
+	sanctions    SanctionService
+	audit        AuditService
 }
 
 func NewAdminService(
 	userRepo interfaces.UserRepository,
 	postRepo interfaces.PostRepository,
 	commentRepo interfaces.CommentRepository,
-	smsService *sms.Service,
+	smsOutbox SMSOutboxService,
+	smsCampaigns SMSCampaignService,
+	sanctions SanctionService,
+	audit AuditService,
 ) AdminService {
 	return AdminService{
-		userRepo:    userRepo,
-		postRepo:    postRepo,
-		commentRepo: commentRepo,
-		smsService:  smsService,
+		userRepo:     userRepo,
+		postRepo:     postRepo,
+		commentRepo:  commentRepo,
+		smsOutbox:    smsOutbox,
+		smsCampaigns: smsCampaigns,
+		sanctions:    sanctions,
+		audit:        audit,
 	}
 }
 
-func (s *AdminService) BanUser(ctx context.Context, userID string) error {
+// BanUser suspende a conta do usuário; durationHours igual a zero suspende até ser levantada
+func (s *AdminService) BanUser(ctx context.Context, adminID, userID, reason string, durationHours int) (models.Sanction, error) {
+	return s.sanctions.Issue(ctx, adminID, userID, models.SanctionCreation{
+		Type:          models.SanctionBan,
+		Reason:        reason,
+		DurationHours: durationHours,
+	})
+}
 
-	var user = models.User{}
+// UnbanUser levanta as suspensões em vigor do usuário
+func (s *AdminService) UnbanUser(ctx context.Context, adminID, userID, reason string) error {
+	return s.sanctions.LiftBans(ctx, adminID, userID, reason)
+}
 
-	// Obter usuário
-	user, err := s.userRepo.FindByID(ctx, userID)
-	if err != nil {
-		return err
+// AssignRole atribui um role ao usuário; o role define as suas permissões na administração.
+// A mudança vale logo, sem esperar pela renovação do token.
+func (s *AdminService) AssignRole(ctx context.Context, adminID, userID string, role models.Role) (models.User, error) {
+	if !role.Valid() {
+		return models.User{}, ErrInvalidRole
 	}
-
-	// Verificar se já está banido
-	if !user.Active {
-		return errors.New("usuário já está banido")
+	if adminID == userID {
+		return models.User{}, ErrRoleSelf
 	}
 
-	// Banir usuário
-	user.Active = false
-	user.UpdatedAt = time.Now()
-
-	return s.userRepo.Update(ctx, user)
-}
-
-func (s *AdminService) UnbanUser(ctx context.Context, userID string) error {
-
-	var user = models.User{}
-	// Obter usuário
 	user, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
-		return err
+		return models.User{}, err
 	}
-
-	// Verificar se está banido
-	if user.Active {
-		return errors.New("usuário não está banido")
+	if user.ID == "" {
+		return models.User{}, ErrRoleUserNotFound
+	}
+	if user.Role == role {
+		return models.User{}, ErrRoleUnchanged
 	}
 
-	// Desbanir usuário
-	user.Active = true
-	return s.userRepo.Update(ctx, user)
-}
-
-func (s *AdminService) PromoteToAdmin(ctx context.Context, userID string) error {
-	var user = models.User{}
+	before := snapshot(user)
+	user.Role = role
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return models.User{}, err
+	}
 
-	// Obter usuário
-	user, err := s.userRepo.FindByID(ctx, userID)
-	if err != nil {
-		return err
+	// Voltar a usuário comum retira as permissões; qualquer outro role concede-as
+	action := models.AuditUserPromote
+	if role == models.RoleUser {
+		action = models.AuditUserDemote
 	}
+	s.audit.Record(ctx, models.AuditEntry{
+		AdminID:    adminID,
+		Action:     action,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+		Before:     before,
+		After:      snapshot(user),
+	})
+
+	user.Password = ""
+	return user, nil
+}
 
-	// Verificar se já é administrador
-	if user.Role == "admin" {
+func (s *AdminService) PromoteToAdmin(ctx context.Context, adminID, userID string) error {
+	_, err := s.AssignRole(ctx, adminID, userID, models.RoleAdmin)
+	if errors.Is(err, ErrRoleUnchanged) {
 		return errors.New("usuário já é administrador")
 	}
-	// Promover a administrador
-	user.Role = "admin"
-	return s.userRepo.Update(ctx, user)
+	return err
 }
 
-func (s *AdminService) DemoteFromAdmin(ctx context.Context, userID string) error {
-
-	var user = models.User{}
-
-	// Obter usuário
+func (s *AdminService) DemoteFromAdmin(ctx context.Context, adminID, userID string) error {
 	user, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		return err
 	}
 
 	// Verificar se é administrador
-	if user.Role != "admin" {
+	if user.Role != models.RoleAdmin {
 		return errors.New("usuário não é administrador")
 	}
 
 	// Rebaixar para usuário comum
-	user.Role = "user"
+	_, err = s.AssignRole(ctx, adminID, userID, models.RoleUser)
+	return err
+}
+
+// GetRoles devolve os roles que podem ser atribuídos, com as permissões de cada um
+func (s *AdminService) GetRoles() []models.RoleDefinition {
+	roles := models.Roles()
+	definitions := make([]models.RoleDefinition, 0, len(roles))
+	for _, role := range roles {
+		definitions = append(definitions, models.RoleDefinition{
+			Role:        role,
+			Permissions: role.Permissions(),
+		})
+	}
+	return definitions
+}
+
+// GetUsersByRole lista os usuários com o role indicado
+func (s *AdminService) GetUsersByRole(ctx context.Context, role models.Role, page, limit int) (models.Users, int, error) {
+	if !role.Valid() {
+		return nil, 0, ErrInvalidRole
+	}
+
+	users, total, err := s.userRepo.ListByRole(ctx, string(role), int64(page), int64(limit))
+	if err != nil {
+		return nil, 0, err
+	}
 
-	return s.userRepo.Update(ctx, user)
+	// Remover informações sensíveis
+	for i := range users {
+		users[i].Password = ""
+	}
+	return users, int(total), nil
 }
 
 func (s *AdminService) GetBannedUsers(ctx context.Context, page, limit int) (models.Users, int, error) {
@@ -130,7 +185,7 @@ func (s *AdminService) GetBannedUsers(ctx context.Context, page, limit int) (mod
 func (s *AdminService) GetAdminUsers(ctx context.Context, page, limit int) (models.Users, int, error) {
 	var users = models.Users{}
 
-	users, total, err := s.userRepo.ListByRole(ctx, "admin", int64(page), int64(limit))
+	users, total, err := s.userRepo.ListByRole(ctx, string(models.RoleAdmin), int64(page), int64(limit))
 	if err != nil {
 		return models.Users{}, 0, err
 	}
@@ -143,73 +198,27 @@ func (s *AdminService) GetAdminUsers(ctx context.Context, page, limit int) (mode
 	return users, int(total), nil
 }
 
-func (s *AdminService) SendSMS(ctx context.Context, message string, contacts []string) (int, error) {
-	if len(contacts) == 0 {
-		return 0, errors.New("nenhum contato fornecido")
-	}
-
-	// Contador de mensagens enviadas
-	sentCount := 0
-
-	// Enviar SMS para cada contato
-	for _, contact := range contacts {
-		err := s.smsService.Send(contact, message)
-		if err == nil {
-			sentCount++
-		}
-		// Continuar mesmo se houver erro para tentar enviar para todos os contatos
-	}
-
-	if sentCount == 0 {
-		return 0, errors.New("falha ao enviar SMS para todos os contatos")
+// SendSMSToProvinces envia uma campanha imediata aos usuários das províncias indicadas
+func (s *AdminService) SendSMSToProvinces(ctx context.Context, adminID, message string, provinces []string) (models.SMSCampaign, error) {
+	if len(provinces) == 0 {
+		return models.SMSCampaign{}, errors.New("nenhuma província fornecida")
 	}
 
-	return sentCount, nil
+	return s.smsCampaigns.CreateCampaign(ctx, adminID, models.SMSCampaignRequest{
+		Message:  message,
+		Audience: models.SMSAudience{Provinces: provinces},
+	})
 }
 
-func (s *AdminService) SendSMSToProvince(ctx context.Context, message string, province string) (int, error) {
-	// Obter todos os usuários da província
-
-	var users = models.Users{}
-	users, _, err := s.userRepo.ListByProvince(ctx, province, 0, 0) // Sem paginação para obter todos
-	if err != nil {
-		return 0, err
-	}
-
-	if len(users) == 0 {
-		return 0, errors.New("nenhum usuário encontrado na província")
-	}
-
-	// Extrair contatos
-	contacts := make([]string, len(users))
-	for i, user := range users {
-		contacts[i] = user.Contact
-	}
-
-	// Enviar SMS para os contatos
-	return s.SendSMS(ctx, message, contacts)
+func (s *AdminService) SendSMSToProvince(ctx context.Context, adminID, message string, province string) (models.SMSCampaign, error) {
+	return s.SendSMSToProvinces(ctx, adminID, message, []string{province})
 }
 
-func (s *AdminService) SendSMSToAllUsers(ctx context.Context, message string) (int, error) {
-	// Obter todos os usuários
-	var users = models.Users{}
-	users, _, err := s.userRepo.List(ctx, 0, 0) // Sem paginação para obter todos
-	if err != nil {
-		return 0, err
-	}
-
-	if len(users) == 0 {
-		return 0, errors.New("nenhum usuário encontrado")
-	}
-
-	// Extrair contatos
-	contacts := make([]string, len(users))
-	for i, user := range users {
-		contacts[i] = user.Contact
-	}
-
-	// Enviar SMS para os contatos
-	return s.SendSMS(ctx, message, contacts)
+func (s *AdminService) SendSMSToAllUsers(ctx context.Context, adminID, message string) (models.SMSCampaign, error) {
+	// Público vazio abrange todos os usuários verificados
+	return s.smsCampaigns.CreateCampaign(ctx, adminID, models.SMSCampaignRequest{
+		Message: message,
+	})
 }
 
 func (s *AdminService) DeleteInappropriateContent(ctx context.Context, postID string, reason string) error {
@@ -225,7 +234,7 @@ func (s *AdminService) DeleteInappropriateContent(ctx context.Context, postID st
 	user, err = s.userRepo.FindByID(ctx, post.UserID)
 	if err == nil && user.Contact != "" {
 		message := "Sua postagem foi removida por violar as diretrizes da comunidade. Motivo: " + reason
-		s.smsService.Send(user.Contact, message)
+		s.smsOutbox.Enqueue(ctx, user.Contact, message)
 	}
 
 	// Excluir todos os comentários da postagem
@@ -272,7 +281,7 @@ func (s *AdminService) GetSystemStats(ctx context.Context) (map[string]interface
 	}
 
 	// Usuários administradores
-	adminUsers, _, err := s.userRepo.ListByRole(ctx, "admin", 0, 0)
+	adminUsers, _, err := s.userRepo.ListByRole(ctx, string(models.RoleAdmin), 0, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -289,14 +298,37 @@ func (s *AdminService) GetSystemStats(ctx context.Context) (map[string]interface
 	return stats, nil
 }
 
-func (s *AdminService) GetAdminLogs(page, limit int, adminID string) (map[string]string, error) {
-	return map[string]string{}, nil
+// GetAdminLogs devolve o histórico das ações dos administradores, das mais recentes para as mais antigas
+func (s *AdminService) GetAdminLogs(ctx context.Context, filter models.AuditFilter, page, limit int) (models.AuditEntries, int, error) {
+	return s.audit.List(ctx, filter, page, limit)
+}
+
+// ExportAdminLogs escreve em w o histórico das ações dos administradores em CSV
+func (s *AdminService) ExportAdminLogs(ctx context.Context, filter models.AuditFilter, w io.Writer) error {
+	return s.audit.ExportCSV(ctx, filter, w)
 }
 
-func (s *AdminService) DeleteUserAccount(userID string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
-	defer func() {
-		cancel()
-	}()
-	return s.userRepo.Delete(ctx, userID)
+// DeleteUserAccount exclui a conta do usuário e regista a exclusão no histórico
+func (s *AdminService) DeleteUserAccount(ctx context.Context, adminID, userID, reason string) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.ID == "" {
+		return ErrDeleteUserNotFound
+	}
+
+	if err := s.userRepo.Delete(ctx, userID); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, models.AuditEntry{
+		AdminID:    adminID,
+		Action:     models.AuditUserDelete,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+		Reason:     reason,
+		Before:     snapshot(user),
+	})
+	return nil
 }
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/anamalala/internal/models"
+	"github.com/anamalala/internal/repositories/interfaces"
+	"github.com/anamalala/pkg/logger"
+)
+
+// auditCSVHeader são as colunas da exportação do histórico em CSV
+var auditCSVHeader = []string{
+	"created_at", "admin_id", "admin_name", "action", "target_type", "target_id", "reason", "before", "after",
+}
+
+// AuditService mantém o histórico das ações dos administradores: quem fez o quê, a quem,
+// quando, com que motivo e o estado do alvo antes e depois. O histórico só recebe
+// entradas novas; nada é alterado nem apagado.
+type AuditService struct {
+	auditRepo interfaces.AuditLogRepository
+	userRepo  interfaces.UserRepository
+	logger    *logger.Logger
+}
+
+func NewAuditService(
+	auditRepo interfaces.AuditLogRepository,
+	userRepo interfaces.UserRepository,
+	logger *logger.Logger,
+) AuditService {
+	return AuditService{
+		auditRepo: auditRepo,
+		userRepo:  userRepo,
+		logger:    logger,
+	}
+}
+
+// Record junta uma ação ao histórico. A ação já foi feita, por isso uma falha é apenas
+// registada no log. Ações automáticas, sem administrador, não entram no histórico.
+func (s *AuditService) Record(ctx context.Context, entry models.AuditEntry) {
+	if entry.AdminID == "" {
+		return
+	}
+
+	if admin, err := s.userRepo.FindByID(ctx, entry.AdminID); err == nil {
+		entry.AdminName = admin.Name
+	}
+
+	// O registo não depende do pedido que fez a ação ter terminado
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+
+	if _, err := s.auditRepo.Append(ctx, entry); err != nil {
+		s.logger.Error("audit_record_failed", "admin_id", entry.AdminID, "action", string(entry.Action),
+			"target_id", entry.TargetID, "error", err.Error())
+	}
+}
+
+// List devolve as entradas do histórico que correspondem ao filtro, das mais recentes para as mais antigas
+func (s *AuditService) List(ctx context.Context, filter models.AuditFilter, page, limit int) (models.AuditEntries, int, error) {
+	entries, total, err := s.auditRepo.List(ctx, filter, int64(page), int64(limit))
+	if err != nil {
+		return nil, 0, err
+	}
+	return entries, int(total), nil
+}
+
+// ExportCSV escreve em w as entradas do histórico que correspondem ao filtro, em CSV.
+// Os estados antes e depois da ação ficam em JSON. Os campos escritos pelos usuários
+// são escapados para não serem executados como fórmulas numa folha de cálculo.
+func (s *AuditService) ExportCSV(ctx context.Context, filter models.AuditFilter, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(auditCSVHeader); err != nil {
+		return err
+	}
+
+	err := s.auditRepo.Each(ctx, filter, func(entry models.AuditEntry) error {
+		return writer.Write([]string{
+			entry.CreatedAt.UTC().Format(time.RFC3339),
+			csvCell(entry.AdminID),
+			csvCell(entry.AdminName),
+			string(entry.Action),
+			csvCell(entry.TargetType),
+			csvCell(entry.TargetID),
+			csvCell(entry.Reason),
+			snapshotJSON(entry.Before),
+			snapshotJSON(entry.After),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvCell escapa um campo que uma folha de cálculo leria como fórmula, começando-o por um apóstrofo
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// snapshot copia o estado de um alvo para o histórico, com os mesmos campos que a API devolve.
+// Os campos escondidos da API, como a palavra-passe, também ficam fora do histórico.
+func snapshot(v any) map[string]any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+	return fields
+}
+
+// snapshotJSON escreve um estado do histórico numa coluna do CSV
+func snapshotJSON(fields map[string]any) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"testing"
+
+	"github.com/anamalala/internal/models"
+	"github.com/anamalala/internal/repositories/interfaces"
+)
+
+// auditRepoStub devolve sempre as mesmas entradas do histórico
+type auditRepoStub struct {
+	interfaces.AuditLogRepository
+	entries models.AuditEntries
+}
+
+func (r auditRepoStub) Each(ctx context.Context, filter models.AuditFilter, fn func(models.AuditEntry) error) error {
+	for _, entry := range r.entries {
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestExportCSVEscapesFormulas(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"plain text", "spam repetido", "spam repetido"},
+		{"empty", "", ""},
+		{"equals", "=HYPERLINK(\"http://x\")", "'=HYPERLINK(\"http://x\")"},
+		{"plus", "+258841234567", "'+258841234567"},
+		{"minus", "-1+1", "'-1+1"},
+		{"at", "@SUM(A1)", "'@SUM(A1)"},
+		{"tab", "\t=1", "'\t=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := auditRepoStub{entries: models.AuditEntries{
+				{AdminID: "admin-1", AdminName: tt.value, TargetID: tt.value, Reason: tt.value},
+			}}
+			service := NewAuditService(repo, nil, nil)
+
+			var out bytes.Buffer
+			if err := service.ExportCSV(context.Background(), models.AuditFilter{}, &out); err != nil {
+				t.Fatalf("ExportCSV: %v", err)
+			}
+
+			records, err := csv.NewReader(&out).ReadAll()
+			if err != nil {
+				t.Fatalf("ReadAll: %v", err)
+			}
+			if len(records) != 2 {
+				t.Fatalf("expected header and 1 row, got %d records", len(records))
+			}
+			row := records[1]
+			for _, column := range []int{2, 5, 6} {
+				if row[column] != tt.want {
+					t.Errorf("expected column %s to be %q, got %q", auditCSVHeader[column], tt.want, row[column])
+				}
+			}
+		})
+	}
+}
@@ -9,126 +9,277 @@ import (
 	"github.com/anamalala/internal/models"
 	"github.com/anamalala/internal/repositories/interfaces"
 	"github.com/anamalala/internal/utils"
+	"github.com/anamalala/pkg/logger"
 )
 
+var ErrContactNotVerified = errors.New("contacto ainda não verificado")
+
+// ErrAccountSuspended indica uma conta desativada, por exemplo por uma suspensão em vigor
+var ErrAccountSuspended = errors.New("conta suspensa")
+
 type AuthService struct {
-	userRepo  interfaces.UserRepository
-	tokenUtil utils.TokenUtil
-	//sms *sms.Service
+	userRepo            interfaces.UserRepository
+	sessionRepo         interfaces.SessionRepository
+	tokenUtil           utils.TokenUtil
+	verificationService VerificationService
+	logger              *logger.Logger
 }
 
-func (s AuthService) VerifyResetPasswordCode(ctx context.Context, contact string, token string) bool {
+// VerifyResetPasswordCode confirma o código de redefinição sem o consumir
+func (s AuthService) VerifyResetPasswordCode(ctx context.Context, contact string, token string) error {
+	return s.verificationService.CheckCode(ctx, contact, models.VerificationPurposePasswordReset, token)
+}
 
-	user, err := s.userRepo.FindByContact(ctx, contact)
-	if err != nil {
-		return false
+func NewAuthService(
+	userRepo interfaces.UserRepository,
+	sessionRepo interfaces.SessionRepository,
+	tokenUtil utils.TokenUtil,
+	verificationService VerificationService,
+	logger *logger.Logger,
+) AuthService {
+	return AuthService{
+		userRepo:            userRepo,
+		sessionRepo:         sessionRepo,
+		tokenUtil:           tokenUtil,
+		verificationService: verificationService,
+		logger:              logger,
 	}
+}
 
-	// Verificar se o código existe e está válido
-	if user.ResetCode != token {
-		return false
+// Register cria uma conta pendente e envia um código de verificação por SMS.
+// A conta só fica utilizável depois de VerifyContact.
+func (s *AuthService) Register(ctx context.Context, user models.User) (models.User, error) {
+	// Hash da senha
+	hashedPassword, err := utils.HashPassword(user.Password)
+	if err != nil {
+		return models.User{}, err
 	}
 
-	// Verificar se o código não expirou
-	if time.Now().After(user.ResetCodeExpiry) {
-		return false
+	// Verificar se o usuário já existe
+	existing, err := s.userRepo.FindByContact(ctx, user.Contact)
+	if err == nil {
+		if !existing.PendingVerification {
+			return models.User{}, errors.New("user esxists")
+		}
+		// Registo anterior ainda por verificar: atualizar os dados e reenviar o código
+		existing.Name = user.Name
+		existing.Province = user.Province
+		existing.Password = hashedPassword
+		err = s.userRepo.Update(ctx, existing)
+		if err != nil {
+			return models.User{}, err
+		}
+		user = existing
+	} else {
+		user.Password = hashedPassword
+		user.Role = "user" // Por padrão, todos os novos registros são usuários normais
+		user.PendingVerification = true
+		// Salvar usuário
+		err = s.userRepo.Create(ctx, user)
+		if err != nil {
+			return models.User{}, err
+		}
 	}
 
-	return true
-
-}
-
-func NewAuthService(userRepo interfaces.UserRepository, tokenUtil utils.TokenUtil) AuthService {
-	return AuthService{
-		userRepo:  userRepo,
-		tokenUtil: tokenUtil,
+	// Enviar código de verificação
+	err = s.verificationService.SendCode(ctx, user.Contact, models.VerificationPurposeContact)
+	if err != nil {
+		return models.User{}, err
 	}
+	return user, nil
 }
 
-func (s *AuthService) Register(ctx context.Context, user models.User) (models.User, error) {
-	// Verificar se o usuário já existe
-	_, err := s.userRepo.FindByContact(ctx, user.Contact)
-	if err == nil {
-		return models.User{}, errors.New("user esxists")
+// VerifyContact valida o código enviado no registo e ativa a conta
+func (s *AuthService) VerifyContact(ctx context.Context, contact, code string) (models.User, error) {
+	user, err := s.userRepo.FindByContact(ctx, contact)
+	if err != nil {
+		return models.User{}, ErrCodeInvalid
 	}
-	// Hash da senha
-	hashedPassword, err := utils.HashPassword(user.Password)
+	if !user.PendingVerification {
+		return models.User{}, errors.New("contacto já verificado")
+	}
+
+	err = s.verificationService.VerifyCode(ctx, contact, models.VerificationPurposeContact, code)
 	if err != nil {
 		return models.User{}, err
 	}
-	user.Password = hashedPassword
 
-	user.Role = "user" // Por padrão, todos os novos registros são usuários normais
-	// Salvar usuário
-	err = s.userRepo.Create(ctx, user)
+	user.PendingVerification = false
+	user.VerifiedAt = time.Now()
+	err = s.userRepo.Update(ctx, user)
 	if err != nil {
 		return models.User{}, err
 	}
+
+	user.Password = ""
 	return user, nil
 }
 
-func (s *AuthService) Login(ctx context.Context, contact, password string) (models.User, string, error) {
+// ResendVerificationCode envia um novo código para uma conta pendente
+func (s *AuthService) ResendVerificationCode(ctx context.Context, contact string) error {
+	user, err := s.userRepo.FindByContact(ctx, contact)
+	if err != nil || !user.PendingVerification {
+		// Não revelar se o contacto existe
+		return nil
+	}
+
+	return s.verificationService.SendCode(ctx, contact, models.VerificationPurposeContact)
+}
+
+func (s *AuthService) Login(ctx context.Context, contact, password, device, ip string) (models.User, models.TokenPair, error) {
 	// Buscar usuário pelo contacto
 	user, err := s.userRepo.FindByContact(ctx, contact)
 	if err != nil {
-		return models.User{}, "", errors.New("credenciais inválidas")
+		return models.User{}, models.TokenPair{}, errors.New("credenciais inválidas")
 	}
 
 	// Verificar senha
 	if !utils.CheckPasswordHash(password, user.Password) {
-		return models.User{}, "", errors.New("credenciais inválidas")
+		return models.User{}, models.TokenPair{}, errors.New("credenciais inválidas")
+	}
+
+	// Contas pendentes precisam de verificar o contacto primeiro
+	if user.PendingVerification {
+		return models.User{}, models.TokenPair{}, ErrContactNotVerified
+	}
+
+	// Contas suspensas não podem iniciar sessão até a suspensão ser levantada
+	if !user.Active {
+		return models.User{}, models.TokenPair{}, ErrAccountSuspended
 	}
 
-	// Gerar token JWT
-	token, err := s.tokenUtil.GenerateToken(user.ID, string(user.Role))
+	// Criar sessão para o dispositivo
+	session, err := s.sessionRepo.Create(ctx, models.Session{
+		UserID:    user.ID,
+		Device:    device,
+		IP:        ip,
+		ExpiresAt: time.Now().Add(s.tokenUtil.RefreshExpiresIn()),
+	})
 	if err != nil {
-		return models.User{}, "", err
+		return models.User{}, models.TokenPair{}, err
+	}
+
+	// Gerar tokens JWT
+	tokens, refreshHash, expiresAt, err := s.issueTokens(user, session.ID)
+	if err != nil {
+		return models.User{}, models.TokenPair{}, err
+	}
+	rotated, err := s.sessionRepo.Rotate(ctx, session.ID, "", refreshHash, ip, expiresAt)
+	if err != nil {
+		return models.User{}, models.TokenPair{}, err
+	}
+	if !rotated {
+		return models.User{}, models.TokenPair{}, errors.New("falha ao iniciar sessão")
 	}
-	user.IsLoggedIn = true
+
+	user.LastLoginAt = time.Now()
 	er := s.userRepo.Update(ctx, user)
 	user.Password = ""
 	if er != nil {
-		return models.User{}, "", err
+		return models.User{}, models.TokenPair{}, er
 	}
-	return user, token, nil
+	return user, tokens, nil
 }
 
-func (s *AuthService) Logout(ctx context.Context, contact string) error {
-	// Buscar usuário pelo contacto
-	user, err := s.userRepo.FindByContact(ctx, contact)
+// RefreshToken troca um token de renovação válido por um novo par de tokens.
+// O token apresentado deixa de ser válido; se for reutilizado, a sessão inteira é revogada.
+func (s *AuthService) RefreshToken(ctx context.Context, refreshToken, ip string) (models.TokenPair, error) {
+	claims, err := s.tokenUtil.ValidateRefreshToken(refreshToken)
 	if err != nil {
-		return errors.New("credenciais inválidas: ")
+		return models.TokenPair{}, errors.New("token de renovação inválido")
 	}
-	user.IsLoggedIn = false
-	er := s.userRepo.Update(ctx, user)
-	if er != nil {
-		return er
+
+	session, err := s.sessionRepo.FindByID(ctx, claims.SessionID)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+	if session.ID == "" || session.Revoked || session.UserID != claims.UserID {
+		return models.TokenPair{}, errors.New("sessão inválida")
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return models.TokenPair{}, errors.New("sessão expirada")
 	}
-	return nil
-}
 
-func (s *AuthService) ResetPasswordRequest(ctx context.Context, contact string) error {
-	// Verificar se o usuário existe
-	user, err := s.userRepo.FindByContact(ctx, contact)
+	currentHash := utils.HashToken(refreshToken)
+	if session.RefreshTokenHash != currentHash {
+		// Token já rotacionado: possível roubo, revogar toda a sessão
+		if err := s.sessionRepo.Revoke(ctx, session.ID); err != nil {
+			return models.TokenPair{}, err
+		}
+		return models.TokenPair{}, errors.New("token de renovação reutilizado, sessão revogada")
+	}
+
+	user, err := s.userRepo.FindByID(ctx, session.UserID)
 	if err != nil {
-		return errors.New("usuário não encontrado")
+		return models.TokenPair{}, err
+	}
+	if user.ID == "" || !user.Active {
+		return models.TokenPair{}, errors.New("usuário inválido")
 	}
 
-	// Gerar código de recuperação (6 dígitos)
-	resetCode := utils.GenerateResetCode()
+	tokens, refreshHash, expiresAt, err := s.issueTokens(user, session.ID)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
 
-	// Armazenar código no usuário
-	user.ResetCode = resetCode
-	user.ResetCodeExpiry = time.Now().Add(15 * time.Minute) // Expira em 15 minutos
+	rotated, err := s.sessionRepo.Rotate(ctx, session.ID, currentHash, refreshHash, ip, expiresAt)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+	if !rotated {
+		// Outro pedido usou o mesmo token em simultâneo
+		if err := s.sessionRepo.Revoke(ctx, session.ID); err != nil {
+			return models.TokenPair{}, err
+		}
+		return models.TokenPair{}, errors.New("token de renovação reutilizado, sessão revogada")
+	}
 
-	// Atualizar usuário
-	err = s.userRepo.Update(ctx, user)
+	return tokens, nil
+}
+
+// issueTokens gera o token de acesso e o token de renovação de uma sessão
+func (s *AuthService) issueTokens(user models.User, sessionID string) (models.TokenPair, string, time.Time, error) {
+	accessToken, err := s.tokenUtil.GenerateToken(user.ID, string(user.Role), sessionID)
 	if err != nil {
-		return err
+		return models.TokenPair{}, "", time.Time{}, err
+	}
+
+	refreshToken, expiresAt, err := s.tokenUtil.GenerateRefreshToken(user.ID, sessionID)
+	if err != nil {
+		return models.TokenPair{}, "", time.Time{}, err
+	}
+
+	tokens := models.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.tokenUtil.ExpiresIn().Seconds()),
+	}
+	return tokens, utils.HashToken(refreshToken), expiresAt, nil
+}
+
+// Logout termina apenas a sessão do dispositivo atual
+func (s *AuthService) Logout(ctx context.Context, sessionID string) error {
+	if sessionID == "" {
+		return errors.New("sessão inválida")
+	}
+	return s.sessionRepo.Revoke(ctx, sessionID)
+}
+
+// ResetPasswordRequest envia um código de redefinição por SMS.
+// Nunca devolve erro, nem quando o contacto não existe, está bloqueado ou o envio falha,
+// para não revelar quais números estão registados. As falhas ficam apenas no log.
+func (s *AuthService) ResetPasswordRequest(ctx context.Context, contact string) error {
+	// Verificar se o usuário existe
+	user, err := s.userRepo.FindByContact(ctx, contact)
+	if err != nil || user.PendingVerification {
+		return nil
 	}
 
-	// Enviar SMS com código de recuperação (mock)
-	// Na implementação real, integrar com serviço de SMS
+	// Gerar e enviar código de recuperação (6 dígitos)
+	err = s.verificationService.SendCode(ctx, contact, models.VerificationPurposePasswordReset)
+	if err != nil && !errors.Is(err, ErrCodeResendCooldown) && !errors.Is(err, ErrCodeLocked) {
+		s.logger.Error("password_reset_code_failed", "user_id", user.ID, "error", err.Error())
+	}
 
 	return nil
 }
@@ -137,17 +288,13 @@ func (s *AuthService) ResetPassword(ctx context.Context, contact, resetCode, new
 	// Buscar usuário
 	user, err := s.userRepo.FindByContact(ctx, contact)
 	if err != nil {
-		return errors.New("usuário não encontrado")
-	}
-
-	// Verificar se o código existe e está válido
-	if user.ResetCode != resetCode {
-		return errors.New("código de recuperação inválido")
+		return ErrCodeInvalid
 	}
 
-	// Verificar se o código não expirou
-	if time.Now().After(user.ResetCodeExpiry) {
-		return errors.New("código de recuperação expirado")
+	// Verificar e consumir o código de recuperação
+	err = s.verificationService.VerifyCode(ctx, contact, models.VerificationPurposePasswordReset, resetCode)
+	if err != nil {
+		return err
 	}
 
 	// Hash da nova senha
@@ -156,12 +303,13 @@ func (s *AuthService) ResetPassword(ctx context.Context, contact, resetCode, new
 		return err
 	}
 
-	// Atualizar senha e limpar códigos de recuperação
-	user.Password = hashedPassword
-	user.ResetCode = ""
-	user.ResetCodeExpiry = time.Time{}
-	user.UpdatedAt = time.Now()
+	// Atualizar senha
+	err = s.userRepo.UpdatePassword(ctx, user.ID, hashedPassword)
+	if err != nil {
+		return err
+	}
 
-	// Salvar usuário
-	return s.userRepo.Update(ctx, user)
+	// Terminar as sessões existentes, a senha antiga pode ter sido comprometida
+	_, err = s.sessionRepo.RevokeAllByUserID(ctx, user.ID)
+	return err
 }
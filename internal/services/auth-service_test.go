@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/anamalala/internal/models"
+	"github.com/anamalala/internal/repositories/interfaces"
+	"github.com/anamalala/internal/utils"
+)
+
+// sessionRepoStub guarda as sessões em memória
+type sessionRepoStub struct {
+	interfaces.SessionRepository
+	sessions map[string]models.Session
+}
+
+func (r *sessionRepoStub) FindByID(ctx context.Context, id string) (models.Session, error) {
+	return r.sessions[id], nil
+}
+
+func (r *sessionRepoStub) Rotate(ctx context.Context, id, oldTokenHash, newTokenHash, ip string, expiresAt time.Time) (bool, error) {
+	session, ok := r.sessions[id]
+	if !ok || session.Revoked || session.RefreshTokenHash != oldTokenHash {
+		return false, nil
+	}
+	session.RefreshTokenHash = newTokenHash
+	session.IP = ip
+	session.ExpiresAt = expiresAt
+	r.sessions[id] = session
+	return true, nil
+}
+
+func (r *sessionRepoStub) Revoke(ctx context.Context, id string) error {
+	session := r.sessions[id]
+	session.Revoked = true
+	r.sessions[id] = session
+	return nil
+}
+
+// userRepoStub devolve os usuários indicados pelo ID
+type userRepoStub struct {
+	interfaces.UserRepository
+	users map[string]models.User
+}
+
+func (r userRepoStub) FindByID(ctx context.Context, id string) (models.User, error) {
+	return r.users[id], nil
+}
+
+// newRefreshTestService cria um AuthService com a sessão indicada e devolve um token de renovação
+// dessa sessão emitido ao usuário
+func newRefreshTestService(t *testing.T, session models.Session, user models.User) (AuthService, *sessionRepoStub, string) {
+	t.Helper()
+
+	tokenUtil := utils.NewTokenUtil("secret", time.Hour, "refresh-secret", 24*time.Hour)
+	refreshToken, _, err := tokenUtil.GenerateRefreshToken(user.ID, session.ID)
+	if err != nil {
+		t.Fatalf("GenerateRefreshToken: %v", err)
+	}
+	session.RefreshTokenHash = utils.HashToken(refreshToken)
+
+	sessionRepo := &sessionRepoStub{sessions: map[string]models.Session{session.ID: session}}
+	userRepo := userRepoStub{users: map[string]models.User{user.ID: user}}
+	service := NewAuthService(userRepo, sessionRepo, tokenUtil, VerificationService{}, nil)
+	return service, sessionRepo, refreshToken
+}
+
+func TestRefreshTokenRotates(t *testing.T) {
+	session := models.Session{ID: "session-1", UserID: "user-1", ExpiresAt: time.Now().Add(time.Hour)}
+	user := models.User{ID: "user-1", Role: "user", Active: true}
+	service, sessionRepo, refreshToken := newRefreshTestService(t, session, user)
+
+	tokens, err := service.RefreshToken(context.Background(), refreshToken, "10.0.0.1")
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+	if tokens.AccessToken == "" || tokens.RefreshToken == "" {
+		t.Fatalf("expected a new token pair, got %+v", tokens)
+	}
+	if tokens.RefreshToken == refreshToken {
+		t.Errorf("expected the refresh token to rotate")
+	}
+
+	stored := sessionRepo.sessions["session-1"]
+	if stored.RefreshTokenHash != utils.HashToken(tokens.RefreshToken) {
+		t.Errorf("expected the session to keep the hash of the new refresh token")
+	}
+	if stored.IP != "10.0.0.1" {
+		t.Errorf("expected ip 10.0.0.1, got %q", stored.IP)
+	}
+
+	// O novo token continua a rodar
+	if _, err := service.RefreshToken(context.Background(), tokens.RefreshToken, "10.0.0.1"); err != nil {
+		t.Fatalf("RefreshToken with the rotated token: %v", err)
+	}
+}
+
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	session := models.Session{ID: "session-1", UserID: "user-1", ExpiresAt: time.Now().Add(time.Hour)}
+	user := models.User{ID: "user-1", Role: "user", Active: true}
+	service, sessionRepo, refreshToken := newRefreshTestService(t, session, user)
+
+	tokens, err := service.RefreshToken(context.Background(), refreshToken, "10.0.0.1")
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+
+	// Quem roubou o token antigo tenta usá-lo
+	if _, err := service.RefreshToken(context.Background(), refreshToken, "10.0.0.2"); err == nil {
+		t.Fatalf("expected the reused token to be refused")
+	}
+	if !sessionRepo.sessions["session-1"].Revoked {
+		t.Fatalf("expected the session to be revoked")
+	}
+
+	// A família inteira fica revogada, incluindo o token mais recente
+	if _, err := service.RefreshToken(context.Background(), tokens.RefreshToken, "10.0.0.1"); err == nil {
+		t.Errorf("expected the latest token of a revoked session to be refused")
+	}
+}
+
+func TestRefreshTokenRefused(t *testing.T) {
+	active := models.Session{ID: "session-1", UserID: "user-1", ExpiresAt: time.Now().Add(time.Hour)}
+	user := models.User{ID: "user-1", Role: "user", Active: true}
+
+	revoked := active
+	revoked.Revoked = true
+	expired := active
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	otherUser := active
+	otherUser.UserID = "user-2"
+	suspended := user
+	suspended.Active = false
+
+	tests := []struct {
+		name    string
+		session models.Session
+		user    models.User
+		token   func(refreshToken string) string
+	}{
+		{"revoked session", revoked, user, nil},
+		{"expired session", expired, user, nil},
+		{"session of another user", otherUser, user, nil},
+		{"suspended user", active, suspended, nil},
+		{"malformed token", active, user, func(string) string { return "not-a-token" }},
+		{"access token", active, user, func(string) string {
+			token, _ := utils.NewTokenUtil("secret", time.Hour, "refresh-secret", time.Hour).GenerateToken("user-1", "user", "session-1")
+			return token
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _, refreshToken := newRefreshTestService(t, tt.session, tt.user)
+			if tt.token != nil {
+				refreshToken = tt.token(refreshToken)
+			}
+
+			if _, err := service.RefreshToken(context.Background(), refreshToken, "10.0.0.1"); err == nil {
+				t.Errorf("expected the refresh to be refused")
+			}
+		})
+	}
+}
@@ -4,61 +4,102 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"sync"
+	"strings"
 	"time"
 
 	"slices"
 
 	"github.com/anamalala/internal/models"
 	"github.com/anamalala/internal/repositories/interfaces"
+	"github.com/anamalala/pkg/contentfilter"
 )
 
-type ChatroomService struct {
-	postRepo    interfaces.PostRepository
-	commentRepo interfaces.CommentRepository
-	userRepo    interfaces.UserRepository
-}
+var (
+	// ErrContentRequired indica uma postagem ou comentário sem conteúdo
+	ErrContentRequired = errors.New("conteúdo é obrigatório")
+	// ErrInvalidCursor indica um cursor de paginação que não foi emitido pela API
+	ErrInvalidCursor = errors.New("cursor inválido")
+	// ErrCommentNotFound indica um comentário que não existe ou foi excluído
+	ErrCommentNotFound = errors.New("comentário não encontrado")
+	// ErrPostNotFound indica uma postagem que não existe
+	ErrPostNotFound = errors.New("postagem não encontrada")
+	// ErrCommentDeleted indica um comentário removido que só permanece como marcador da conversa
+	ErrCommentDeleted = errors.New("comentário removido")
+)
 
-// CommentJob representa um job para buscar comentários de um post
-type CommentJob struct {
-	Post *models.Post
-}
+const (
+	// MaxThreadDepth é o número máximo de níveis de respostas devolvidos numa conversa
+	MaxThreadDepth = 10
+	// MaxThreadReplies é o número máximo de respostas devolvidas por comentário numa conversa
+	MaxThreadReplies = 50
+	// MaxThreadComments é o número máximo de respostas devolvidas numa conversa, somando todos os níveis
+	MaxThreadComments = 500
+)
 
-// CommentWorker representa um worker para processar jobs de comentários
-type CommentWorker struct {
-	ID   int
-	Jobs <-chan CommentJob
-	wg   *sync.WaitGroup
+type ChatroomService struct {
+	postRepo            interfaces.PostRepository
+	commentRepo         interfaces.CommentRepository
+	userRepo            interfaces.UserRepository
+	notificationService *NotificationService
+	mediaService        MediaService
+	contentFilter       *ContentFilterService
+	moderationRepo      interfaces.ModerationRepository
+	audit               AuditService
 }
 
-// NewCommentWorker cria um novo worker para buscar comentários
-func NewCommentWorker(id int, jobs <-chan CommentJob, wg *sync.WaitGroup) *CommentWorker {
-	return &CommentWorker{
-		ID:   id,
-		Jobs: jobs,
-		wg:   wg,
-	}
-}
+// Quantos comentários e respostas acompanham as postagens; o resto da conversa
+// é carregado por página em GetCommentsByPostID e GetReplies
+var (
+	feedCommentPreview = models.CommentPreview{Comments: 3, Replies: 2}
+	postCommentPreview = models.CommentPreview{Comments: 20, Replies: 3}
+)
 
 func NewChatroomService(
 	postRepo interfaces.PostRepository,
 	commentRepo interfaces.CommentRepository,
 	userRepo interfaces.UserRepository,
+	notificationService *NotificationService,
+	mediaService MediaService,
+	contentFilter *ContentFilterService,
+	moderationRepo interfaces.ModerationRepository,
+	audit AuditService,
 ) ChatroomService {
 	return ChatroomService{
-		postRepo:    postRepo,
-		commentRepo: commentRepo,
-		userRepo:    userRepo,
+		postRepo:            postRepo,
+		commentRepo:         commentRepo,
+		userRepo:            userRepo,
+		notificationService: notificationService,
+		mediaService:        mediaService,
+		contentFilter:       contentFilter,
+		moderationRepo:      moderationRepo,
+		audit:               audit,
 	}
 }
 
 func (s *ChatroomService) CreatePost(ctx context.Context, post models.Post) (models.Post, error) {
+	if strings.TrimSpace(post.Content) == "" && len(post.Attachments) == 0 {
+		return models.Post{}, ErrContentRequired
+	}
+	media, err := s.mediaService.ValidateAttachments(ctx, post.UserID, post.Attachments, nil)
+	if err != nil {
+		return models.Post{}, err
+	}
 	// Verificar se o autor existe
 	user, err := s.userRepo.FindByID(ctx, post.UserID)
 	if err != nil {
 		return models.Post{}, errors.New("autor não encontrado")
 	}
+	verdict, err := s.filterContent(ctx, post.UserID, post.Content)
+	if err != nil {
+		return models.Post{}, err
+	}
+	post.Content = verdict.Content
+	post.Hidden = verdict.Action == contentfilter.ActionHold
 	// Configurar campos da postagem
+	post.Type = models.PostTypeText
+	if len(media) > 0 {
+		post.Type = media[0].Kind
+	}
 	post.CreatedAt = time.Now()
 	post.UpdatedAt = time.Now()
 	post.Likes = 0
@@ -71,46 +112,145 @@ func (s *ChatroomService) CreatePost(ctx context.Context, post models.Post) (mod
 	if err != nil {
 		return models.Post{}, err
 	}
+	post.Media = media
+	s.recordContent(ctx, post.UserID, post.Content)
+
+	if post.Hidden {
+		err = s.holdForReview(ctx, models.ModerationItem{
+			ContentType: "post",
+			ContentID:   post.ID,
+			PostID:      post.ID,
+			AuthorID:    post.UserID,
+			Excerpt:     excerpt(post.Content),
+		}, verdict)
+		if err != nil {
+			return models.Post{}, err
+		}
+	}
 
 	return post, nil
 }
 
-func (s *ChatroomService) GetAllPosts(ctx context.Context, page, limit int) (models.Posts, int, error) {
-	posts, total, err := s.postRepo.List(ctx, int64(page), int64(limit))
+// GetAllPosts devolve uma página de postagens, das mais recentes para as mais antigas,
+// e o cursor da página seguinte (vazio na última página)
+func (s *ChatroomService) GetAllPosts(ctx context.Context, cursor string, limit int) (models.Posts, string, error) {
+	after, err := models.ParseCursor(cursor)
 	if err != nil {
-		return nil, 0, err
+		return nil, "", ErrInvalidCursor
 	}
-	posts = FetchPostsComments(posts, len(posts), s.commentRepo)
-	return posts, int(total), nil
 
+	// Pedir um a mais para saber se existe página seguinte
+	posts, err := s.postRepo.ListPageWithComments(ctx, after, int64(limit)+1, feedCommentPreview)
+	if err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(posts) > limit {
+		posts = posts[:limit]
+		last := posts[limit-1]
+		nextCursor = models.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+
+	if err := s.attachMedia(ctx, posts); err != nil {
+		return nil, "", err
+	}
+	return posts, nextCursor, nil
+}
+
+// CountRecentActivity conta as postagens e comentários criados desde o momento indicado
+func (s *ChatroomService) CountRecentActivity(ctx context.Context, since time.Time) (int64, error) {
+	posts, err := s.postRepo.CountCreatedSince(ctx, since)
+	if err != nil {
+		return 0, err
+	}
+	comments, err := s.commentRepo.CountCreatedSince(ctx, since)
+	if err != nil {
+		return 0, err
+	}
+	return posts + comments, nil
 }
 
 func (s *ChatroomService) GetPostByID(ctx context.Context, id string) (models.Post, error) {
-	post, err := s.postRepo.FindByID(ctx, id)
+	return s.findPostWithComments(ctx, id)
+}
+
+// IsPostVisible indica se a postagem existe e não está oculta para moderação
+func (s *ChatroomService) IsPostVisible(ctx context.Context, id string) (bool, error) {
+	post, err := s.postRepo.FindVisibleByID(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	return post.ID != "", nil
+}
+
+// findPostWithComments carrega a postagem com a pré-visualização dos comentários e os anexos
+func (s *ChatroomService) findPostWithComments(ctx context.Context, id string) (models.Post, error) {
+	post, err := s.postRepo.FindByIDWithComments(ctx, id, postCommentPreview)
 	if err != nil {
 		return models.Post{}, err
 	}
-	posts := []models.Post{post}
-	posts = FetchPostsComments(posts, 1, s.commentRepo)
 
-	for _, p := range posts {
-		if p.ID == post.ID {
-			return p, nil
+	posts := models.Posts{post}
+	if err := s.attachMedia(ctx, posts); err != nil {
+		return models.Post{}, err
+	}
+	return posts[0], nil
+}
+
+// attachMedia preenche os anexos das postagens com uma única consulta
+func (s *ChatroomService) attachMedia(ctx context.Context, posts models.Posts) error {
+	var ids []string
+	for _, post := range posts {
+		ids = append(ids, post.Attachments...)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	media, err := s.mediaService.Resolve(ctx, ids)
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]models.Media, len(media))
+	for _, m := range media {
+		byID[m.ID] = m
+	}
+
+	for i := range posts {
+		posts[i].Media = nil
+		for _, id := range posts[i].Attachments {
+			if m, ok := byID[id]; ok {
+				posts[i].Media = append(posts[i].Media, m)
+			}
 		}
 	}
-	return post, nil
+	return nil
 }
 
 func (s *ChatroomService) CommentPost(ctx context.Context, referenceID string, comment models.Comment, authorID string) (models.Comment, error) {
-	_, err := s.postRepo.FindByID(ctx, referenceID)
+	if strings.TrimSpace(comment.Content) == "" {
+		return models.Comment{}, ErrContentRequired
+	}
+	post, err := s.postRepo.FindVisibleByID(ctx, referenceID)
 	if err != nil {
-		return models.Comment{}, errors.New("postagem não encontrada")
+		return models.Comment{}, err
+	}
+	if post.ID == "" {
+		return models.Comment{}, ErrPostNotFound
 	}
 	user, err := s.userRepo.FindByID(ctx, authorID)
 	if err != nil {
 		return models.Comment{}, errors.New("usuario que comenta não encontrada")
 	}
+	verdict, err := s.filterContent(ctx, authorID, comment.Content)
+	if err != nil {
+		return models.Comment{}, err
+	}
+	comment.Content = verdict.Content
+	comment.Hidden = verdict.Action == contentfilter.ActionHold
 	comment.ReferenceID = referenceID
+	comment.PostID = referenceID
 	comment.UserID = authorID
 	comment.CreatedAt = time.Now()
 	comment.Likes = 0
@@ -119,62 +259,169 @@ func (s *ChatroomService) CommentPost(ctx context.Context, referenceID string, c
 		Name: user.Name,
 		ID:   user.ID,
 	}
-	err = s.commentRepo.Create(ctx, comment)
+	comment, err = s.commentRepo.Create(ctx, comment)
 	if err != nil {
 		return models.Comment{}, err
 	}
+	s.recordContent(ctx, authorID, comment.Content)
+	if comment.Hidden {
+		return comment, s.holdCommentForReview(ctx, comment, verdict)
+	}
+
+	// Uma falha ao notificar não invalida o comentário
+	_ = s.notificationService.NotifyNewComment(ctx, post.UserID, post.ID, authorID)
+
 	return comment, nil
 }
 
 func (s *ChatroomService) ReplayComment(ctx context.Context, referenceID string, comment models.Comment, authorID string) (models.Comment, error) {
-	_, err := s.commentRepo.FindByID(ctx, referenceID)
+	if strings.TrimSpace(comment.Content) == "" {
+		return models.Comment{}, ErrContentRequired
+	}
+	parent, err := s.commentRepo.FindVisibleByID(ctx, referenceID)
 	if err != nil {
 		return models.Comment{}, errors.New("comentario não encontrado")
 	}
+	if parent.ID == "" {
+		return models.Comment{}, ErrCommentNotFound
+	}
+	if parent.Deleted {
+		return models.Comment{}, ErrCommentDeleted
+	}
 	user, err := s.userRepo.FindByID(ctx, authorID)
 	if err != nil {
 		return models.Comment{}, errors.New("usuario que comenta não encontrada")
 	}
+	verdict, err := s.filterContent(ctx, authorID, comment.Content)
+	if err != nil {
+		return models.Comment{}, err
+	}
+	comment.Content = verdict.Content
+	comment.Hidden = verdict.Action == contentfilter.ActionHold
 	comment.ReferenceID = referenceID
+	comment.PostID = parent.PostID
+	if comment.PostID == "" && parent.Reference == "post" {
+		comment.PostID = parent.ReferenceID
+	}
 	comment.UserID = authorID
 	comment.CreatedAt = time.Now()
 	comment.Likes = 0
 	comment.Reference = "comment"
+	comment.Ancestors = threadAncestors(parent)
+	comment.Depth = len(comment.Ancestors)
 	comment.Author = models.Author{
 		Name: user.Name,
 		ID:   user.ID,
 	}
 	comment.Comments = []models.Comment{}
-	err = s.commentRepo.Create(ctx, comment)
+	comment, err = s.commentRepo.Create(ctx, comment)
 	if err != nil {
 		return models.Comment{}, err
 	}
+	s.recordContent(ctx, authorID, comment.Content)
+	if comment.Hidden {
+		return comment, s.holdCommentForReview(ctx, comment, verdict)
+	}
+
+	// Uma falha ao notificar não invalida a resposta
+	_ = s.notificationService.NotifyNewReply(ctx, parent.UserID, parent.ID, authorID)
+
 	return comment, nil
 }
 
-func (s *ChatroomService) GetCommentsByPostID(ctx context.Context, postID string, page, limit int) (models.Comments, int, error) {
-	comments, total, err := s.commentRepo.ListByPostID(ctx, postID, int64(page), int64(limit))
+// filterContent aplica o filtro de conteúdo ao texto enviado pelo usuário.
+// O conteúdo rejeitado devolve ErrContentRejected com os motivos.
+func (s *ChatroomService) filterContent(ctx context.Context, userID, content string) (contentfilter.Verdict, error) {
+	verdict, err := s.contentFilter.Check(ctx, userID, content)
+	if err != nil {
+		return contentfilter.Verdict{}, err
+	}
+	if verdict.Action == contentfilter.ActionReject {
+		return contentfilter.Verdict{}, fmt.Errorf("%w: %s", ErrContentRejected, verdict.Reasons())
+	}
+	return verdict, nil
+}
+
+// recordContent regista no filtro o conteúdo já guardado, para detetar se o usuário o repete.
+// Uma falha não invalida o conteúdo: no pior caso a próxima repetição não é detetada.
+func (s *ChatroomService) recordContent(ctx context.Context, userID, content string) {
+	_ = s.contentFilter.Record(ctx, userID, content)
+}
+
+// holdCommentForReview coloca na fila de moderação um comentário retido pelo filtro
+func (s *ChatroomService) holdCommentForReview(ctx context.Context, comment models.Comment, verdict contentfilter.Verdict) error {
+	return s.holdForReview(ctx, models.ModerationItem{
+		ContentType: "comment",
+		ContentID:   comment.ID,
+		PostID:      comment.PostID,
+		AuthorID:    comment.UserID,
+		Excerpt:     excerpt(comment.Content),
+	}, verdict)
+}
+
+// holdForReview coloca na fila de moderação o conteúdo que o filtro guardou oculto.
+// O item já fica marcado como oculto para que o moderador, ao aprovar, o volte a mostrar.
+func (s *ChatroomService) holdForReview(ctx context.Context, item models.ModerationItem, verdict contentfilter.Verdict) error {
+	item.Hidden = true
+	_, _, err := s.moderationRepo.AddReport(ctx, item, models.Report{
+		Reason:    models.ReportReasonContentFilter,
+		Details:   verdict.Reasons(),
+		CreatedAt: time.Now(),
+	})
+	return err
+}
+
+// GetCommentsByPostID devolve uma página de comentários da postagem, dos mais antigos para os mais recentes
+func (s *ChatroomService) GetCommentsByPostID(ctx context.Context, postID string, cursor string, limit int) (models.Comments, string, error) {
+	return s.listComments(ctx, "post", postID, cursor, limit)
+}
+
+// GetReplies devolve uma página de respostas a um comentário, das mais antigas para as mais recentes
+func (s *ChatroomService) GetReplies(ctx context.Context, commentID string, cursor string, limit int) (models.Comments, string, error) {
+	return s.listComments(ctx, "comment", commentID, cursor, limit)
+}
+
+// listComments pagina os comentários de uma referência e devolve o cursor da página seguinte
+func (s *ChatroomService) listComments(ctx context.Context, reference, referenceID string, cursor string, limit int) (models.Comments, string, error) {
+	after, err := models.ParseCursor(cursor)
 	if err != nil {
-		return nil, 0, err
+		return nil, "", ErrInvalidCursor
 	}
-	return comments, int(total), nil
+
+	comments, err := s.commentRepo.ListPageByReference(ctx, reference, referenceID, after, int64(limit)+1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(comments) > limit {
+		comments = comments[:limit]
+		last := comments[limit-1]
+		nextCursor = models.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+
+	return comments, nextCursor, nil
 }
 
 func (s *ChatroomService) LikePost(ctx context.Context, postID string, userID string) (models.Post, error) {
-	// Obter postagem
-	post, err := s.postRepo.FindByID(ctx, postID)
+	// Obter postagem; as postagens ocultas para moderação não podem ser curtidas
+	post, err := s.postRepo.FindVisibleByID(ctx, postID)
 	if err != nil {
 		return models.Post{}, err
 	}
+	if post.ID == "" {
+		return models.Post{}, ErrPostNotFound
+	}
 	// Verificar se usuário já curtiu
 	if slices.Contains(post.LikedUserId, userID) {
 		// Usuário já curtiu, remover curtida (toggle)
 		err = s.postRepo.RemoveLike(ctx, postID, userID)
-		post.Likes = post.Likes - 1
 	} else {
 		err = s.postRepo.AddLike(ctx, postID, userID)
-		post.Likes = post.Likes + 1
 
+		if err == nil {
+			_ = s.notificationService.NotifyNewLike(ctx, post.UserID, post.ID, userID, "post")
+		}
 	}
 	// Adicionar curtida
 
@@ -182,32 +429,34 @@ func (s *ChatroomService) LikePost(ctx context.Context, postID string, userID st
 		return models.Post{}, err
 	}
 
-	posts := FetchPostsComments([]models.Post{post}, 2, s.commentRepo)
-
-	for _, p  := range posts {
-		if p.ID == post.ID{
-			post = p
-		}
-	}
-
-	return post, nil
+	// Ler de novo para devolver a contagem de curtidas já atualizada
+	return s.findPostWithComments(ctx, postID)
 }
 
-func (s *ChatroomService) LikeComment(ctx context.Context, commentID string, userID string) error {
-	// Obter comentário
-	comment, err := s.commentRepo.FindByID(ctx, commentID)
+// LikeComment alterna a curtida do usuário no comentário e devolve o comentário curtido
+func (s *ChatroomService) LikeComment(ctx context.Context, commentID string, userID string) (models.Comment, error) {
+	// Obter comentário; os comentários ocultos para moderação não podem ser curtidos
+	comment, err := s.commentRepo.FindVisibleByID(ctx, commentID)
 	if err != nil {
-		return err
+		return models.Comment{}, err
+	}
+	if comment.ID == "" {
+		return models.Comment{}, ErrCommentNotFound
 	}
 
 	// Verificar se usuário já curtiu
 	if slices.Contains(comment.LikedUserId, userID) {
 		// Usuário já curtiu, remover curtida (toggle)
-		return s.commentRepo.RemoveLike(ctx, commentID, userID)
+		return comment, s.commentRepo.RemoveLike(ctx, commentID, userID)
 	}
 
 	// Adicionar curtida
-	return s.commentRepo.AddLike(ctx, commentID, userID)
+	if err := s.commentRepo.AddLike(ctx, commentID, userID); err != nil {
+		return models.Comment{}, err
+	}
+
+	_ = s.notificationService.NotifyNewLike(ctx, comment.UserID, comment.ID, userID, "comment")
+	return comment, nil
 }
 
 func (s *ChatroomService) DeletePost(ctx context.Context, postID string, userID string) error {
@@ -217,11 +466,11 @@ func (s *ChatroomService) DeletePost(ctx context.Context, postID string, userID
 		return err
 	}
 
-	// Verificar se usuário é o autor ou administrador
+	// Verificar se usuário é o autor ou pode excluir qualquer postagem
 	user, _ := s.userRepo.FindByID(ctx, userID)
 
-	// Verificar se o usuário é o autor da postagem ou um administrador
-	if post.UserID != user.ID && user.Role != "admin" {
+	// Verificar se o usuário é o autor da postagem ou tem a permissão post.delete.any
+	if post.UserID != user.ID && !user.Role.Can(models.PermPostDeleteAny) {
 		return errors.New("não autorizado a excluir esta postagem")
 	}
 	// Excluir todos os comentários da postagem
@@ -231,84 +480,157 @@ func (s *ChatroomService) DeletePost(ctx context.Context, postID string, userID
 	}
 
 	// Excluir postagem
-	return s.postRepo.Delete(ctx, postID)
+	if err := s.postRepo.Delete(ctx, postID); err != nil {
+		return err
+	}
+
+	// Só a exclusão por um membro da equipa entra no histórico
+	if post.ID != "" && post.UserID != userID {
+		s.audit.Record(ctx, models.AuditEntry{
+			AdminID:    userID,
+			Action:     models.AuditPostDelete,
+			TargetType: models.AuditTargetPost,
+			TargetID:   postID,
+			Before:     snapshot(post),
+		})
+	}
+	return nil
 }
 
-func (s *ChatroomService) DeleteComment(ctx context.Context, commentID string, userID string) error {
+// DeleteComment exclui um comentário. Um comentário com respostas fica como marcador,
+// sem conteúdo nem autor, para que as respostas não percam o contexto; o resultado indica esse caso.
+func (s *ChatroomService) DeleteComment(ctx context.Context, commentID string, userID string) (bool, error) {
 
 	// Obter comentário
 	comment, err := s.commentRepo.FindByID(ctx, commentID)
 	if err != nil {
-		return err
+		return false, err
+	}
+	if comment.ID == "" {
+		return false, ErrCommentNotFound
 	}
 
 	user, _ := s.userRepo.FindByID(ctx, userID)
 
-	// Verificar se usuário é o autor ou administrador
-	if user.Role != "admin" && comment.UserID != userID {
-		return errors.New("não autorizado a excluir este comentário")
+	// Verificar se usuário é o autor ou pode excluir qualquer comentário
+	if !user.Role.Can(models.PermCommentDeleteAny) && comment.UserID != userID {
+		return false, errors.New("não autorizado a excluir este comentário")
+	}
+
+	hasReplies, err := s.hasReplies(ctx, commentID)
+	if err != nil {
+		return false, err
+	}
+	if hasReplies {
+		if err := s.commentRepo.MarkDeleted(ctx, commentID); err != nil {
+			return false, err
+		}
+		s.auditCommentDeletion(ctx, comment, userID)
+		return true, nil
 	}
 
 	// Excluir comentário
-	return s.commentRepo.Delete(ctx, commentID)
+	if err := s.commentRepo.Delete(ctx, commentID); err != nil {
+		return false, err
+	}
+	s.auditCommentDeletion(ctx, comment, userID)
+
+	// Marcadores que ficaram sem respostas deixam de ser necessários
+	for i := len(comment.Ancestors) - 1; i >= 0; i-- {
+		ancestor, err := s.commentRepo.FindByID(ctx, comment.Ancestors[i])
+		if err != nil || !ancestor.Deleted {
+			break
+		}
+		if hasReplies, err := s.hasReplies(ctx, ancestor.ID); err != nil || hasReplies {
+			break
+		}
+		if err := s.commentRepo.Delete(ctx, ancestor.ID); err != nil {
+			break
+		}
+	}
+
+	return false, nil
 }
 
-func (s *ChatroomService) GetCommentID(ctx context.Context, id string) (models.Comment, error) {
-	comment, err := s.commentRepo.FindByID(ctx, id)
+// auditCommentDeletion regista no histórico a exclusão de um comentário por um membro da equipa
+func (s *ChatroomService) auditCommentDeletion(ctx context.Context, comment models.Comment, userID string) {
+	if comment.UserID == userID {
+		return
+	}
+	s.audit.Record(ctx, models.AuditEntry{
+		AdminID:    userID,
+		Action:     models.AuditCommentDelete,
+		TargetType: models.AuditTargetComment,
+		TargetID:   comment.ID,
+		Before:     snapshot(comment),
+	})
+}
+
+// GetThread devolve o comentário com as respostas aninhadas até depth níveis abaixo dele,
+// com no máximo limit respostas por comentário, das mais antigas para as mais recentes,
+// e no máximo MaxThreadComments respostas no total.
+// ReplyCount indica o total de respostas de cada comentário, para carregar as restantes por página.
+func (s *ChatroomService) GetThread(ctx context.Context, commentID string, depth, limit int) (models.Comment, error) {
+	root, err := s.commentRepo.FindVisibleByID(ctx, commentID)
 	if err != nil {
 		return models.Comment{}, err
 	}
-	return comment, nil
-}
+	if root.ID == "" {
+		return models.Comment{}, ErrCommentNotFound
+	}
 
-// Start inicia o worker para processar jobs de comentários
-func (w *CommentWorker) Start(commentRepo interfaces.CommentRepository) {
-	fmt.Println("comecando um worker para fazer job de de comentario")
+	depth = min(max(depth, 0), MaxThreadDepth)
+	limit = min(max(limit, 1), MaxThreadReplies)
 
-	go func() {
-		defer w.wg.Done()
-		for job := range w.Jobs {
-			w.fetchCommentsWithReplies(job.Post, commentRepo)
-		}
-	}()
+	children, err := s.commentRepo.ListThread(ctx, root, depth, int64(limit), MaxThreadComments)
+	if err != nil {
+		return models.Comment{}, err
+	}
+
+	return buildThread(root, children, depth), nil
 }
 
-// fetchCommentsWithReplies busca comentários para um post específico com todas as respostas
-func (w *CommentWorker) fetchCommentsWithReplies(post *models.Post, commentRepo interfaces.CommentRepository) {
-	fmt.Println("comecando a procurar  comentarios")
+// buildThread preenche as respostas de comment a partir das respostas agrupadas pelo comentário pai
+func buildThread(comment models.Comment, children map[string]models.CommentReplies, depth int) models.Comment {
+	replies := children[comment.ID]
+	comment.ReplyCount = replies.Total
+	comment.Comments = nil
+	if depth == 0 {
+		return comment
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
-	defer cancel()
-	comments, _, _ := commentRepo.ListByPostID(ctx, post.ID, 0, 0)
-	for i := range comments {
-		comments[i].Comments = w.fetchNestedReplies(comments[i].ID, commentRepo)
+	for _, reply := range replies.Replies {
+		comment.Comments = append(comment.Comments, buildThread(reply, children, depth-1))
 	}
-	post.Comments = comments
+	return comment
 }
 
-// fetchNestedReplies busca respostas aninhadas para um comentário
-func (w *CommentWorker) fetchNestedReplies(commentID string, commentRepo interfaces.CommentRepository) []models.Comment {
-	fmt.Println("comecando a procurar respostas de comentarios")
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
-	defer cancel()
-	replies, _, _ := commentRepo.ListByCommentID(ctx, commentID, 0, 0)
-	return replies
+// hasReplies indica se o comentário tem pelo menos uma resposta não excluída
+func (s *ChatroomService) hasReplies(ctx context.Context, commentID string) (bool, error) {
+	replies, err := s.commentRepo.ListPageByReference(ctx, "comment", commentID, models.Cursor{}, 1)
+	if err != nil {
+		return false, err
+	}
+	return len(replies) > 0, nil
 }
 
-// FetchPostsComments processa posts concorrentemente para buscar comentários com respostas
-func FetchPostsComments(posts []models.Post, numWorkers int, commentRepo interfaces.CommentRepository) []models.Post {
-	jobs := make(chan CommentJob, len(posts))
-	var wg sync.WaitGroup
-	// Cria workers
-	for w := 1; w <= numWorkers; w++ {
-		worker := NewCommentWorker(w, jobs, &wg)
-		wg.Add(1)
-		worker.Start(commentRepo)
+// threadAncestors devolve os ancestrais de uma resposta a parent.
+// Respostas anteriores aos ancestrais guardados só conhecem o comentário pai.
+func threadAncestors(parent models.Comment) []string {
+	ancestors := make([]string, 0, len(parent.Ancestors)+2)
+	if len(parent.Ancestors) == 0 && parent.Reference == "comment" {
+		ancestors = append(ancestors, parent.ReferenceID)
 	}
-	for i := range posts {
-		jobs <- CommentJob{Post: &posts[i]}
+	ancestors = append(ancestors, parent.Ancestors...)
+	return append(ancestors, parent.ID)
+}
+
+// GetCommentID devolve o comentário mesmo que esteja oculto para moderação,
+// para que o autor e a equipa o possam excluir
+func (s *ChatroomService) GetCommentID(ctx context.Context, id string) (models.Comment, error) {
+	comment, err := s.commentRepo.FindByID(ctx, id)
+	if err != nil {
+		return models.Comment{}, err
 	}
-	close(jobs)
-	wg.Wait()
-	return posts
+	return comment, nil
 }
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/anamalala/internal/models"
+	"github.com/anamalala/internal/repositories/interfaces"
+)
+
+// threadRepoStub devolve uma conversa já agrupada pelo comentário pai e regista os limites pedidos
+type threadRepoStub struct {
+	interfaces.CommentRepository
+	comments    map[string]models.Comment
+	children    map[string]models.CommentReplies
+	depth       int
+	limit       int64
+	maxComments int
+}
+
+func (r *threadRepoStub) FindVisibleByID(ctx context.Context, id string) (models.Comment, error) {
+	return r.comments[id], nil
+}
+
+func (r *threadRepoStub) ListThread(ctx context.Context, root models.Comment, maxDepth int, limit int64, maxComments int) (map[string]models.CommentReplies, error) {
+	r.depth, r.limit, r.maxComments = maxDepth, limit, maxComments
+	return r.children, nil
+}
+
+// threadShape descreve a árvore de respostas como "id(total: respostas...)" para comparar nos testes
+func threadShape(comment models.Comment) string {
+	shape := comment.ID
+	if comment.ReplyCount > 0 || len(comment.Comments) > 0 {
+		shape += "(" + strconv.Itoa(comment.ReplyCount) + ":"
+		for i, reply := range comment.Comments {
+			if i > 0 {
+				shape += " "
+			}
+			shape += threadShape(reply)
+		}
+		shape += ")"
+	}
+	return shape
+}
+
+func TestBuildThread(t *testing.T) {
+	// root
+	// ├── a
+	// │   ├── a1
+	// │   │   └── a1x
+	// │   └── a2
+	// └── b
+	children := map[string]models.CommentReplies{
+		"root": {Replies: models.Comments{{ID: "a"}, {ID: "b"}}, Total: 2},
+		"a":    {Replies: models.Comments{{ID: "a1"}, {ID: "a2"}}, Total: 2},
+		"a1":   {Replies: models.Comments{{ID: "a1x"}}, Total: 1},
+	}
+	// Só as primeiras respostas de cada nível são carregadas, mas o total conta todas
+	limited := map[string]models.CommentReplies{
+		"root": {Replies: models.Comments{{ID: "a"}}, Total: 5},
+		"a":    {Replies: models.Comments{{ID: "a1"}}, Total: 3},
+	}
+
+	tests := []struct {
+		name     string
+		children map[string]models.CommentReplies
+		depth    int
+		want     string
+	}{
+		{"no replies", map[string]models.CommentReplies{}, 3, "root"},
+		{"depth zero only counts replies", children, 0, "root(2:)"},
+		{"one level", children, 1, "root(2:a(2:) b)"},
+		{"two levels", children, 2, "root(2:a(2:a1(1:) a2) b)"},
+		{"whole thread", children, 10, "root(2:a(2:a1(1:a1x) a2) b)"},
+		{"limited replies keep the total", limited, 2, "root(5:a(3:a1))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := models.Comment{ID: "root", Comments: models.Comments{{ID: "stale"}}}
+
+			got := threadShape(buildThread(root, tt.children, tt.depth))
+			if got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGetThreadClampsDepthAndLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		depth     int
+		limit     int
+		wantDepth int
+		wantLimit int64
+	}{
+		{"within bounds", 3, 20, 3, 20},
+		{"negative", -1, -5, 0, 1},
+		{"above the maximum", MaxThreadDepth + 5, MaxThreadReplies + 5, MaxThreadDepth, MaxThreadReplies},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &threadRepoStub{comments: map[string]models.Comment{"root": {ID: "root"}}}
+			service := ChatroomService{commentRepo: repo}
+
+			if _, err := service.GetThread(context.Background(), "root", tt.depth, tt.limit); err != nil {
+				t.Fatalf("GetThread: %v", err)
+			}
+			if repo.depth != tt.wantDepth || repo.limit != tt.wantLimit {
+				t.Errorf("expected depth %d and limit %d, got %d and %d", tt.wantDepth, tt.wantLimit, repo.depth, repo.limit)
+			}
+			if repo.maxComments != MaxThreadComments {
+				t.Errorf("expected at most %d comments, got %d", MaxThreadComments, repo.maxComments)
+			}
+		})
+	}
+}
+
+func TestGetThreadHiddenComment(t *testing.T) {
+	// FindVisibleByID não devolve os comentários ocultos para moderação
+	service := ChatroomService{commentRepo: &threadRepoStub{comments: map[string]models.Comment{}}}
+
+	if _, err := service.GetThread(context.Background(), "hidden", 3, 10); !errors.Is(err, ErrCommentNotFound) {
+		t.Fatalf("expected ErrCommentNotFound, got %v", err)
+	}
+}
+
+func TestThreadAncestors(t *testing.T) {
+	tests := []struct {
+		name   string
+		parent models.Comment
+		want   []string
+	}{
+		{"top-level comment", models.Comment{ID: "c1", Reference: "post", ReferenceID: "p1"}, []string{"c1"}},
+		{"reply", models.Comment{ID: "r1", Reference: "comment", ReferenceID: "c1", Ancestors: []string{"c1"}}, []string{"c1", "r1"}},
+		{"deep reply", models.Comment{ID: "r2", Reference: "comment", ReferenceID: "r1", Ancestors: []string{"c1", "r1"}}, []string{"c1", "r1", "r2"}},
+		{"reply stored before ancestors", models.Comment{ID: "r1", Reference: "comment", ReferenceID: "c1"}, []string{"c1", "r1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := threadAncestors(tt.parent); !slices.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
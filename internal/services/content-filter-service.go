@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anamalala/internal/models"
+	"github.com/anamalala/internal/repositories/interfaces"
+	"github.com/anamalala/pkg/contentfilter"
+)
+
+var (
+	// ErrContentRejected indica uma postagem ou comentário recusado pelo filtro de conteúdo
+	ErrContentRejected = errors.New("conteúdo não permitido")
+	// ErrBlockedTermRequired indica um termo vazio
+	ErrBlockedTermRequired = errors.New("termo é obrigatório")
+	// ErrBlockedTermExists indica um termo que já está na lista de bloqueio
+	ErrBlockedTermExists = errors.New("o termo já está na lista de bloqueio")
+	// ErrBlockedTermNotFound indica um termo que não está na lista de bloqueio
+	ErrBlockedTermNotFound = errors.New("termo não encontrado")
+	// ErrInvalidFilterAction indica uma ação diferente de reject, mask ou hold
+	ErrInvalidFilterAction = errors.New("ação inválida: use reject, mask ou hold")
+)
+
+// ContentFilterService examina as postagens e comentários antes de serem guardados.
+// A lista de bloqueio é gerida pelos administradores e fica em memória, sendo lida de novo
+// depois de refresh ou logo após uma alteração nesta instância. As restantes regras
+// (links, telefones, mensagens repetidas) vêm da configuração.
+type ContentFilterService struct {
+	blockedTermRepo interfaces.BlockedTermRepository
+	pipeline        *contentfilter.Pipeline
+	refresh         time.Duration
+
+	mu        sync.Mutex
+	blocklist *contentfilter.Blocklist
+	loadedAt  time.Time
+}
+
+// NewContentFilterService cria o filtro com a lista de bloqueio seguida das regras indicadas
+func NewContentFilterService(blockedTermRepo interfaces.BlockedTermRepository, refresh time.Duration, rules ...contentfilter.Rule) *ContentFilterService {
+	s := &ContentFilterService{
+		blockedTermRepo: blockedTermRepo,
+		refresh:         refresh,
+	}
+	s.pipeline = contentfilter.NewPipeline(append([]contentfilter.Rule{contentfilter.NewBlocklistRule(s.loadBlocklist)}, rules...)...)
+	return s
+}
+
+// Check aplica o filtro ao conteúdo enviado pelo usuário
+func (s *ContentFilterService) Check(ctx context.Context, userID, content string) (contentfilter.Verdict, error) {
+	return s.pipeline.Run(ctx, userID, content)
+}
+
+// Record regista o conteúdo aceite pelo filtro depois de guardado, para a deteção de mensagens repetidas
+func (s *ContentFilterService) Record(ctx context.Context, userID, content string) error {
+	return s.pipeline.Record(ctx, userID, content)
+}
+
+// GetTerms devolve a lista de bloqueio
+func (s *ContentFilterService) GetTerms(ctx context.Context) (models.BlockedTerms, error) {
+	return s.blockedTermRepo.FindAll(ctx)
+}
+
+// AddTerm junta um termo à lista de bloqueio; sem ação indicada o conteúdo é rejeitado
+func (s *ContentFilterService) AddTerm(ctx context.Context, adminID string, creation models.BlockedTermCreation) (models.BlockedTerm, error) {
+	term := strings.TrimSpace(creation.Term)
+	normalized := contentfilter.NormalizeText(term)
+	if normalized == "" {
+		return models.BlockedTerm{}, ErrBlockedTermRequired
+	}
+
+	action := contentfilter.ActionReject
+	if creation.Action != "" {
+		action = contentfilter.ParseAction(creation.Action)
+		if action == "" {
+			return models.BlockedTerm{}, ErrInvalidFilterAction
+		}
+	}
+	if strings.HasSuffix(term, "*") {
+		normalized += "*"
+	}
+
+	existing, err := s.blockedTermRepo.FindByNormalized(ctx, normalized)
+	if err != nil {
+		return models.BlockedTerm{}, err
+	}
+	if existing.ID != "" {
+		return models.BlockedTerm{}, ErrBlockedTermExists
+	}
+
+	var variants []string
+	for _, variant := range creation.Variants {
+		if variant = strings.TrimSpace(variant); variant != "" {
+			variants = append(variants, variant)
+		}
+	}
+
+	created, err := s.blockedTermRepo.Create(ctx, models.BlockedTerm{
+		Term:       term,
+		Normalized: normalized,
+		Variants:   variants,
+		Language:   strings.ToLower(strings.TrimSpace(creation.Language)),
+		Action:     string(action),
+		CreatedBy:  adminID,
+	})
+	if err != nil {
+		return models.BlockedTerm{}, err
+	}
+
+	s.invalidate()
+	return created, nil
+}
+
+// DeleteTerm retira um termo da lista de bloqueio
+func (s *ContentFilterService) DeleteTerm(ctx context.Context, id string) error {
+	term, err := s.blockedTermRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if term.ID == "" {
+		return ErrBlockedTermNotFound
+	}
+
+	if err := s.blockedTermRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.invalidate()
+	return nil
+}
+
+// loadBlocklist devolve a lista de bloqueio em memória, lendo-a de novo quando expirou.
+// Se a leitura falhar, a lista anterior continua em uso.
+func (s *ContentFilterService) loadBlocklist(ctx context.Context) (*contentfilter.Blocklist, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.blocklist != nil && time.Since(s.loadedAt) < s.refresh {
+		return s.blocklist, nil
+	}
+
+	terms, err := s.blockedTermRepo.FindAll(ctx)
+	if err != nil {
+		if s.blocklist != nil {
+			return s.blocklist, nil
+		}
+		return nil, err
+	}
+
+	filterTerms := make([]contentfilter.Term, 0, len(terms))
+	for _, term := range terms {
+		filterTerms = append(filterTerms, contentfilter.Term{
+			Text:     term.Term,
+			Variants: term.Variants,
+			Action:   contentfilter.Action(term.Action),
+		})
+	}
+	s.blocklist = contentfilter.NewBlocklist(filterTerms)
+	s.loadedAt = time.Now()
+	return s.blocklist, nil
+}
+
+// invalidate obriga a ler de novo a lista de bloqueio no próximo conteúdo
+func (s *ContentFilterService) invalidate() {
+	s.mu.Lock()
+	s.blocklist = nil
+	s.mu.Unlock()
+}
@@ -9,28 +9,45 @@ import (
 )
 
 type InformationService struct {
-	infoRepo interfaces.InformationRepository
+	infoRepo     interfaces.InformationRepository
+	mediaService MediaService
+	audit        AuditService
 }
 
-func NewInformationService(infoRepo interfaces.InformationRepository) InformationService {
+func NewInformationService(infoRepo interfaces.InformationRepository, mediaService MediaService, audit AuditService) InformationService {
 	return InformationService{
-		infoRepo: infoRepo,
+		infoRepo:     infoRepo,
+		mediaService: mediaService,
+		audit:        audit,
 	}
 }
 
 func (s *InformationService) CreateInformation(ctx context.Context, info models.Information, authorID string) (models.Information, error) {
-	// Converter ID do autor para ObjectID
+	media, err := s.mediaService.ValidateAttachments(ctx, authorID, info.Attachments, nil)
+	if err != nil {
+		return models.Information{}, err
+	}
+
 	// Configurar campos do artigo
 	info.AuthorID = authorID
 	info.CreatedAt = time.Now()
 	info.UpdatedAt = time.Now()
 
 	// Salvar artigo
-	err := s.infoRepo.Create(ctx, info)
+	info, err = s.infoRepo.Create(ctx, info)
 	if err != nil {
 		return models.Information{}, err
 	}
 
+	s.audit.Record(ctx, models.AuditEntry{
+		AdminID:    authorID,
+		Action:     models.AuditInfoCreate,
+		TargetType: models.AuditTargetInformation,
+		TargetID:   info.ID,
+		After:      snapshot(info),
+	})
+	info.Media = media
+
 	return info, nil
 }
 
@@ -41,6 +58,11 @@ func (s *InformationService) GetInformation(ctx context.Context, id string) (mod
 		return models.Information{}, err
 	}
 
+	info.Media, err = s.mediaService.Resolve(ctx, info.Attachments)
+	if err != nil {
+		return models.Information{}, err
+	}
+
 	return info, nil
 }
 
@@ -50,15 +72,38 @@ func (s *InformationService) GetAllInformation(ctx context.Context, page, limit
 		return nil, 0, err
 	}
 
+	// Carregar os anexos de todas as informações numa só consulta
+	var ids []string
+	for _, info := range infoItems {
+		ids = append(ids, info.Attachments...)
+	}
+	media, err := s.mediaService.Resolve(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[string]models.Media, len(media))
+	for _, m := range media {
+		byID[m.ID] = m
+	}
+	for i := range infoItems {
+		for _, id := range infoItems[i].Attachments {
+			if m, ok := byID[id]; ok {
+				infoItems[i].Media = append(infoItems[i].Media, m)
+			}
+		}
+	}
+
 	return infoItems, int(total), nil
 }
 
-func (s *InformationService) UpdateInformation(ctx context.Context, id string, updateData models.Information) (models.Information, error) {
+// UpdateInformation altera a informação; editorID é o administrador que a alterou
+func (s *InformationService) UpdateInformation(ctx context.Context, id, editorID string, updateData models.Information) (models.Information, error) {
 	// Obter informação atual
 	info, err := s.infoRepo.FindByID(ctx, id)
 	if err != nil {
 		return models.Information{}, err
 	}
+	before := snapshot(info)
 
 	// Atualizar campos
 	if updateData.Title != "" {
@@ -69,6 +114,12 @@ func (s *InformationService) UpdateInformation(ctx context.Context, id string, u
 		info.Content = updateData.Content
 	}
 
+	media, err := s.mediaService.ValidateAttachments(ctx, editorID, updateData.Attachments, info.Attachments)
+	if err != nil {
+		return models.Information{}, err
+	}
+	info.Attachments = updateData.Attachments
+
 	// Atualizar data de modificação
 	info.UpdatedAt = time.Now()
 
@@ -78,9 +129,39 @@ func (s *InformationService) UpdateInformation(ctx context.Context, id string, u
 		return models.Information{}, err
 	}
 
+	s.audit.Record(ctx, models.AuditEntry{
+		AdminID:    editorID,
+		Action:     models.AuditInfoUpdate,
+		TargetType: models.AuditTargetInformation,
+		TargetID:   info.ID,
+		Before:     before,
+		After:      snapshot(info),
+	})
+	info.Media = media
+
 	return info, nil
 }
 
-func (s *InformationService) DeleteInformation(ctx context.Context, id string) error {
-	return s.infoRepo.Delete(ctx, id)
+// DeleteInformation exclui a informação; adminID é o administrador que a excluiu
+func (s *InformationService) DeleteInformation(ctx context.Context, id, adminID string) error {
+	info, err := s.infoRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.infoRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	if info.ID == "" {
+		return nil
+	}
+
+	s.audit.Record(ctx, models.AuditEntry{
+		AdminID:    adminID,
+		Action:     models.AuditInfoDelete,
+		TargetType: models.AuditTargetInformation,
+		TargetID:   id,
+		Before:     snapshot(info),
+	})
+	return nil
 }
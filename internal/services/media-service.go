@@ -0,0 +1,359 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anamalala/internal/models"
+	"github.com/anamalala/internal/repositories/interfaces"
+	"github.com/anamalala/pkg/imaging"
+	"github.com/anamalala/pkg/storage"
+)
+
+var (
+	// ErrMediaTooLarge indica um ficheiro acima do tamanho máximo permitido
+	ErrMediaTooLarge = errors.New("ficheiro demasiado grande")
+	// ErrMediaEmpty indica um ficheiro sem conteúdo
+	ErrMediaEmpty = errors.New("ficheiro vazio")
+	// ErrMediaTypeNotAllowed indica um tipo de ficheiro que não é aceite
+	ErrMediaTypeNotAllowed = errors.New("tipo de ficheiro não permitido")
+	// ErrMediaNotFound indica um ficheiro que não existe
+	ErrMediaNotFound = errors.New("ficheiro não encontrado")
+	// ErrInvalidMediaURL indica um link de download expirado ou com assinatura inválida
+	ErrInvalidMediaURL = errors.New("link de download inválido ou expirado")
+	// ErrInvalidImage indica uma imagem corrompida ou que não corresponde ao seu formato
+	ErrInvalidImage = errors.New("imagem inválida")
+	// ErrInvalidAttachments indica anexos inexistentes, de outro usuário ou em número excessivo
+	ErrInvalidAttachments = errors.New("anexos inválidos")
+)
+
+// MaxAttachments é o número máximo de anexos numa postagem ou informação
+const MaxAttachments = 10
+
+// allowedMediaTypes são os tipos de ficheiro aceites, identificados pelo conteúdo e não pelo nome
+var allowedMediaTypes = map[string]models.PostType{
+	"image/jpeg":      models.PostTypeImage,
+	"image/png":       models.PostTypeImage,
+	"image/gif":       models.PostTypeImage,
+	"image/webp":      models.PostTypeImage,
+	"video/mp4":       models.PostTypeVideo,
+	"video/webm":      models.PostTypeVideo,
+	"application/pdf": models.PostTypeFile,
+}
+
+type MediaService struct {
+	mediaRepo       interfaces.MediaRepository
+	storage         storage.Storage
+	maxUploadSize   int64
+	thumbnailWidths []int
+	signingSecret   []byte
+	urlTTL          time.Duration
+	publicURL       string
+}
+
+func NewMediaService(
+	mediaRepo interfaces.MediaRepository,
+	storage storage.Storage,
+	maxUploadSize int64,
+	thumbnailWidths []int,
+	signingSecret string,
+	urlTTL time.Duration,
+	publicURL string,
+) MediaService {
+	return MediaService{
+		mediaRepo:       mediaRepo,
+		storage:         storage,
+		maxUploadSize:   maxUploadSize,
+		thumbnailWidths: thumbnailWidths,
+		signingSecret:   []byte(signingSecret),
+		urlTTL:          urlTTL,
+		publicURL:       strings.TrimSuffix(publicURL, "/"),
+	}
+}
+
+// MaxUploadSize devolve o tamanho máximo de um ficheiro em bytes
+func (s *MediaService) MaxUploadSize() int64 {
+	return s.maxUploadSize
+}
+
+// Upload valida e guarda um ficheiro enviado pelo usuário.
+// Um ficheiro que o usuário já enviou não é registado de novo: é devolvido o que já existe.
+// Se o mesmo conteúdo foi enviado por outro usuário, o ficheiro guardado é reaproveitado
+// mas o usuário fica com o seu próprio registo.
+func (s *MediaService) Upload(ctx context.Context, userID, filename string, content io.ReadSeeker) (models.Media, error) {
+	// O tipo é detetado pelos primeiros bytes, sem confiar no que o cliente declara
+	head := make([]byte, 512)
+	n, err := io.ReadFull(content, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return models.Media{}, err
+	}
+	if n == 0 {
+		return models.Media{}, ErrMediaEmpty
+	}
+	contentType := http.DetectContentType(head[:n])
+	kind, ok := allowedMediaTypes[contentType]
+	if !ok {
+		return models.Media{}, ErrMediaTypeNotAllowed
+	}
+
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return models.Media{}, err
+	}
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, io.LimitReader(content, s.maxUploadSize+1))
+	if err != nil {
+		return models.Media{}, err
+	}
+	if size > s.maxUploadSize {
+		return models.Media{}, ErrMediaTooLarge
+	}
+	hash := hex.EncodeToString(hasher.Sum(nil))
+
+	existing, err := s.mediaRepo.FindByUserHash(ctx, userID, hash)
+	if err != nil {
+		return models.Media{}, err
+	}
+	if existing.ID != "" {
+		return s.sign(existing), nil
+	}
+
+	stored, err := s.mediaRepo.FindByHash(ctx, hash)
+	if err != nil {
+		return models.Media{}, err
+	}
+	if stored.ID != "" {
+		media := stored
+		media.ID = ""
+		media.UserID = userID
+		media.Filename = cleanFilename(filename)
+		media, err = s.mediaRepo.Create(ctx, media)
+		if err != nil {
+			return models.Media{}, err
+		}
+		return s.sign(media), nil
+	}
+
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return models.Media{}, err
+	}
+	media := models.Media{
+		UserID:      userID,
+		Hash:        hash,
+		StorageKey:  hash[:2] + "/" + hash,
+		Filename:    cleanFilename(filename),
+		ContentType: contentType,
+		Kind:        kind,
+		Size:        size,
+	}
+	if kind == models.PostTypeImage {
+		media, err = s.storeImage(ctx, media, content)
+	} else {
+		err = s.storage.Put(ctx, media.StorageKey, content)
+	}
+	if err != nil {
+		return models.Media{}, err
+	}
+
+	media, err = s.mediaRepo.Create(ctx, media)
+	if err != nil {
+		return models.Media{}, err
+	}
+
+	return s.sign(media), nil
+}
+
+// storeImage guarda a imagem sem metadados (incluindo a localização GPS) e as suas miniaturas,
+// ao lado do original. Formatos que não é possível descodificar ficam sem miniaturas.
+func (s *MediaService) storeImage(ctx context.Context, media models.Media, content io.Reader) (models.Media, error) {
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return models.Media{}, err
+	}
+
+	data, orientation, err := imaging.StripMetadata(data, media.ContentType)
+	if err != nil {
+		return models.Media{}, ErrInvalidImage
+	}
+	media.Size = int64(len(data))
+
+	width, height, thumbnails, err := imaging.Thumbnails(data, orientation, s.thumbnailWidths)
+	if err != nil && !errors.Is(err, imaging.ErrUnsupportedFormat) {
+		return models.Media{}, ErrInvalidImage
+	}
+	media.Width, media.Height = width, height
+
+	for _, thumbnail := range thumbnails {
+		key := fmt.Sprintf("%s.w%d", media.StorageKey, thumbnail.Width)
+		if err := s.storage.Put(ctx, key, bytes.NewReader(thumbnail.Data)); err != nil {
+			return models.Media{}, err
+		}
+		media.Variants = append(media.Variants, models.MediaVariant{
+			Width:       thumbnail.Width,
+			Height:      thumbnail.Height,
+			ContentType: thumbnail.ContentType,
+			StorageKey:  key,
+			Size:        int64(len(thumbnail.Data)),
+		})
+	}
+
+	if err := s.storage.Put(ctx, media.StorageKey, bytes.NewReader(data)); err != nil {
+		return models.Media{}, err
+	}
+	return media, nil
+}
+
+// Open verifica a assinatura de um link de download e abre o ficheiro, ou a miniatura
+// com a largura indicada. Quem chama deve fechar o conteúdo devolvido.
+func (s *MediaService) Open(ctx context.Context, id, width, expires, signature string) (models.Media, io.ReadCloser, error) {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return models.Media{}, nil, ErrInvalidMediaURL
+	}
+	variantWidth := 0
+	if width != "" {
+		variantWidth, err = strconv.Atoi(width)
+		if err != nil || variantWidth <= 0 {
+			return models.Media{}, nil, ErrInvalidMediaURL
+		}
+	}
+	expected := s.signature(id, variantWidth, expiresAt)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return models.Media{}, nil, ErrInvalidMediaURL
+	}
+
+	media, err := s.mediaRepo.FindByID(ctx, id)
+	if err != nil {
+		return models.Media{}, nil, err
+	}
+	if media.ID == "" {
+		return models.Media{}, nil, ErrMediaNotFound
+	}
+
+	if variantWidth > 0 {
+		i := slices.IndexFunc(media.Variants, func(v models.MediaVariant) bool { return v.Width == variantWidth })
+		if i < 0 {
+			return models.Media{}, nil, ErrMediaNotFound
+		}
+		variant := media.Variants[i]
+		media.StorageKey = variant.StorageKey
+		media.ContentType = variant.ContentType
+		media.Size = variant.Size
+		media.Width, media.Height = variant.Width, variant.Height
+	}
+
+	content, err := s.storage.Open(ctx, media.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return models.Media{}, nil, ErrMediaNotFound
+	}
+	if err != nil {
+		return models.Media{}, nil, err
+	}
+
+	return media, content, nil
+}
+
+// Resolve devolve os ficheiros com os IDs indicados, pela mesma ordem e com links assinados.
+// IDs inexistentes são ignorados.
+func (s *MediaService) Resolve(ctx context.Context, ids []string) (models.MediaList, error) {
+	found, err := s.mediaRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]models.Media, len(found))
+	for _, media := range found {
+		byID[media.ID] = media
+	}
+
+	media := make(models.MediaList, 0, len(ids))
+	for _, id := range ids {
+		if m, ok := byID[id]; ok {
+			media = append(media, s.sign(m))
+		}
+	}
+	return media, nil
+}
+
+// ValidateAttachments confirma que todos os anexos existem e foram enviados pelo usuário,
+// e devolve-os pela ordem indicada. Os anexos em current, que o conteúdo editado já tinha,
+// são aceites mesmo que tenham sido enviados por outro usuário.
+func (s *MediaService) ValidateAttachments(ctx context.Context, userID string, ids, current []string) (models.MediaList, error) {
+	if len(ids) > MaxAttachments {
+		return nil, ErrInvalidAttachments
+	}
+
+	media, err := s.Resolve(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(media) != len(ids) {
+		return nil, ErrInvalidAttachments
+	}
+	for _, m := range media {
+		if m.UserID != userID && !slices.Contains(current, m.ID) {
+			return nil, ErrInvalidAttachments
+		}
+	}
+	return media, nil
+}
+
+// sign preenche os links de download assinados do ficheiro e das suas miniaturas
+func (s *MediaService) sign(media models.Media) models.Media {
+	expiresAt := time.Now().Add(s.urlTTL).Truncate(time.Second)
+
+	media.URL = s.signedURL(media.ID, 0, expiresAt.Unix())
+	media.URLExpiresAt = expiresAt
+
+	variants := make([]models.MediaVariant, len(media.Variants))
+	for i, variant := range media.Variants {
+		variant.URL = s.signedURL(media.ID, variant.Width, expiresAt.Unix())
+		variants[i] = variant
+	}
+	media.Variants = variants
+	return media
+}
+
+// signedURL monta o link de download do ficheiro, ou da miniatura quando width é maior que zero
+func (s *MediaService) signedURL(id string, width int, expiresAt int64) string {
+	query := url.Values{}
+	if width > 0 {
+		query.Set("width", strconv.Itoa(width))
+	}
+	query.Set("expires", strconv.FormatInt(expiresAt, 10))
+	query.Set("signature", s.signature(id, width, expiresAt))
+
+	return s.publicURL + "/api/v1/media/" + url.PathEscape(id) + "?" + query.Encode()
+}
+
+// signature calcula o HMAC que autoriza o download do ficheiro, ou de uma miniatura, até expiresAt
+func (s *MediaService) signature(id string, width int, expiresAt int64) string {
+	message := id + ":" + strconv.FormatInt(expiresAt, 10)
+	if width > 0 {
+		message = id + ":w" + strconv.Itoa(width) + ":" + strconv.FormatInt(expiresAt, 10)
+	}
+
+	mac := hmac.New(sha256.New, s.signingSecret)
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// cleanFilename guarda apenas o nome do ficheiro, sem caminhos enviados pelo cliente
+func cleanFilename(filename string) string {
+	filename = filename[strings.LastIndexAny(filename, `/\`)+1:]
+	if len(filename) > 255 {
+		filename = strings.ToValidUTF8(filename[:255], "")
+	}
+	return filename
+}
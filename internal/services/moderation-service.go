@@ -0,0 +1,334 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/anamalala/internal/models"
+	"github.com/anamalala/internal/repositories/interfaces"
+	"github.com/anamalala/pkg/logger"
+)
+
+var (
+	// ErrInvalidReportReason indica um motivo de denúncia desconhecido
+	ErrInvalidReportReason = errors.New("motivo de denúncia inválido")
+	// ErrReportedContentNotFound indica uma denúncia de conteúdo que não existe ou já foi excluído
+	ErrReportedContentNotFound = errors.New("conteúdo não encontrado")
+	// ErrReportOwnContent indica um usuário a denunciar o próprio conteúdo
+	ErrReportOwnContent = errors.New("não é possível denunciar o próprio conteúdo")
+	// ErrAlreadyReported indica que o usuário já denunciou este conteúdo
+	ErrAlreadyReported = errors.New("já denunciou este conteúdo")
+	// ErrModerationItemNotFound indica um item da fila de moderação que não existe
+	ErrModerationItemNotFound = errors.New("denúncia não encontrada")
+	// ErrModerationItemResolved indica um item da fila de moderação que já foi decidido
+	ErrModerationItemResolved = errors.New("a denúncia já foi decidida")
+	// ErrInvalidModerationAction indica uma decisão de moderação desconhecida
+	ErrInvalidModerationAction = errors.New("ação de moderação inválida")
+	// ErrInvalidModerationStatus indica um filtro de estado desconhecido
+	ErrInvalidModerationStatus = errors.New("estado de moderação inválido")
+)
+
+// Decisões que um moderador pode tomar sobre um item da fila
+const (
+	ModerationActionApprove = "approve"
+	ModerationActionRemove  = "remove"
+	ModerationActionDismiss = "dismiss"
+)
+
+// moderationActions associa cada decisão ao estado final do item
+var moderationActions = map[string]models.ModerationStatus{
+	ModerationActionApprove: models.ModerationStatusApproved,
+	ModerationActionRemove:  models.ModerationStatusRemoved,
+	ModerationActionDismiss: models.ModerationStatusDismissed,
+}
+
+const (
+	// maxReportDetails limita o texto livre de uma denúncia
+	maxReportDetails = 500
+	// maxExcerpt limita a cópia do conteúdo guardada para os moderadores
+	maxExcerpt = 1000
+)
+
+// ModerationService recebe as denúncias dos usuários e as decisões dos moderadores.
+// O conteúdo com denúncias suficientes fica oculto até um moderador o analisar.
+type ModerationService struct {
+	moderationRepo      interfaces.ModerationRepository
+	postRepo            interfaces.PostRepository
+	commentRepo         interfaces.CommentRepository
+	chatroomService     ChatroomService
+	notificationService *NotificationService
+	eventBus            interfaces.EventBus
+	audit               AuditService
+	logger              *logger.Logger
+	hideThreshold       int
+}
+
+func NewModerationService(
+	moderationRepo interfaces.ModerationRepository,
+	postRepo interfaces.PostRepository,
+	commentRepo interfaces.CommentRepository,
+	chatroomService ChatroomService,
+	notificationService *NotificationService,
+	eventBus interfaces.EventBus,
+	audit AuditService,
+	logger *logger.Logger,
+	hideThreshold int,
+) ModerationService {
+	return ModerationService{
+		moderationRepo:      moderationRepo,
+		postRepo:            postRepo,
+		commentRepo:         commentRepo,
+		chatroomService:     chatroomService,
+		notificationService: notificationService,
+		eventBus:            eventBus,
+		audit:               audit,
+		logger:              logger,
+		hideThreshold:       hideThreshold,
+	}
+}
+
+// ReportPost regista a denúncia de uma postagem
+func (s *ModerationService) ReportPost(ctx context.Context, postID, reporterID string, creation models.ReportCreation) (models.ModerationItem, error) {
+	post, err := s.postRepo.FindVisibleByID(ctx, postID)
+	if err != nil {
+		return models.ModerationItem{}, err
+	}
+	if post.ID == "" {
+		return models.ModerationItem{}, ErrReportedContentNotFound
+	}
+
+	return s.report(ctx, models.ModerationItem{
+		ContentType: "post",
+		ContentID:   post.ID,
+		PostID:      post.ID,
+		AuthorID:    post.UserID,
+		Excerpt:     excerpt(post.Content),
+	}, reporterID, creation)
+}
+
+// ReportComment regista a denúncia de um comentário ou resposta
+func (s *ModerationService) ReportComment(ctx context.Context, commentID, reporterID string, creation models.ReportCreation) (models.ModerationItem, error) {
+	comment, err := s.commentRepo.FindVisibleByID(ctx, commentID)
+	if err != nil {
+		return models.ModerationItem{}, err
+	}
+	if comment.ID == "" || comment.Deleted {
+		return models.ModerationItem{}, ErrReportedContentNotFound
+	}
+
+	postID := comment.PostID
+	if postID == "" && comment.Reference == "post" {
+		postID = comment.ReferenceID
+	}
+
+	return s.report(ctx, models.ModerationItem{
+		ContentType: "comment",
+		ContentID:   comment.ID,
+		PostID:      postID,
+		AuthorID:    comment.UserID,
+		Excerpt:     excerpt(comment.Content),
+	}, reporterID, creation)
+}
+
+// report junta a denúncia ao item pendente do conteúdo e oculta o conteúdo ao atingir o limite
+func (s *ModerationService) report(ctx context.Context, item models.ModerationItem, reporterID string, creation models.ReportCreation) (models.ModerationItem, error) {
+	if !creation.Reason.Valid() {
+		return models.ModerationItem{}, ErrInvalidReportReason
+	}
+	if item.AuthorID == reporterID {
+		return models.ModerationItem{}, ErrReportOwnContent
+	}
+
+	details := strings.TrimSpace(creation.Details)
+	if utf8.RuneCountInString(details) > maxReportDetails {
+		details = string([]rune(details)[:maxReportDetails])
+	}
+
+	item, added, err := s.moderationRepo.AddReport(ctx, item, models.Report{
+		ReporterID: reporterID,
+		Reason:     creation.Reason,
+		Details:    details,
+		CreatedAt:  time.Now(),
+	})
+	if err != nil {
+		return models.ModerationItem{}, err
+	}
+	if !added {
+		return models.ModerationItem{}, ErrAlreadyReported
+	}
+
+	if !item.Hidden && s.hideThreshold > 0 && item.ReportCount >= s.hideThreshold {
+		if err := s.setHidden(ctx, item, true); err != nil {
+			return models.ModerationItem{}, err
+		}
+		if err := s.moderationRepo.MarkHidden(ctx, item.ID); err != nil {
+			return models.ModerationItem{}, err
+		}
+		item.Hidden = true
+		s.publishRemoval(ctx, item)
+	}
+
+	return item, nil
+}
+
+// GetQueue devolve os itens da fila de moderação com o estado indicado, por omissão os pendentes
+func (s *ModerationService) GetQueue(ctx context.Context, status string, page, limit int) (models.ModerationItems, int, error) {
+	moderationStatus := models.ModerationStatus(status)
+	switch moderationStatus {
+	case "":
+		moderationStatus = models.ModerationStatusPending
+	case models.ModerationStatusPending, models.ModerationStatusApproved,
+		models.ModerationStatusRemoved, models.ModerationStatusDismissed:
+	default:
+		return nil, 0, ErrInvalidModerationStatus
+	}
+
+	items, total, err := s.moderationRepo.List(ctx, moderationStatus, int64(page), int64(limit))
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, int(total), nil
+}
+
+// GetItem devolve um item da fila de moderação com todas as denúncias
+func (s *ModerationService) GetItem(ctx context.Context, id string) (models.ModerationItem, error) {
+	item, err := s.moderationRepo.FindByID(ctx, id)
+	if err != nil {
+		return models.ModerationItem{}, err
+	}
+	if item.ID == "" {
+		return models.ModerationItem{}, ErrModerationItemNotFound
+	}
+	return item, nil
+}
+
+// Resolve aplica a decisão do moderador: approve mantém o conteúdo, remove exclui-o
+// e dismiss encerra as denúncias por serem infundadas. Quando o conteúdo não é removido
+// volta a ficar visível. Quem denunciou e o autor são notificados da decisão.
+// O item é reclamado antes de alterar o conteúdo, para que dois moderadores não apliquem
+// decisões diferentes; se a alteração falhar, o item volta à fila.
+func (s *ModerationService) Resolve(ctx context.Context, id, moderatorID, action, note string) (models.ModerationItem, error) {
+	status, ok := moderationActions[action]
+	if !ok {
+		return models.ModerationItem{}, ErrInvalidModerationAction
+	}
+
+	item, err := s.GetItem(ctx, id)
+	if err != nil {
+		return models.ModerationItem{}, err
+	}
+	if item.Status != models.ModerationStatusPending {
+		return models.ModerationItem{}, ErrModerationItemResolved
+	}
+
+	note = strings.TrimSpace(note)
+	resolved, err := s.moderationRepo.Resolve(ctx, item.ID, status, moderatorID, note)
+	if err != nil {
+		return models.ModerationItem{}, err
+	}
+	if !resolved {
+		return models.ModerationItem{}, ErrModerationItemResolved
+	}
+
+	switch {
+	case status == models.ModerationStatusRemoved:
+		// A exclusão segue o mesmo caminho do autor: um comentário com respostas fica como marcador
+		if item.ContentType == "comment" {
+			_, err = s.chatroomService.DeleteComment(ctx, item.ContentID, moderatorID)
+		} else {
+			err = s.chatroomService.DeletePost(ctx, item.ContentID, moderatorID)
+		}
+		if err == nil && !item.Hidden {
+			s.publishRemoval(ctx, item)
+		}
+	case item.Hidden:
+		err = s.setHidden(ctx, item, false)
+		if err == nil {
+			s.publishRestore(ctx, item)
+		}
+	}
+	if err != nil {
+		if reopenErr := s.moderationRepo.Reopen(context.Background(), item.ID, status, moderatorID); reopenErr != nil {
+			s.logger.Error("moderation_reopen_failed", "item_id", item.ID, "status", status, "error", reopenErr)
+		}
+		return models.ModerationItem{}, err
+	}
+
+	before := snapshot(item)
+	item.Status = status
+	item.ResolvedBy = moderatorID
+	item.ResolvedAt = time.Now()
+	item.Note = note
+
+	s.audit.Record(ctx, models.AuditEntry{
+		AdminID:    moderatorID,
+		Action:     models.AuditModerationResolve,
+		TargetType: models.AuditTargetModerationItem,
+		TargetID:   item.ID,
+		Reason:     note,
+		Before:     before,
+		After:      snapshot(item),
+	})
+
+	// Uma falha ao notificar não invalida a decisão
+	if err := s.notificationService.NotifyModerationOutcome(ctx, item); err != nil {
+		s.logger.Warn("moderation_notify_failed", "item_id", item.ID, "error", err)
+	}
+
+	return item, nil
+}
+
+// setHidden oculta ou volta a mostrar o conteúdo denunciado
+func (s *ModerationService) setHidden(ctx context.Context, item models.ModerationItem, hidden bool) error {
+	if item.ContentType == "comment" {
+		return s.commentRepo.SetHidden(ctx, item.ContentID, hidden)
+	}
+	return s.postRepo.SetHidden(ctx, item.ContentID, hidden)
+}
+
+// publishRemoval avisa os clientes para retirarem o conteúdo oculto ou removido.
+// Com o barramento do MongoDB o evento é derivado da própria escrita.
+func (s *ModerationService) publishRemoval(ctx context.Context, item models.ModerationItem) {
+	event := models.DeletePostEvent(item.ContentID)
+	if item.ContentType == "comment" {
+		event = models.DeleteCommentEvent(models.Comment{ID: item.ContentID, PostID: item.PostID}, false)
+	}
+
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		s.logger.Warn("moderation_publish_failed", "item_id", item.ID, "error", err)
+	}
+}
+
+// publishRestore volta a mostrar aos clientes o conteúdo oculto que o moderador manteve.
+// Com o barramento do MongoDB o evento é derivado da própria escrita.
+func (s *ModerationService) publishRestore(ctx context.Context, item models.ModerationItem) {
+	var event models.Event
+	if item.ContentType == "comment" {
+		comment, err := s.commentRepo.FindVisibleByID(ctx, item.ContentID)
+		if err != nil || comment.ID == "" {
+			return
+		}
+		event = models.NewCommentEvent(comment)
+	} else {
+		post, err := s.postRepo.FindVisibleByID(ctx, item.ContentID)
+		if err != nil || post.ID == "" {
+			return
+		}
+		event = models.NewPostEvent(post)
+	}
+
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		s.logger.Warn("moderation_publish_failed", "item_id", item.ID, "error", err)
+	}
+}
+
+// excerpt guarda no máximo maxExcerpt caracteres do conteúdo denunciado
+func excerpt(content string) string {
+	if utf8.RuneCountInString(content) <= maxExcerpt {
+		return content
+	}
+	return string([]rune(content)[:maxExcerpt])
+}
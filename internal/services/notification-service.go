@@ -9,25 +9,173 @@ import (
 	"github.com/anamalala/internal/repositories/interfaces"
 )
 
+var (
+	ErrNotificationNotFound  = errors.New("notificação não encontrada")
+	ErrNotificationForbidden = errors.New("notificação não pertence a este usuário")
+	ErrInvalidPreferences    = errors.New("preferências de notificação inválidas")
+)
+
+// Tipos das mensagens enviadas pela WebSocket ao destinatário
+const (
+	NotificationEventNew         = "notification"
+	NotificationEventUnreadCount = "unread_count"
+)
+
+// NotificationPusher entrega mensagens em tempo real às conexões de um usuário.
+// PushToUser devolve o número de conexões que receberam a mensagem.
+type NotificationPusher interface {
+	PushToUser(userID string, messageType string, payload any) int
+}
+
 type NotificationService struct {
 	notificationRepo interfaces.NotificationRepository
+	preferencesRepo  interfaces.NotificationPreferencesRepository
 	userRepo         interfaces.UserRepository
+	smsOutbox        SMSOutboxService
+	pusher           NotificationPusher
 }
 
 func NewNotificationService(
 	notificationRepo interfaces.NotificationRepository,
+	preferencesRepo interfaces.NotificationPreferencesRepository,
 	userRepo interfaces.UserRepository,
+	smsOutbox SMSOutboxService,
 ) *NotificationService {
 	return &NotificationService{
 		notificationRepo: notificationRepo,
+		preferencesRepo:  preferencesRepo,
 		userRepo:         userRepo,
+		smsOutbox:        smsOutbox,
 	}
 }
 
+// SetPusher liga o serviço às conexões WebSocket; deve ser chamado antes de o servidor arrancar
+func (s *NotificationService) SetPusher(pusher NotificationPusher) {
+	s.pusher = pusher
+}
+
+// deliver grava a notificação e entrega-a em tempo real, pelos canais que o usuário permite.
+// Notificações de alta prioridade seguem por SMS quando não chegaram a nenhuma conexão ativa;
+// durante as horas de silêncio o SMS fica na fila até ao fim do período.
+func (s *NotificationService) deliver(ctx context.Context, notification models.Notification) (models.Notification, error) {
+	preferences := s.preferences(ctx, notification.UserID)
+	channels := preferences.Channels(notification.Type)
+
+	if channels.InApp {
+		created, err := s.notificationRepo.Create(ctx, notification)
+		if err != nil {
+			return models.Notification{}, err
+		}
+		notification = created
+	} else if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now()
+	}
+
+	delivered := 0
+	if s.pusher != nil && channels.WebSocket {
+		delivered = s.pusher.PushToUser(notification.UserID, NotificationEventNew, notification)
+		if delivered > 0 && channels.InApp {
+			s.pushUnreadCount(ctx, notification.UserID)
+		}
+	}
+
+	if delivered == 0 && notification.Priority == models.NotificationPriorityHigh && channels.SMS {
+		user, err := s.userRepo.FindByID(ctx, notification.UserID)
+		if err == nil && user.Contact != "" {
+			message := notification.Message
+			if notification.Title != "" {
+				message = notification.Title + ": " + message
+			}
+			sendAt := quietHoursEnd(preferences.QuietHours, time.Now())
+			// Uma falha no SMS não invalida a notificação
+			_ = s.smsOutbox.EnqueueAt(ctx, user.Contact, message, sendAt)
+		}
+	}
+
+	return notification, nil
+}
+
+// preferences devolve as preferências do usuário, ou as predefinidas se não houver nenhuma gravada
+func (s *NotificationService) preferences(ctx context.Context, userID string) models.NotificationPreferences {
+	preferences, err := s.preferencesRepo.FindByUserID(ctx, userID)
+	if err != nil || preferences.UserID == "" {
+		return models.DefaultNotificationPreferences(userID)
+	}
+	return preferences
+}
+
+// GetPreferences devolve as preferências de notificação do usuário com todos os tipos preenchidos
+func (s *NotificationService) GetPreferences(ctx context.Context, userID string) (models.NotificationPreferences, error) {
+	preferences, err := s.preferencesRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return models.NotificationPreferences{}, err
+	}
+
+	result := models.DefaultNotificationPreferences(userID)
+	if preferences.UserID == "" {
+		return result, nil
+	}
+
+	for notificationType, channels := range preferences.Types {
+		result.Types[notificationType] = channels
+	}
+	result.QuietHours = preferences.QuietHours
+	result.UpdatedAt = preferences.UpdatedAt
+
+	return result, nil
+}
+
+// UpdatePreferences altera os tipos indicados e as horas de silêncio, mantendo o resto
+func (s *NotificationService) UpdatePreferences(ctx context.Context, userID string, update models.NotificationPreferences) (models.NotificationPreferences, error) {
+	preferences, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		return models.NotificationPreferences{}, err
+	}
+
+	for notificationType, channels := range update.Types {
+		if _, ok := preferences.Types[notificationType]; !ok {
+			return models.NotificationPreferences{}, ErrInvalidPreferences
+		}
+		preferences.Types[notificationType] = channels
+	}
+
+	if update.QuietHours != (models.QuietHours{}) {
+		quietHours := update.QuietHours
+		if quietHours.Timezone == "" {
+			quietHours.Timezone = preferences.QuietHours.Timezone
+		}
+		if err := validateQuietHours(quietHours); err != nil {
+			return models.NotificationPreferences{}, err
+		}
+		preferences.QuietHours = quietHours
+	}
+
+	if err := s.preferencesRepo.Upsert(ctx, preferences); err != nil {
+		return models.NotificationPreferences{}, err
+	}
+
+	preferences.UpdatedAt = time.Now()
+	return preferences, nil
+}
+
+// pushUnreadCount envia ao usuário o total atualizado de notificações por ler
+func (s *NotificationService) pushUnreadCount(ctx context.Context, userID string) {
+	if s.pusher == nil {
+		return
+	}
+
+	count, err := s.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return
+	}
+
+	s.pusher.PushToUser(userID, NotificationEventUnreadCount, map[string]int64{"unread": count})
+}
+
 func (s *NotificationService) CreateNotification(ctx context.Context, notification models.Notification) (models.Notification, error) {
 	// Verificar se o usuário existe
-	_, err := s.userRepo.FindByID(ctx, notification.UserID)
-	if err != nil {
+	user, err := s.userRepo.FindByID(ctx, notification.UserID)
+	if err != nil || user.ID == "" {
 		return models.Notification{}, errors.New("usuário não encontrado")
 	}
 
@@ -35,17 +183,12 @@ func (s *NotificationService) CreateNotification(ctx context.Context, notificati
 	notification.CreatedAt = time.Now()
 	notification.Read = false
 
-	// Salvar notificação
-	err = s.notificationRepo.Create(ctx, notification)
-	if err != nil {
-		return models.Notification{}, err
-	}
-
-	return notification, nil
+	// Salvar e entregar notificação
+	return s.deliver(ctx, notification)
 }
 
-func (s *NotificationService) GetUserNotifications(ctx context.Context, userID string, page, limit int) (models.Notifications, int, error) {
-	notifications, total, err := s.notificationRepo.ListByUserID(ctx, userID, int64(page), int64(limit), false)
+func (s *NotificationService) GetUserNotifications(ctx context.Context, userID string, page, limit int, unreadOnly bool) (models.Notifications, int, error) {
+	notifications, total, err := s.notificationRepo.ListByUserID(ctx, userID, int64(page), int64(limit), unreadOnly)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -64,27 +207,36 @@ func (s *NotificationService) GetUnreadNotificationsCount(ctx context.Context, u
 
 func (s *NotificationService) MarkAsRead(ctx context.Context, notificationID string, userID string) error {
 	// Obter notificação
-	notification, _, err := s.notificationRepo.ListByUserID(ctx, notificationID, 0, 0, true)
+	notification, err := s.notificationRepo.FindByID(ctx, notificationID)
 	if err != nil {
 		return err
 	}
+	if notification.ID == "" {
+		return ErrNotificationNotFound
+	}
 
-	for _, n := range notification {
-		s.notificationRepo.MarkAsRead(ctx, n.UserID)
+	// Verificar se a notificação pertence ao usuário
+	if notification.UserID != userID {
+		return ErrNotificationForbidden
 	}
 
-	return nil
+	if err := s.notificationRepo.MarkAsRead(ctx, notificationID); err != nil {
+		return err
+	}
 
+	s.pushUnreadCount(ctx, userID)
+	return nil
 }
 
 func (s *NotificationService) MarkAllAsRead(ctx context.Context, userID string) (int, error) {
 
-	err := s.notificationRepo.MarkAllAsRead(ctx, userID)
+	count, err := s.notificationRepo.MarkAllAsRead(ctx, userID)
 	if err != nil {
 		return 0, err
 	}
 
-	return 0, nil
+	s.pushUnreadCount(ctx, userID)
+	return int(count), nil
 }
 
 func (s *NotificationService) DeleteNotification(ctx context.Context, notificationID string, userID string) error {
@@ -94,13 +246,23 @@ func (s *NotificationService) DeleteNotification(ctx context.Context, notificati
 	if err != nil {
 		return err
 	}
+	if notification.ID == "" {
+		return ErrNotificationNotFound
+	}
 
 	// Verificar se a notificação pertence ao usuário
 	if notification.UserID != userID {
-		return errors.New("notificação não pertence a este usuário")
+		return ErrNotificationForbidden
 	}
 
-	return s.notificationRepo.Delete(ctx, notificationID)
+	if err := s.notificationRepo.Delete(ctx, notificationID); err != nil {
+		return err
+	}
+
+	if !notification.Read {
+		s.pushUnreadCount(ctx, userID)
+	}
+	return nil
 }
 
 // Métodos para criar notificações específicas
@@ -120,14 +282,41 @@ func (s *NotificationService) NotifyNewComment(ctx context.Context, postAuthorID
 	// Criar notificação
 	notification := models.Notification{
 		UserID:    postAuthorID,
-		Type:      "comment",
+		Type:      models.NotificationTypeComment,
+		Title:     "Novo comentário",
 		Message:   commenter.Name + " comentou na sua postagem",
 		Reference: postID,
 		CreatedAt: time.Now(),
 		Read:      false,
 	}
 
-	err = s.notificationRepo.Create(ctx, notification)
+	_, err = s.deliver(ctx, notification)
+	return err
+}
+
+func (s *NotificationService) NotifyNewReply(ctx context.Context, commentAuthorID string, commentID string, replierID string) error {
+	// Não notificar quem responde ao próprio comentário
+	if commentAuthorID == replierID {
+		return nil
+	}
+
+	// Obter informações do usuário que respondeu
+	replier, err := s.userRepo.FindByID(ctx, replierID)
+	if err != nil {
+		return err
+	}
+
+	notification := models.Notification{
+		UserID:    commentAuthorID,
+		Type:      models.NotificationTypeReply,
+		Title:     "Nova resposta",
+		Message:   replier.Name + " respondeu ao seu comentário",
+		Reference: commentID,
+		CreatedAt: time.Now(),
+		Read:      false,
+	}
+
+	_, err = s.deliver(ctx, notification)
 	return err
 }
 
@@ -156,14 +345,15 @@ func (s *NotificationService) NotifyNewLike(ctx context.Context, contentAuthorID
 	// Criar notificação
 	notification := models.Notification{
 		UserID:    contentAuthorID,
-		Type:      "like",
+		Type:      models.NotificationTypeLike,
+		Title:     "Nova curtida",
 		Message:   message,
 		Reference: contentID,
 		CreatedAt: time.Now(),
 		Read:      false,
 	}
 
-	err = s.notificationRepo.Create(ctx, notification)
+	_, err = s.deliver(ctx, notification)
 	return err
 }
 
@@ -171,16 +361,74 @@ func (s *NotificationService) NotifyAdminAction(ctx context.Context, userID stri
 	// Criar notificação
 	notification := models.Notification{
 		UserID:    userID,
-		Type:      "admin",
+		Type:      models.NotificationTypeAdmin,
+		Priority:  models.NotificationPriorityHigh,
+		Title:     action,
 		Message:   action + ": " + reason,
 		CreatedAt: time.Now(),
 		Read:      false,
 	}
 
-	err := s.notificationRepo.Create(ctx, notification)
+	_, err := s.deliver(ctx, notification)
 	return err
 }
 
+// NotifyModerationOutcome informa quem denunciou e o autor da decisão do moderador
+func (s *NotificationService) NotifyModerationOutcome(ctx context.Context, item models.ModerationItem) error {
+	// Concordância de género: "a sua postagem" e "o seu comentário"
+	content, removed, kept := "a sua postagem", "removida", "mantê-la"
+	if item.ContentType == "comment" {
+		content, removed, kept = "o seu comentário", "removido", "mantê-lo"
+	}
+
+	var reporterMessage, authorMessage string
+	switch item.Status {
+	case models.ModerationStatusRemoved:
+		reporterMessage = "O conteúdo que denunciou foi removido. Obrigado por ajudar a manter a comunidade segura."
+		authorMessage = "Por violar as diretrizes da comunidade, " + content + " foi " + removed + "."
+		if item.Note != "" {
+			authorMessage += " Motivo: " + item.Note
+		}
+	case models.ModerationStatusApproved:
+		reporterMessage = "Analisámos o conteúdo que denunciou e não encontrámos violação das diretrizes da comunidade."
+		authorMessage = "Um moderador analisou denúncias sobre " + content + " e decidiu " + kept + "."
+	case models.ModerationStatusDismissed:
+		reporterMessage = "A sua denúncia foi analisada e encerrada sem ação."
+		authorMessage = "As denúncias sobre " + content + " foram encerradas sem ação."
+	default:
+		return errors.New("a denúncia ainda não foi decidida")
+	}
+
+	var errs []error
+	for _, report := range item.Reports {
+		// Conteúdo retido pelo filtro não tem quem denunciou
+		if report.ReporterID == "" {
+			continue
+		}
+		_, err := s.deliver(ctx, models.Notification{
+			UserID:    report.ReporterID,
+			Type:      models.NotificationTypeModeration,
+			Title:     "Denúncia analisada",
+			Message:   reporterMessage,
+			Reference: item.ContentID,
+			CreatedAt: time.Now(),
+		})
+		errs = append(errs, err)
+	}
+
+	_, err := s.deliver(ctx, models.Notification{
+		UserID:    item.AuthorID,
+		Type:      models.NotificationTypeModeration,
+		Title:     "Decisão de moderação",
+		Message:   authorMessage,
+		Reference: item.ContentID,
+		CreatedAt: time.Now(),
+	})
+	errs = append(errs, err)
+
+	return errors.Join(errs...)
+}
+
 func (s *NotificationService) NotifyAllUsers(ctx context.Context, message string, notificationType string) (int, error) {
 	// Obter todos os usuários
 	users, _, err := s.userRepo.List(ctx, 0, 0) // Sem paginação para obter todos
@@ -205,7 +453,7 @@ func (s *NotificationService) NotifyAllUsers(ctx context.Context, message string
 			Read:      false,
 		}
 
-		err := s.notificationRepo.Create(ctx, notification)
+		_, err := s.deliver(ctx, notification)
 		if err == nil {
 			createdCount++
 		}
@@ -218,3 +466,62 @@ func (s *NotificationService) NotifyAllUsers(ctx context.Context, message string
 
 	return createdCount, nil
 }
+
+// validateQuietHours verifica o formato HH:MM e o fuso horário das horas de silêncio
+func validateQuietHours(quietHours models.QuietHours) error {
+	if _, err := time.Parse("15:04", quietHours.Start); err != nil {
+		return ErrInvalidPreferences
+	}
+	if _, err := time.Parse("15:04", quietHours.End); err != nil {
+		return ErrInvalidPreferences
+	}
+	if _, err := time.LoadLocation(quietHours.Timezone); err != nil {
+		return ErrInvalidPreferences
+	}
+	return nil
+}
+
+// quietHoursEnd devolve o momento em que termina o período de silêncio que contém t.
+// Fora desse período, ou com as horas de silêncio desligadas, devolve t.
+func quietHoursEnd(quietHours models.QuietHours, t time.Time) time.Time {
+	if !quietHours.Enabled {
+		return t
+	}
+
+	start, errStart := time.Parse("15:04", quietHours.Start)
+	end, errEnd := time.Parse("15:04", quietHours.End)
+	if errStart != nil || errEnd != nil {
+		return t
+	}
+
+	location, err := time.LoadLocation(quietHours.Timezone)
+	if err != nil {
+		// Moçambique não tem horário de verão
+		location = time.FixedZone("CAT", 2*60*60)
+	}
+
+	local := t.In(location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+	startOffset := time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute
+	endOffset := time.Duration(end.Hour())*time.Hour + time.Duration(end.Minute())*time.Minute
+	now := local.Sub(midnight)
+
+	switch {
+	case startOffset == endOffset:
+		return t
+	case startOffset < endOffset:
+		if now >= startOffset && now < endOffset {
+			return midnight.Add(endOffset)
+		}
+	default:
+		// O período atravessa a meia-noite
+		if now >= startOffset {
+			return midnight.AddDate(0, 0, 1).Add(endOffset)
+		}
+		if now < endOffset {
+			return midnight.Add(endOffset)
+		}
+	}
+
+	return t
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/anamalala/internal/models"
+	"github.com/anamalala/internal/repositories/interfaces"
+)
+
+// notificationRepoStub guarda as notificações criadas
+type notificationRepoStub struct {
+	interfaces.NotificationRepository
+	created models.Notifications
+}
+
+func (r *notificationRepoStub) Create(ctx context.Context, notification models.Notification) (models.Notification, error) {
+	r.created = append(r.created, notification)
+	return notification, nil
+}
+
+// preferencesRepoStub simula usuários que nunca alteraram as preferências
+type preferencesRepoStub struct {
+	interfaces.NotificationPreferencesRepository
+}
+
+func (r preferencesRepoStub) FindByUserID(ctx context.Context, userID string) (models.NotificationPreferences, error) {
+	return models.NotificationPreferences{}, nil
+}
+
+func TestNotifyModerationOutcomeCreatesNotifications(t *testing.T) {
+	notificationRepo := &notificationRepoStub{}
+	service := NewNotificationService(notificationRepo, preferencesRepoStub{}, nil, SMSOutboxService{})
+
+	item := models.ModerationItem{
+		ContentType: "post",
+		ContentID:   "post-1",
+		AuthorID:    "author-1",
+		Status:      models.ModerationStatusRemoved,
+		Reports: []models.Report{
+			{ReporterID: "reporter-1", Reason: models.ReportReasonSpam},
+			{Reason: models.ReportReasonContentFilter},
+		},
+	}
+	if err := service.NotifyModerationOutcome(context.Background(), item); err != nil {
+		t.Fatalf("NotifyModerationOutcome: %v", err)
+	}
+
+	if len(notificationRepo.created) != 2 {
+		t.Fatalf("expected 2 notifications, got %d", len(notificationRepo.created))
+	}
+	recipients := map[string]bool{}
+	for _, notification := range notificationRepo.created {
+		if notification.Type != models.NotificationTypeModeration {
+			t.Errorf("expected type %q, got %q", models.NotificationTypeModeration, notification.Type)
+		}
+		recipients[notification.UserID] = true
+	}
+	if !recipients["reporter-1"] || !recipients["author-1"] {
+		t.Errorf("expected the reporter and the author to be notified, got %v", recipients)
+	}
+}
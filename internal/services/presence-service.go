@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/anamalala/internal/models"
+	"github.com/anamalala/internal/repositories/interfaces"
+	"github.com/anamalala/pkg/logger"
+)
+
+const (
+	// PresenceWindow é o tempo desde a última atividade na API em que um usuário sem ligações ainda conta como online
+	PresenceWindow = 5 * time.Minute
+	// presenceWriteInterval limita as escritas da atividade na API por usuário
+	presenceWriteInterval = time.Minute
+	// presenceSweepInterval é o intervalo entre verificações de usuários que ficaram offline
+	presenceSweepInterval = time.Minute
+)
+
+// PresenceService acompanha quem está online a partir das ligações WebSocket e da atividade na API.
+// As ligações abertas e o estado online ficam no documento do usuário, partilhado por todas as réplicas,
+// e tanto os eventos de presença como a contagem de online são derivados desse estado.
+type PresenceService struct {
+	userRepo interfaces.UserRepository
+	eventBus interfaces.EventBus
+	logger   *logger.Logger
+
+	mu        sync.Mutex
+	lastWrite map[string]time.Time
+}
+
+func NewPresenceService(userRepo interfaces.UserRepository, eventBus interfaces.EventBus, logger *logger.Logger) *PresenceService {
+	return &PresenceService{
+		userRepo:  userRepo,
+		eventBus:  eventBus,
+		logger:    logger,
+		lastWrite: make(map[string]time.Time),
+	}
+}
+
+// Touch regista atividade do usuário na API, no máximo uma escrita por minuto nesta instância.
+// Como a janela de presença é maior, quem escreveu há menos de um minuto continua online.
+func (s *PresenceService) Touch(userID string) {
+	now := time.Now()
+
+	s.mu.Lock()
+	write := now.Sub(s.lastWrite[userID]) >= presenceWriteInterval
+	if write {
+		s.lastWrite[userID] = now
+	}
+	s.mu.Unlock()
+
+	if write {
+		go s.markActive(userID, now)
+	}
+}
+
+// Connected regista uma nova ligação WebSocket do usuário
+func (s *PresenceService) Connected(userID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	cameOnline, err := s.userRepo.AddConnection(ctx, userID, now)
+	if err != nil {
+		s.logger.Warn("presence_connect_failed", "user_id", userID, "error", err)
+		return
+	}
+	if cameOnline {
+		s.publish(userID, true, now)
+	}
+}
+
+// Disconnected regista o fim de uma ligação WebSocket. O usuário só passa a offline quando
+// já não tem ligações em nenhuma réplica nem atividade recente na API; caso contrário,
+// é o Run que o anuncia quando a atividade expirar.
+func (s *PresenceService) Disconnected(userID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	remaining, err := s.userRepo.RemoveConnection(ctx, userID, now)
+	if err != nil {
+		s.logger.Warn("presence_disconnect_failed", "user_id", userID, "error", err)
+		return
+	}
+	if remaining > 0 {
+		return
+	}
+	s.setOfflineIfIdle(ctx, userID, now)
+}
+
+// GetOnlineStats conta os usuários online, por província
+func (s *PresenceService) GetOnlineStats(ctx context.Context) (models.OnlineStats, error) {
+	provinces, err := s.userRepo.CountOnlineByProvince(ctx)
+	if err != nil {
+		return models.OnlineStats{}, err
+	}
+
+	stats := models.OnlineStats{Provinces: provinces}
+	for _, count := range provinces {
+		stats.Total += count
+	}
+	return stats, nil
+}
+
+// Run anuncia como offline quem deixou de ter ligações e atividade na API, até o contexto ser cancelado
+func (s *PresenceService) Run(ctx context.Context) {
+	ticker := time.NewTicker(presenceSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+// sweep passa a offline os usuários inativos. Todas as réplicas o fazem, mas só a que muda
+// o estado de cada usuário anuncia a mudança.
+func (s *PresenceService) sweep(ctx context.Context) {
+	now := time.Now()
+
+	s.mu.Lock()
+	for userID, lastWrite := range s.lastWrite {
+		if now.Sub(lastWrite) >= PresenceWindow {
+			delete(s.lastWrite, userID)
+		}
+	}
+	s.mu.Unlock()
+
+	sweepCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	userIDs, err := s.userRepo.FindIdleOnline(sweepCtx, now.Add(-PresenceWindow))
+	if err != nil {
+		s.logger.Warn("presence_sweep_failed", "error", err)
+		return
+	}
+	for _, userID := range userIDs {
+		s.setOfflineIfIdle(sweepCtx, userID, now)
+	}
+}
+
+// markActive grava a atividade na API e anuncia o usuário se ele estava offline
+func (s *PresenceService) markActive(userID string, at time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cameOnline, err := s.userRepo.MarkActive(ctx, userID, at)
+	if err != nil {
+		s.logger.Warn("presence_activity_failed", "user_id", userID, "error", err)
+		return
+	}
+	if cameOnline {
+		s.publish(userID, true, at)
+	}
+}
+
+// setOfflineIfIdle passa o usuário a offline se estiver inativo e anuncia-o
+func (s *PresenceService) setOfflineIfIdle(ctx context.Context, userID string, now time.Time) {
+	offline, err := s.userRepo.SetOfflineIfIdle(ctx, userID, now.Add(-PresenceWindow))
+	if err != nil {
+		s.logger.Warn("presence_offline_failed", "user_id", userID, "error", err)
+		return
+	}
+	if offline {
+		s.publish(userID, false, now)
+	}
+}
+
+// publish anuncia a mudança de presença aos inscritos no tópico de presença
+func (s *PresenceService) publish(userID string, online bool, at time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	event := models.Event{
+		Type:   models.EventPresence,
+		Topics: []string{models.TopicPresence},
+		Payload: models.PresenceChange{
+			UserID:     userID,
+			Online:     online,
+			LastSeenAt: at,
+		},
+	}
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		s.logger.Warn("presence_publish_failed", "user_id", userID, "error", err)
+	}
+}
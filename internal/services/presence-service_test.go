@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/anamalala/internal/models"
+	"github.com/anamalala/internal/repositories/interfaces"
+	"github.com/anamalala/pkg/logger"
+)
+
+// presenceRepoStub guarda o estado de presença partilhado por todas as réplicas, como o documento do usuário
+type presenceRepoStub struct {
+	interfaces.UserRepository
+
+	mu          sync.Mutex
+	province    map[string]string
+	online      map[string]bool
+	connections map[string]int
+	lastActive  map[string]time.Time
+}
+
+func newPresenceRepoStub() *presenceRepoStub {
+	return &presenceRepoStub{
+		province:    map[string]string{"user-1": "Maputo", "user-2": "Gaza"},
+		online:      map[string]bool{},
+		connections: map[string]int{},
+		lastActive:  map[string]time.Time{},
+	}
+}
+
+func (r *presenceRepoStub) AddConnection(ctx context.Context, userID string, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.connections[userID]++
+	wasOnline := r.online[userID]
+	r.online[userID] = true
+	return !wasOnline, nil
+}
+
+func (r *presenceRepoStub) RemoveConnection(ctx context.Context, userID string, at time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.connections[userID] > 0 {
+		r.connections[userID]--
+	}
+	return r.connections[userID], nil
+}
+
+func (r *presenceRepoStub) MarkActive(ctx context.Context, userID string, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastActive[userID] = at
+	wasOnline := r.online[userID]
+	r.online[userID] = true
+	return !wasOnline, nil
+}
+
+// idle indica se o usuário está online sem ligações nem atividade desde since; chamado com mu bloqueado
+func (r *presenceRepoStub) idle(userID string, since time.Time) bool {
+	return r.online[userID] && r.connections[userID] == 0 && r.lastActive[userID].Before(since)
+}
+
+func (r *presenceRepoStub) FindIdleOnline(ctx context.Context, since time.Time) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var userIDs []string
+	for userID := range r.online {
+		if r.idle(userID, since) {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	return userIDs, nil
+}
+
+func (r *presenceRepoStub) SetOfflineIfIdle(ctx context.Context, userID string, since time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.idle(userID, since) {
+		return false, nil
+	}
+	r.online[userID] = false
+	return true, nil
+}
+
+func (r *presenceRepoStub) CountOnlineByProvince(ctx context.Context) (map[string]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts := map[string]int64{}
+	for userID, online := range r.online {
+		if online {
+			counts[r.province[userID]]++
+		}
+	}
+	return counts, nil
+}
+
+// presenceBusStub regista as mudanças de presença publicadas
+type presenceBusStub struct {
+	interfaces.EventBus
+
+	mu      sync.Mutex
+	changes []models.PresenceChange
+}
+
+func (b *presenceBusStub) Publish(ctx context.Context, event models.Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.changes = append(b.changes, event.Payload.(models.PresenceChange))
+	return nil
+}
+
+// take devolve as mudanças publicadas desde a última chamada
+func (b *presenceBusStub) take() []models.PresenceChange {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	changes := b.changes
+	b.changes = nil
+	return changes
+}
+
+// onlineTotal devolve o total de GetOnlineStats
+func onlineTotal(t *testing.T, service *PresenceService) int64 {
+	t.Helper()
+
+	stats, err := service.GetOnlineStats(context.Background())
+	if err != nil {
+		t.Fatalf("GetOnlineStats: %v", err)
+	}
+	return stats.Total
+}
+
+func TestPresenceAcrossReplicas(t *testing.T) {
+	repo := newPresenceRepoStub()
+	bus := &presenceBusStub{}
+	// Duas réplicas com o mesmo estado partilhado
+	first := NewPresenceService(repo, bus, logger.NewLogger("production"))
+	second := NewPresenceService(repo, bus, logger.NewLogger("production"))
+
+	first.Connected("user-1")
+	second.Connected("user-1")
+	if changes := bus.take(); len(changes) != 1 || !changes[0].Online {
+		t.Fatalf("expected one online event, got %+v", changes)
+	}
+
+	// Ainda ligado à outra réplica
+	first.Disconnected("user-1")
+	if changes := bus.take(); len(changes) != 0 {
+		t.Errorf("expected no offline event while connected to another replica, got %+v", changes)
+	}
+	if total := onlineTotal(t, first); total != 1 {
+		t.Errorf("expected 1 user online, got %d", total)
+	}
+
+	second.Disconnected("user-1")
+	if changes := bus.take(); len(changes) != 1 || changes[0].Online {
+		t.Fatalf("expected one offline event, got %+v", changes)
+	}
+	if total := onlineTotal(t, second); total != 0 {
+		t.Errorf("expected the offline user not to be counted, got %d", total)
+	}
+
+	// Desligar de novo não repete o evento
+	first.Disconnected("user-1")
+	if changes := bus.take(); len(changes) != 0 {
+		t.Errorf("expected no repeated offline event, got %+v", changes)
+	}
+}
+
+func TestPresenceRecentActivityKeepsOnline(t *testing.T) {
+	repo := newPresenceRepoStub()
+	bus := &presenceBusStub{}
+	service := NewPresenceService(repo, bus, logger.NewLogger("production"))
+
+	service.markActive("user-2", time.Now())
+	service.Connected("user-2")
+	service.Disconnected("user-2")
+	if changes := bus.take(); len(changes) != 1 || !changes[0].Online {
+		t.Fatalf("expected only the online event, got %+v", changes)
+	}
+	if total := onlineTotal(t, service); total != 1 {
+		t.Errorf("expected the recently active user to be counted, got %d", total)
+	}
+
+	// Quando a atividade expira, a verificação anuncia a saída uma única vez, mesmo em várias réplicas
+	repo.lastActive["user-2"] = time.Now().Add(-PresenceWindow - time.Minute)
+	other := NewPresenceService(repo, bus, logger.NewLogger("production"))
+	service.sweep(context.Background())
+	other.sweep(context.Background())
+	if changes := bus.take(); len(changes) != 1 || changes[0].Online || changes[0].UserID != "user-2" {
+		t.Fatalf("expected one offline event, got %+v", changes)
+	}
+	if total := onlineTotal(t, service); total != 0 {
+		t.Errorf("expected no users online, got %d", total)
+	}
+}
@@ -0,0 +1,421 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/anamalala/internal/models"
+	"github.com/anamalala/internal/repositories/interfaces"
+	"github.com/anamalala/pkg/logger"
+)
+
+var (
+	// ErrInvalidSanctionType indica um tipo diferente de warning, mute ou ban
+	ErrInvalidSanctionType = errors.New("tipo de sanção inválido: use warning, mute ou ban")
+	// ErrSanctionReasonRequired indica uma sanção sem motivo
+	ErrSanctionReasonRequired = errors.New("o motivo da sanção é obrigatório")
+	// ErrInvalidSanctionDuration indica uma duração negativa ou acima do máximo
+	ErrInvalidSanctionDuration = errors.New("duração da sanção inválida")
+	// ErrSanctionSelf indica um administrador a sancionar-se a si próprio
+	ErrSanctionSelf = errors.New("não é possível aplicar uma sanção a si próprio")
+	// ErrSanctionUserNotFound indica uma sanção a um usuário que não existe
+	ErrSanctionUserNotFound = errors.New("usuário não encontrado")
+	// ErrSanctionNotFound indica uma sanção que não existe
+	ErrSanctionNotFound = errors.New("sanção não encontrada")
+	// ErrSanctionNotInForce indica uma sanção já levantada, expirada ou que não restringe o usuário
+	ErrSanctionNotInForce = errors.New("a sanção já não está em vigor")
+	// ErrSanctionStaff indica uma sanção a um membro da equipa, aplicada ou levantada por quem não gere os roles
+	ErrSanctionStaff = errors.New("só um administrador pode gerir as sanções de membros da equipa")
+	// ErrUserNotBanned indica um pedido para levantar a suspensão de quem não está suspenso
+	ErrUserNotBanned = errors.New("usuário não está banido")
+)
+
+const (
+	// sanctionSweepInterval é o intervalo entre verificações de sanções expiradas
+	sanctionSweepInterval = time.Minute
+	// sanctionSweepBatch limita as sanções levantadas em cada verificação
+	sanctionSweepBatch = 100
+	// maxSanctionHours limita a duração de uma sanção temporária a um ano
+	maxSanctionHours = 365 * 24
+)
+
+// sanctionTime é o fuso usado nas datas dos SMS; Moçambique não tem horário de verão
+var sanctionTime = time.FixedZone("CAT", 2*60*60)
+
+// SanctionService aplica advertências, modo só de leitura e suspensões aos usuários,
+// guarda o histórico e levanta automaticamente as sanções que expiram.
+// O usuário é avisado por SMS de cada sanção e do seu fim, conforme as suas preferências.
+type SanctionService struct {
+	sanctionRepo    interfaces.SanctionRepository
+	userRepo        interfaces.UserRepository
+	preferencesRepo interfaces.NotificationPreferencesRepository
+	sessionService  SessionService
+	smsOutbox       SMSOutboxService
+	audit           AuditService
+	logger          *logger.Logger
+}
+
+func NewSanctionService(
+	sanctionRepo interfaces.SanctionRepository,
+	userRepo interfaces.UserRepository,
+	preferencesRepo interfaces.NotificationPreferencesRepository,
+	sessionService SessionService,
+	smsOutbox SMSOutboxService,
+	audit AuditService,
+	logger *logger.Logger,
+) SanctionService {
+	return SanctionService{
+		sanctionRepo:    sanctionRepo,
+		userRepo:        userRepo,
+		preferencesRepo: preferencesRepo,
+		sessionService:  sessionService,
+		smsOutbox:       smsOutbox,
+		audit:           audit,
+		logger:          logger,
+	}
+}
+
+// Issue aplica uma sanção ao usuário. Uma suspensão desativa a conta e termina as sessões abertas.
+func (s *SanctionService) Issue(ctx context.Context, adminID, userID string, creation models.SanctionCreation) (models.Sanction, error) {
+	if !creation.Type.Valid() {
+		return models.Sanction{}, ErrInvalidSanctionType
+	}
+	reason := strings.TrimSpace(creation.Reason)
+	if reason == "" {
+		return models.Sanction{}, ErrSanctionReasonRequired
+	}
+	if creation.DurationHours < 0 || creation.DurationHours > maxSanctionHours {
+		return models.Sanction{}, ErrInvalidSanctionDuration
+	}
+	if adminID == userID {
+		return models.Sanction{}, ErrSanctionSelf
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return models.Sanction{}, err
+	}
+	if user.ID == "" {
+		return models.Sanction{}, ErrSanctionUserNotFound
+	}
+
+	if err := s.checkStaffTarget(ctx, adminID, user); err != nil {
+		return models.Sanction{}, err
+	}
+
+	sanction := models.Sanction{
+		UserID:   userID,
+		Type:     creation.Type,
+		Reason:   reason,
+		IssuedBy: adminID,
+	}
+	if creation.Type != models.SanctionWarning && creation.DurationHours > 0 {
+		sanction.ExpiresAt = time.Now().Add(time.Duration(creation.DurationHours) * time.Hour)
+	}
+
+	sanction, err = s.sanctionRepo.Create(ctx, sanction)
+	if err != nil {
+		return models.Sanction{}, err
+	}
+
+	if sanction.Type == models.SanctionBan {
+		if err := s.userRepo.ToggleUserActive(ctx, userID, false); err != nil {
+			return models.Sanction{}, err
+		}
+		if _, err := s.sessionService.RevokeAllSessions(ctx, userID); err != nil {
+			return models.Sanction{}, err
+		}
+	}
+
+	s.audit.Record(ctx, models.AuditEntry{
+		AdminID:    adminID,
+		Action:     sanctionAuditAction(sanction.Type, false),
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+		Reason:     reason,
+		After:      snapshot(sanction),
+	})
+
+	s.notify(ctx, user, issuedMessage(sanction))
+	return sanction, nil
+}
+
+// Lift levanta uma sanção antes do fim
+func (s *SanctionService) Lift(ctx context.Context, adminID, sanctionID, reason string) (models.Sanction, error) {
+	sanction, err := s.sanctionRepo.FindByID(ctx, sanctionID)
+	if err != nil {
+		return models.Sanction{}, err
+	}
+	if sanction.ID == "" {
+		return models.Sanction{}, ErrSanctionNotFound
+	}
+	if !sanction.InForce(time.Now()) {
+		return models.Sanction{}, ErrSanctionNotInForce
+	}
+
+	user, err := s.userRepo.FindByID(ctx, sanction.UserID)
+	if err != nil {
+		return models.Sanction{}, err
+	}
+	if err := s.checkStaffTarget(ctx, adminID, user); err != nil {
+		return models.Sanction{}, err
+	}
+
+	lifted, err := s.lift(ctx, sanction, adminID, strings.TrimSpace(reason))
+	if err != nil {
+		return models.Sanction{}, err
+	}
+	if !lifted {
+		return models.Sanction{}, ErrSanctionNotInForce
+	}
+
+	return s.sanctionRepo.FindByID(ctx, sanctionID)
+}
+
+// LiftBans levanta todas as suspensões em vigor do usuário
+func (s *SanctionService) LiftBans(ctx context.Context, adminID, userID, reason string) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.ID == "" {
+		return ErrUserNotBanned
+	}
+	if err := s.checkStaffTarget(ctx, adminID, user); err != nil {
+		return err
+	}
+
+	sanctions, err := s.sanctionRepo.ListInForce(ctx, userID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	lifted := false
+	for _, sanction := range sanctions {
+		if sanction.Type != models.SanctionBan {
+			continue
+		}
+		ok, err := s.lift(ctx, sanction, adminID, strings.TrimSpace(reason))
+		if err != nil {
+			return err
+		}
+		lifted = lifted || ok
+	}
+
+	if !lifted {
+		// Contas desativadas antes do histórico de sanções são reativadas diretamente
+		if user.Active {
+			return ErrUserNotBanned
+		}
+		if err := s.userRepo.ToggleUserActive(ctx, userID, true); err != nil {
+			return err
+		}
+
+		reactivated := user
+		reactivated.Active = true
+		s.audit.Record(ctx, models.AuditEntry{
+			AdminID:    adminID,
+			Action:     models.AuditUserUnban,
+			TargetType: models.AuditTargetUser,
+			TargetID:   userID,
+			Reason:     strings.TrimSpace(reason),
+			Before:     snapshot(user),
+			After:      snapshot(reactivated),
+		})
+	}
+	return nil
+}
+
+// checkStaffTarget impede que um moderador aplique ou levante sanções a quem está acima dele,
+// como um administrador: as sanções de membros da equipa exigem a permissão de gerir roles
+func (s *SanctionService) checkStaffTarget(ctx context.Context, adminID string, user models.User) error {
+	if !user.Role.IsStaff() {
+		return nil
+	}
+
+	admin, err := s.userRepo.FindByID(ctx, adminID)
+	if err != nil {
+		return err
+	}
+	if !admin.Role.Can(models.PermRoleAssign) {
+		return ErrSanctionStaff
+	}
+	return nil
+}
+
+// GetHistory devolve o histórico de sanções do usuário, das mais recentes para as mais antigas
+func (s *SanctionService) GetHistory(ctx context.Context, userID string, page, limit int) (models.Sanctions, int, error) {
+	sanctions, total, err := s.sanctionRepo.ListByUser(ctx, userID, int64(page), int64(limit))
+	if err != nil {
+		return nil, 0, err
+	}
+	return sanctions, int(total), nil
+}
+
+// Restriction devolve a sanção que impede o usuário de escrever: a suspensão, se houver,
+// senão o modo só de leitura. Devolve uma sanção vazia quando o usuário pode escrever.
+func (s *SanctionService) Restriction(ctx context.Context, userID string) (models.Sanction, error) {
+	sanctions, err := s.sanctionRepo.ListInForce(ctx, userID, time.Now())
+	if err != nil {
+		return models.Sanction{}, err
+	}
+
+	restriction := models.Sanction{}
+	for _, sanction := range sanctions {
+		if sanction.Type == models.SanctionBan {
+			return sanction, nil
+		}
+		if restriction.ID == "" {
+			restriction = sanction
+		}
+	}
+	return restriction, nil
+}
+
+// RunScheduler levanta as sanções que expiraram, até o contexto ser cancelado
+func (s *SanctionService) RunScheduler(ctx context.Context) {
+	ticker := time.NewTicker(sanctionSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		s.liftExpired(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// liftExpired levanta as sanções cuja data de fim já passou
+func (s *SanctionService) liftExpired(ctx context.Context) {
+	for ctx.Err() == nil {
+		sanctions, err := s.sanctionRepo.ListExpired(ctx, time.Now(), sanctionSweepBatch)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Error("sanction_sweep_failed", "error", err.Error())
+			}
+			return
+		}
+
+		for _, sanction := range sanctions {
+			if _, err := s.lift(ctx, sanction, "", "expirou"); err != nil {
+				s.logger.Error("sanction_lift_failed", "id", sanction.ID, "error", err.Error())
+				return
+			}
+			s.logger.Info("sanction_expired", "id", sanction.ID, "user_id", sanction.UserID, "type", string(sanction.Type))
+		}
+
+		if len(sanctions) < sanctionSweepBatch {
+			return
+		}
+	}
+}
+
+// lift marca a sanção como levantada e, no fim de uma suspensão, reativa a conta se não houver
+// outra em vigor. Só quem a levantou de facto avisa o usuário, mesmo com várias instâncias.
+func (s *SanctionService) lift(ctx context.Context, sanction models.Sanction, liftedBy, reason string) (bool, error) {
+	lifted, err := s.sanctionRepo.Lift(ctx, sanction.ID, liftedBy, reason)
+	if err != nil || !lifted {
+		return false, err
+	}
+
+	after := sanction
+	after.Lifted = true
+	after.LiftedAt = time.Now()
+	after.LiftedBy = liftedBy
+	after.LiftReason = reason
+	s.audit.Record(ctx, models.AuditEntry{
+		AdminID:    liftedBy,
+		Action:     sanctionAuditAction(sanction.Type, true),
+		TargetType: models.AuditTargetUser,
+		TargetID:   sanction.UserID,
+		Reason:     reason,
+		Before:     snapshot(sanction),
+		After:      snapshot(after),
+	})
+
+	if sanction.Type == models.SanctionBan {
+		restriction, err := s.Restriction(ctx, sanction.UserID)
+		if err != nil {
+			return true, err
+		}
+		if restriction.Type == models.SanctionBan {
+			return true, nil
+		}
+		if err := s.userRepo.ToggleUserActive(ctx, sanction.UserID, true); err != nil {
+			return true, err
+		}
+	}
+
+	user, err := s.userRepo.FindByID(ctx, sanction.UserID)
+	if err == nil && user.ID != "" {
+		s.notify(ctx, user, liftedMessage(sanction))
+	}
+	return true, nil
+}
+
+// sanctionAuditAction devolve a ação registada no histórico quando a sanção é aplicada ou levantada
+func sanctionAuditAction(sanctionType models.SanctionType, lifted bool) models.AuditAction {
+	switch {
+	case sanctionType == models.SanctionBan && lifted:
+		return models.AuditUserUnban
+	case sanctionType == models.SanctionBan:
+		return models.AuditUserBan
+	case sanctionType == models.SanctionMute && lifted:
+		return models.AuditUserUnmute
+	case sanctionType == models.SanctionMute:
+		return models.AuditUserMute
+	default:
+		return models.AuditUserWarn
+	}
+}
+
+// notify envia o aviso por SMS, como as mensagens administrativas: só se o usuário as recebe
+// por SMS e, durante as horas de silêncio, só quando estas terminam. Uma falha não invalida a sanção.
+func (s *SanctionService) notify(ctx context.Context, user models.User, message string) {
+	if user.Contact == "" {
+		return
+	}
+
+	preferences, err := s.preferencesRepo.FindByUserID(ctx, user.ID)
+	if err != nil || preferences.UserID == "" {
+		preferences = models.DefaultNotificationPreferences(user.ID)
+	}
+	if !preferences.Channels(models.NotificationTypeAdmin).SMS {
+		return
+	}
+
+	sendAt := quietHoursEnd(preferences.QuietHours, time.Now())
+	if err := s.smsOutbox.EnqueueAt(ctx, user.Contact, message, sendAt); err != nil {
+		s.logger.Warn("sanction_sms_failed", "user_id", user.ID, "error", err)
+	}
+}
+
+// issuedMessage é o SMS enviado quando a sanção é aplicada
+func issuedMessage(sanction models.Sanction) string {
+	until := "por tempo indeterminado"
+	if !sanction.ExpiresAt.IsZero() {
+		until = "até " + sanction.ExpiresAt.In(sanctionTime).Format("02/01/2006 15:04")
+	}
+
+	switch sanction.Type {
+	case models.SanctionMute:
+		return "Anamalala: a sua conta está em modo só de leitura " + until + ". Motivo: " + sanction.Reason
+	case models.SanctionBan:
+		return "Anamalala: a sua conta foi suspensa " + until + ". Motivo: " + sanction.Reason
+	default:
+		return "Anamalala: recebeu uma advertência. Motivo: " + sanction.Reason + ". Novas violações podem levar à suspensão da conta."
+	}
+}
+
+// liftedMessage é o SMS enviado quando a sanção termina
+func liftedMessage(sanction models.Sanction) string {
+	if sanction.Type == models.SanctionBan {
+		return "Anamalala: a suspensão da sua conta terminou. Já pode voltar a entrar."
+	}
+	return "Anamalala: o modo só de leitura terminou. Já pode voltar a publicar e comentar."
+}
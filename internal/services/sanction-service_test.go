@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/anamalala/internal/models"
+	"github.com/anamalala/internal/repositories/interfaces"
+)
+
+// sanctionRepoStub guarda as sanções em vigor e conta as tentativas de as levantar.
+// Lift responde sempre que a sanção já foi levantada, para o teste parar antes das notificações.
+type sanctionRepoStub struct {
+	interfaces.SanctionRepository
+	sanctions map[string]models.Sanction
+	lifts     int
+}
+
+func (r *sanctionRepoStub) FindByID(ctx context.Context, id string) (models.Sanction, error) {
+	return r.sanctions[id], nil
+}
+
+func (r *sanctionRepoStub) ListInForce(ctx context.Context, userID string, now time.Time) (models.Sanctions, error) {
+	var sanctions models.Sanctions
+	for _, sanction := range r.sanctions {
+		if sanction.UserID == userID {
+			sanctions = append(sanctions, sanction)
+		}
+	}
+	return sanctions, nil
+}
+
+func (r *sanctionRepoStub) Lift(ctx context.Context, id, liftedBy, reason string) (bool, error) {
+	r.lifts++
+	return false, nil
+}
+
+func TestLiftStaffSanctions(t *testing.T) {
+	users := map[string]models.User{
+		"admin-1":     {ID: "admin-1", Role: models.RoleAdmin},
+		"moderator-1": {ID: "moderator-1", Role: models.RoleModerator},
+		"moderator-2": {ID: "moderator-2", Role: models.RoleModerator, Active: true},
+		"user-1":      {ID: "user-1", Role: models.RoleUser, Active: true},
+	}
+
+	tests := []struct {
+		name         string
+		adminID      string
+		userID       string
+		want         error
+		wantLiftBans error
+		wantLifts    int
+	}{
+		{"moderator lifting a user sanction", "moderator-1", "user-1", ErrSanctionNotInForce, ErrUserNotBanned, 1},
+		{"moderator lifting a staff sanction", "moderator-1", "moderator-2", ErrSanctionStaff, ErrSanctionStaff, 0},
+		{"admin lifting a staff sanction", "admin-1", "moderator-2", ErrSanctionNotInForce, ErrUserNotBanned, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &sanctionRepoStub{sanctions: map[string]models.Sanction{
+				"sanction-1": {ID: "sanction-1", UserID: tt.userID, Type: models.SanctionBan, IssuedBy: "admin-1"},
+			}}
+			service := SanctionService{sanctionRepo: repo, userRepo: userRepoStub{users: users}}
+
+			if _, err := service.Lift(context.Background(), tt.adminID, "sanction-1", "recurso aceite"); !errors.Is(err, tt.want) {
+				t.Errorf("Lift: expected %v, got %v", tt.want, err)
+			}
+			if repo.lifts != tt.wantLifts {
+				t.Errorf("Lift: expected %d lifts, got %d", tt.wantLifts, repo.lifts)
+			}
+
+			// LiftBans passa pela mesma verificação; sem nada levantado, a conta ativa não estava suspensa
+			repo.lifts = 0
+			if err := service.LiftBans(context.Background(), tt.adminID, tt.userID, "recurso aceite"); !errors.Is(err, tt.wantLiftBans) {
+				t.Errorf("LiftBans: expected %v, got %v", tt.wantLiftBans, err)
+			}
+			if repo.lifts != tt.wantLifts {
+				t.Errorf("LiftBans: expected %d lifts, got %d", tt.wantLifts, repo.lifts)
+			}
+		})
+	}
+}
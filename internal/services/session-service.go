@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/anamalala/internal/models"
+	"github.com/anamalala/internal/repositories/interfaces"
+)
+
+type SessionService struct {
+	sessionRepo interfaces.SessionRepository
+}
+
+func NewSessionService(sessionRepo interfaces.SessionRepository) SessionService {
+	return SessionService{
+		sessionRepo: sessionRepo,
+	}
+}
+
+// IsSessionActive verifica se a sessão existe, pertence ao usuário e não foi revogada nem expirou
+func (s *SessionService) IsSessionActive(ctx context.Context, sessionID, userID string) (bool, error) {
+	session, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	if session.ID == "" || session.UserID != userID || session.Revoked {
+		return false, nil
+	}
+
+	return time.Now().Before(session.ExpiresAt), nil
+}
+
+// GetUserSessions lista os dispositivos com sessão ativa, marcando a sessão atual
+func (s *SessionService) GetUserSessions(ctx context.Context, userID, currentSessionID string) (models.Sessions, error) {
+	sessions, err := s.sessionRepo.ListActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession termina a sessão de um dispositivo do usuário
+func (s *SessionService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	session, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	// Verificar se a sessão pertence ao usuário
+	if session.ID == "" || session.UserID != userID {
+		return errors.New("sessão não encontrada")
+	}
+
+	return s.sessionRepo.Revoke(ctx, sessionID)
+}
+
+// RevokeAllSessions termina as sessões de todos os dispositivos do usuário
+func (s *SessionService) RevokeAllSessions(ctx context.Context, userID string) (int, error) {
+	total, err := s.sessionRepo.RevokeAllByUserID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(total), nil
+}
@@ -0,0 +1,459 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/anamalala/internal/models"
+	"github.com/anamalala/internal/repositories/interfaces"
+	"github.com/anamalala/pkg/logger"
+	"github.com/anamalala/pkg/sms"
+)
+
+const (
+	// Intervalo entre verificações de campanhas agendadas
+	smsCampaignPollInterval = 15 * time.Second
+	// Tempo que uma instância tem para pôr as mensagens de uma campanha na fila;
+	// depois disso a campanha volta a poder ser despachada por outra instância
+	smsCampaignLease = 10 * time.Minute
+)
+
+var (
+	ErrCampaignNotFound        = errors.New("campanha não encontrada")
+	ErrCampaignNotCancellable  = errors.New("a campanha já foi enviada ou cancelada")
+	ErrCampaignNoRecipients    = errors.New("nenhum destinatário corresponde ao público da campanha")
+	ErrCampaignInvalidTemplate = errors.New("a mensagem contém variáveis desconhecidas")
+	ErrCampaignInvalidSchedule = errors.New("a data de envio deve estar no futuro")
+	ErrCampaignOutsideProvince = errors.New("só pode enviar campanhas para a sua província")
+	ErrCampaignNotOwner        = errors.New("só pode cancelar as campanhas que criou")
+)
+
+// Variáveis disponíveis nas mensagens, escritas como {{name}}
+var smsTemplatePlaceholder = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+var smsTemplateFields = map[string]func(user models.User) string{
+	"name":     func(user models.User) string { return user.Name },
+	"province": func(user models.User) string { return user.Province },
+}
+
+// smsRecipient é um destinatário da campanha e o momento a partir do qual pode receber o SMS
+type smsRecipient struct {
+	User   models.User
+	SendAt time.Time
+}
+
+// SMSCampaignService cria, agenda e despacha campanhas de SMS para a fila de envio
+type SMSCampaignService struct {
+	campaignRepo    interfaces.SMSCampaignRepository
+	outboxRepo      interfaces.SMSOutboxRepository
+	userRepo        interfaces.UserRepository
+	preferencesRepo interfaces.NotificationPreferencesRepository
+	smsOutbox       SMSOutboxService
+	audit           AuditService
+	logger          *logger.Logger
+	costPerSegment  float64
+	currency        string
+}
+
+func NewSMSCampaignService(
+	campaignRepo interfaces.SMSCampaignRepository,
+	outboxRepo interfaces.SMSOutboxRepository,
+	userRepo interfaces.UserRepository,
+	preferencesRepo interfaces.NotificationPreferencesRepository,
+	smsOutbox SMSOutboxService,
+	audit AuditService,
+	logger *logger.Logger,
+	costPerSegment float64,
+	currency string,
+) SMSCampaignService {
+	return SMSCampaignService{
+		campaignRepo:    campaignRepo,
+		outboxRepo:      outboxRepo,
+		userRepo:        userRepo,
+		preferencesRepo: preferencesRepo,
+		smsOutbox:       smsOutbox,
+		audit:           audit,
+		logger:          logger,
+		costPerSegment:  costPerSegment,
+		currency:        currency,
+	}
+}
+
+// Estimate calcula o número de destinatários e o custo previsto sem enviar nada.
+// O público é limitado da mesma forma que em CreateCampaign.
+func (s *SMSCampaignService) Estimate(ctx context.Context, adminID string, request models.SMSCampaignRequest) (models.SMSCampaignEstimate, error) {
+	if err := validateSMSTemplate(request.Message); err != nil {
+		return models.SMSCampaignEstimate{}, err
+	}
+
+	creator, err := s.userRepo.FindByID(ctx, adminID)
+	if err != nil {
+		return models.SMSCampaignEstimate{}, err
+	}
+	request.Audience, err = scopeAudience(creator, request.Audience)
+	if err != nil {
+		return models.SMSCampaignEstimate{}, err
+	}
+
+	users, err := s.recipients(ctx, request.Audience)
+	if err != nil {
+		return models.SMSCampaignEstimate{}, err
+	}
+
+	estimate := models.SMSCampaignEstimate{
+		Recipients: len(users),
+		Currency:   s.currency,
+	}
+	for i, recipient := range users {
+		text := renderSMSTemplate(request.Message, recipient.User)
+		if i == 0 {
+			estimate.Sample = text
+		}
+		estimate.Segments += sms.Segments(text)
+	}
+	estimate.EstimatedCost = float64(estimate.Segments) * s.costPerSegment
+
+	return estimate, nil
+}
+
+// CreateCampaign regista a campanha. Sem data de envio, as mensagens entram logo na fila;
+// caso contrário a campanha fica agendada até ser despachada pelo RunScheduler.
+func (s *SMSCampaignService) CreateCampaign(ctx context.Context, adminID string, request models.SMSCampaignRequest) (models.SMSCampaign, error) {
+	if err := validateSMSTemplate(request.Message); err != nil {
+		return models.SMSCampaign{}, err
+	}
+
+	creator, err := s.userRepo.FindByID(ctx, adminID)
+	if err != nil {
+		return models.SMSCampaign{}, err
+	}
+	request.Audience, err = scopeAudience(creator, request.Audience)
+	if err != nil {
+		return models.SMSCampaign{}, err
+	}
+
+	campaign := models.SMSCampaign{
+		CreatedBy: adminID,
+		Title:     request.Title,
+		Message:   request.Message,
+		Audience:  request.Audience,
+		State:     models.SMSCampaignDispatching,
+	}
+
+	if request.ScheduledAt != nil && !request.ScheduledAt.IsZero() {
+		if !request.ScheduledAt.After(time.Now()) {
+			return models.SMSCampaign{}, ErrCampaignInvalidSchedule
+		}
+		campaign.State = models.SMSCampaignScheduled
+		campaign.ScheduledAt = *request.ScheduledAt
+		campaign, err = s.campaignRepo.Create(ctx, campaign)
+		if err != nil {
+			return models.SMSCampaign{}, err
+		}
+		s.auditCampaign(ctx, campaign)
+		return campaign, nil
+	}
+
+	// Verificar o público antes de registar uma campanha para envio imediato
+	users, err := s.recipients(ctx, request.Audience)
+	if err != nil {
+		return models.SMSCampaign{}, err
+	}
+	if len(users) == 0 {
+		return models.SMSCampaign{}, ErrCampaignNoRecipients
+	}
+
+	// Se o processo parar a meio do envio, o agendador retoma a campanha quando a concessão expirar
+	campaign.LockedUntil = time.Now().Add(smsCampaignLease)
+	campaign, err = s.campaignRepo.Create(ctx, campaign)
+	if err != nil {
+		return models.SMSCampaign{}, err
+	}
+
+	if err := s.enqueue(ctx, campaign, users); err != nil {
+		if err := s.campaignRepo.MarkFailed(context.Background(), campaign.ID, err.Error()); err != nil {
+			s.logger.Error("sms_campaign_update_failed", "id", campaign.ID, "error", err.Error())
+		}
+		return models.SMSCampaign{}, err
+	}
+
+	campaign.State = models.SMSCampaignDispatched
+	campaign.Total = len(users)
+	s.auditCampaign(ctx, campaign)
+	return campaign, nil
+}
+
+// auditCampaign regista no histórico a campanha criada pelo administrador
+func (s *SMSCampaignService) auditCampaign(ctx context.Context, campaign models.SMSCampaign) {
+	s.audit.Record(ctx, models.AuditEntry{
+		AdminID:    campaign.CreatedBy,
+		Action:     models.AuditSMSSend,
+		TargetType: models.AuditTargetSMSCampaign,
+		TargetID:   campaign.ID,
+		After:      snapshot(campaign),
+	})
+}
+
+// CancelCampaign cancela uma campanha agendada que ainda não foi despachada
+func (s *SMSCampaignService) CancelCampaign(ctx context.Context, adminID, campaignID string) error {
+	campaign, err := s.campaignRepo.FindByID(ctx, campaignID)
+	if err != nil {
+		return err
+	}
+	if campaign.ID == "" {
+		return ErrCampaignNotFound
+	}
+
+	// Um coordenador provincial só cancela as suas campanhas
+	if campaign.CreatedBy != adminID {
+		admin, err := s.userRepo.FindByID(ctx, adminID)
+		if err != nil {
+			return err
+		}
+		if admin.Role.ProvinceScoped() {
+			return ErrCampaignNotOwner
+		}
+	}
+
+	cancelled, err := s.campaignRepo.Cancel(ctx, campaignID)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return ErrCampaignNotCancellable
+	}
+
+	after, err := s.campaignRepo.FindByID(ctx, campaignID)
+	if err != nil || after.ID == "" {
+		after = campaign
+		after.State = models.SMSCampaignCancelled
+	}
+	s.audit.Record(ctx, models.AuditEntry{
+		AdminID:    adminID,
+		Action:     models.AuditSMSCancel,
+		TargetType: models.AuditTargetSMSCampaign,
+		TargetID:   campaignID,
+		Before:     snapshot(campaign),
+		After:      snapshot(after),
+	})
+	return nil
+}
+
+// scopeAudience limita o público das campanhas de um coordenador provincial à sua província.
+// Os critérios do público são combinados, por isso os restantes também ficam limitados.
+func scopeAudience(creator models.User, audience models.SMSAudience) (models.SMSAudience, error) {
+	if !creator.Role.ProvinceScoped() {
+		return audience, nil
+	}
+	if creator.Province == "" {
+		return models.SMSAudience{}, ErrCampaignOutsideProvince
+	}
+
+	for _, province := range audience.Provinces {
+		if !strings.EqualFold(province, creator.Province) {
+			return models.SMSAudience{}, ErrCampaignOutsideProvince
+		}
+	}
+	audience.Provinces = []string{creator.Province}
+	return audience, nil
+}
+
+// viewerProvince devolve a província a que um coordenador provincial está limitado,
+// ou vazio quando o administrador vê as campanhas de todas as províncias
+func (s *SMSCampaignService) viewerProvince(ctx context.Context, adminID string) (string, error) {
+	admin, err := s.userRepo.FindByID(ctx, adminID)
+	if err != nil {
+		return "", err
+	}
+	if !admin.Role.ProvinceScoped() {
+		return "", nil
+	}
+	if admin.Province == "" {
+		return "", ErrCampaignOutsideProvince
+	}
+	return admin.Province, nil
+}
+
+// GetCampaignStatus devolve a campanha com o número de mensagens em cada estado.
+// Um coordenador provincial só vê as campanhas dirigidas à sua província.
+func (s *SMSCampaignService) GetCampaignStatus(ctx context.Context, adminID, campaignID string) (models.SMSCampaignStatus, error) {
+	province, err := s.viewerProvince(ctx, adminID)
+	if err != nil {
+		return models.SMSCampaignStatus{}, err
+	}
+
+	campaign, err := s.campaignRepo.FindByID(ctx, campaignID)
+	if err != nil {
+		return models.SMSCampaignStatus{}, err
+	}
+	if campaign.ID == "" {
+		return models.SMSCampaignStatus{}, ErrCampaignNotFound
+	}
+	if province != "" && !slices.Equal(campaign.Audience.Provinces, []string{province}) {
+		return models.SMSCampaignStatus{}, ErrCampaignOutsideProvince
+	}
+
+	counts, err := s.outboxRepo.CountByCampaign(ctx, campaignID)
+	if err != nil {
+		return models.SMSCampaignStatus{}, err
+	}
+
+	return models.SMSCampaignStatus{Campaign: campaign, Counts: counts}, nil
+}
+
+// ListCampaigns lista as campanhas, das mais recentes para as mais antigas.
+// Um coordenador provincial só vê as campanhas dirigidas à sua província.
+func (s *SMSCampaignService) ListCampaigns(ctx context.Context, adminID string, page, limit int) (models.SMSCampaigns, int, error) {
+	province, err := s.viewerProvince(ctx, adminID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	campaigns, total, err := s.campaignRepo.List(ctx, province, int64(page), int64(limit))
+	if err != nil {
+		return nil, 0, err
+	}
+	return campaigns, int(total), nil
+}
+
+// RunScheduler despacha as campanhas agendadas quando chega a hora, até o contexto ser cancelado
+func (s *SMSCampaignService) RunScheduler(ctx context.Context) {
+	ticker := time.NewTicker(smsCampaignPollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.dispatchDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *SMSCampaignService) dispatchDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		campaign, err := s.campaignRepo.ClaimDue(ctx, smsCampaignLease)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Error("sms_campaign_claim_failed", "error", err.Error())
+			}
+			return
+		}
+		if campaign.ID == "" {
+			return
+		}
+
+		// O público é resolvido no momento do envio para incluir usuários registados entretanto
+		users, err := s.recipients(ctx, campaign.Audience)
+		if err == nil && len(users) == 0 {
+			err = ErrCampaignNoRecipients
+		}
+		if err == nil {
+			err = s.enqueue(ctx, campaign, users)
+		}
+		if err != nil {
+			s.logger.Error("sms_campaign_dispatch_failed", "id", campaign.ID, "error", err.Error())
+			if err := s.campaignRepo.MarkFailed(context.Background(), campaign.ID, err.Error()); err != nil {
+				s.logger.Error("sms_campaign_update_failed", "id", campaign.ID, "error", err.Error())
+			}
+			continue
+		}
+
+		s.logger.Info("sms_campaign_dispatched", "id", campaign.ID, "recipients", len(users))
+	}
+}
+
+// enqueue coloca uma mensagem personalizada por destinatário na fila e marca a campanha como despachada.
+// A fila guarda uma só mensagem por campanha e destinatário, por isso repetir o envio é seguro.
+func (s *SMSCampaignService) enqueue(ctx context.Context, campaign models.SMSCampaign, recipients []smsRecipient) error {
+	messages := make(models.SMSMessages, len(recipients))
+	for i, recipient := range recipients {
+		messages[i] = models.SMSMessage{
+			CampaignID:    campaign.ID,
+			Recipient:     recipient.User.Contact,
+			Message:       renderSMSTemplate(campaign.Message, recipient.User),
+			NextAttemptAt: recipient.SendAt,
+		}
+	}
+
+	if err := s.smsOutbox.EnqueueMessages(ctx, messages); err != nil {
+		return err
+	}
+
+	return s.campaignRepo.MarkDispatched(ctx, campaign.ID, len(messages))
+}
+
+// recipients obtém os usuários do público que aceitam SMS informativos, sem contactos vazios ou repetidos.
+// Quem está nas horas de silêncio recebe a mensagem quando o período terminar.
+func (s *SMSCampaignService) recipients(ctx context.Context, audience models.SMSAudience) ([]smsRecipient, error) {
+	users, err := s.userRepo.FindByAudience(ctx, audience)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]string, len(users))
+	for i, user := range users {
+		userIDs[i] = user.ID
+	}
+	preferences, err := s.preferencesRepo.FindByUserIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	seen := make(map[string]bool, len(users))
+	result := make([]smsRecipient, 0, len(users))
+	for _, user := range users {
+		contact := strings.TrimSpace(user.Contact)
+		if contact == "" || seen[contact] {
+			continue
+		}
+
+		userPreferences, ok := preferences[user.ID]
+		if !ok {
+			userPreferences = models.DefaultNotificationPreferences(user.ID)
+		}
+		if !userPreferences.Channels(models.NotificationTypeInfo).SMS {
+			continue
+		}
+
+		seen[contact] = true
+		user.Contact = contact
+		result = append(result, smsRecipient{
+			User:   user,
+			SendAt: quietHoursEnd(userPreferences.QuietHours, now),
+		})
+	}
+
+	return result, nil
+}
+
+// validateSMSTemplate garante que a mensagem só usa variáveis conhecidas
+func validateSMSTemplate(message string) error {
+	if strings.TrimSpace(message) == "" {
+		return errors.New("a mensagem não pode estar vazia")
+	}
+	for _, match := range smsTemplatePlaceholder.FindAllStringSubmatch(message, -1) {
+		if _, ok := smsTemplateFields[strings.ToLower(match[1])]; !ok {
+			return ErrCampaignInvalidTemplate
+		}
+	}
+	return nil
+}
+
+// renderSMSTemplate substitui as variáveis pelos dados do destinatário
+func renderSMSTemplate(message string, user models.User) string {
+	return smsTemplatePlaceholder.ReplaceAllStringFunc(message, func(placeholder string) string {
+		name := smsTemplatePlaceholder.FindStringSubmatch(placeholder)[1]
+		if field, ok := smsTemplateFields[strings.ToLower(name)]; ok {
+			return field(user)
+		}
+		return placeholder
+	})
+}
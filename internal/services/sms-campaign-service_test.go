@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/anamalala/internal/models"
+	"github.com/anamalala/internal/repositories/interfaces"
+)
+
+// audienceRepoStub devolve os usuários das províncias pedidas e regista o público recebido
+type audienceRepoStub struct {
+	interfaces.UserRepository
+	users    models.Users
+	audience models.SMSAudience
+}
+
+func (r *audienceRepoStub) FindByID(ctx context.Context, id string) (models.User, error) {
+	for _, user := range r.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return models.User{}, nil
+}
+
+func (r *audienceRepoStub) FindByAudience(ctx context.Context, audience models.SMSAudience) (models.Users, error) {
+	r.audience = audience
+
+	var users models.Users
+	for _, user := range r.users {
+		if len(audience.Provinces) == 0 || slices.Contains(audience.Provinces, user.Province) {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+// campaignPreferencesStub simula usuários que nunca alteraram as preferências
+type campaignPreferencesStub struct {
+	interfaces.NotificationPreferencesRepository
+}
+
+func (r campaignPreferencesStub) FindByUserIDs(ctx context.Context, userIDs []string) (map[string]models.NotificationPreferences, error) {
+	return map[string]models.NotificationPreferences{}, nil
+}
+
+func TestScopeAudience(t *testing.T) {
+	coordinator := models.User{ID: "coordinator-1", Role: models.RoleProvincialCoordinator, Province: "Gaza"}
+	admin := models.User{ID: "admin-1", Role: models.RoleAdmin, Province: "Gaza"}
+
+	tests := []struct {
+		name    string
+		creator models.User
+		in      models.SMSAudience
+		want    []string
+		wantErr error
+	}{
+		{"admin keeps every province", admin, models.SMSAudience{}, nil, nil},
+		{"admin keeps the chosen provinces", admin, models.SMSAudience{Provinces: []string{"Maputo", "Sofala"}}, []string{"Maputo", "Sofala"}, nil},
+		{"coordinator limited to the own province", coordinator, models.SMSAudience{}, []string{"Gaza"}, nil},
+		{"coordinator own province in other case", coordinator, models.SMSAudience{Provinces: []string{"gaza"}}, []string{"Gaza"}, nil},
+		{"coordinator other province", coordinator, models.SMSAudience{Provinces: []string{"Gaza", "Maputo"}}, nil, ErrCampaignOutsideProvince},
+		{"coordinator without province", models.User{Role: models.RoleProvincialCoordinator}, models.SMSAudience{}, nil, ErrCampaignOutsideProvince},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := scopeAudience(tt.creator, tt.in)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if !slices.Equal(got.Provinces, tt.want) {
+				t.Errorf("expected provinces %v, got %v", tt.want, got.Provinces)
+			}
+		})
+	}
+}
+
+func TestScopeAudienceKeepsOtherCriteria(t *testing.T) {
+	active := true
+	coordinator := models.User{Role: models.RoleProvincialCoordinator, Province: "Gaza"}
+	audience := models.SMSAudience{Roles: []models.Role{models.RoleUser}, Active: &active, UserIDs: []string{"user-1"}}
+
+	got, err := scopeAudience(coordinator, audience)
+	if err != nil {
+		t.Fatalf("scopeAudience: %v", err)
+	}
+	if !slices.Equal(got.Roles, audience.Roles) || got.Active != &active || !slices.Equal(got.UserIDs, audience.UserIDs) {
+		t.Errorf("expected the other criteria to be kept, got %+v", got)
+	}
+}
+
+func TestEstimateScopedByAdmin(t *testing.T) {
+	users := models.Users{
+		{ID: "admin-1", Role: models.RoleAdmin, Name: "Admin", Contact: "840000001"},
+		{ID: "coordinator-1", Role: models.RoleProvincialCoordinator, Province: "Gaza", Contact: "840000002"},
+		{ID: "user-1", Name: "Ana", Province: "Gaza", Contact: "841111111"},
+		{ID: "user-2", Name: "Rui", Province: "Maputo", Contact: "842222222"},
+		{ID: "user-3", Name: "Eva", Province: "Maputo", Contact: "843333333"},
+	}
+
+	tests := []struct {
+		name           string
+		adminID        string
+		provinces      []string
+		wantErr        error
+		wantProvinces  []string
+		wantRecipients int
+	}{
+		{"admin sees every province", "admin-1", nil, nil, nil, 5},
+		{"admin chooses a province", "admin-1", []string{"Maputo"}, nil, []string{"Maputo"}, 2},
+		{"coordinator limited to the own province", "coordinator-1", nil, nil, []string{"Gaza"}, 2},
+		{"coordinator asking for another province", "coordinator-1", []string{"Maputo"}, ErrCampaignOutsideProvince, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := &audienceRepoStub{users: users}
+			service := NewSMSCampaignService(nil, nil, userRepo, campaignPreferencesStub{}, SMSOutboxService{}, AuditService{}, nil, 1.5, "MZN")
+
+			request := models.SMSCampaignRequest{
+				Message:  "Olá {{name}}, reunião amanhã",
+				Audience: models.SMSAudience{Provinces: tt.provinces},
+			}
+			estimate, err := service.Estimate(context.Background(), tt.adminID, request)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil {
+				if userRepo.audience.Provinces != nil {
+					t.Errorf("expected no recipients to be looked up, got %+v", userRepo.audience)
+				}
+				return
+			}
+
+			if !slices.Equal(userRepo.audience.Provinces, tt.wantProvinces) {
+				t.Errorf("expected provinces %v, got %v", tt.wantProvinces, userRepo.audience.Provinces)
+			}
+			if estimate.Recipients != tt.wantRecipients {
+				t.Errorf("expected %d recipients, got %d", tt.wantRecipients, estimate.Recipients)
+			}
+			if estimate.EstimatedCost != float64(estimate.Segments)*1.5 || estimate.Currency != "MZN" {
+				t.Errorf("expected the cost of %d segments in MZN, got %+v", estimate.Segments, estimate)
+			}
+			if !strings.HasPrefix(estimate.Sample, "Olá ") {
+				t.Errorf("expected a rendered sample, got %q", estimate.Sample)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/anamalala/internal/models"
+	"github.com/anamalala/internal/repositories/interfaces"
+	"github.com/anamalala/pkg/logger"
+	"github.com/anamalala/pkg/sms"
+)
+
+const (
+	// Tempo durante o qual uma mensagem fica reservada para o worker que a retirou da fila
+	smsOutboxLease = 2 * time.Minute
+	// Intervalo entre consultas quando a fila está vazia
+	smsOutboxPollInterval = 2 * time.Second
+	// Primeiro intervalo de espera entre tentativas, duplicado a cada falha
+	smsOutboxBaseBackoff = 30 * time.Second
+	smsOutboxMaxBackoff  = time.Hour
+)
+
+// SMSOutboxService guarda as mensagens numa fila persistente e envia-as em segundo plano
+type SMSOutboxService struct {
+	outboxRepo  interfaces.SMSOutboxRepository
+	smsService  *sms.Service
+	logger      *logger.Logger
+	maxAttempts int
+}
+
+func NewSMSOutboxService(
+	outboxRepo interfaces.SMSOutboxRepository,
+	smsService *sms.Service,
+	logger *logger.Logger,
+	maxAttempts int,
+) SMSOutboxService {
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+	return SMSOutboxService{
+		outboxRepo:  outboxRepo,
+		smsService:  smsService,
+		logger:      logger,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// EnqueueMessages coloca um conjunto de mensagens já preparadas na fila
+func (s *SMSOutboxService) EnqueueMessages(ctx context.Context, messages models.SMSMessages) error {
+	return s.outboxRepo.Enqueue(ctx, messages)
+}
+
+// Enqueue coloca uma mensagem avulsa na fila para envio imediato
+func (s *SMSOutboxService) Enqueue(ctx context.Context, recipient, message string) error {
+	return s.EnqueueAt(ctx, recipient, message, time.Time{})
+}
+
+// EnqueueAt coloca uma mensagem avulsa na fila para ser enviada a partir de sendAt
+func (s *SMSOutboxService) EnqueueAt(ctx context.Context, recipient, message string, sendAt time.Time) error {
+	if recipient == "" {
+		return errors.New("nenhum contato fornecido")
+	}
+	return s.outboxRepo.Enqueue(ctx, models.SMSMessages{{Recipient: recipient, Message: message, NextAttemptAt: sendAt}})
+}
+
+// Run inicia os workers e bloqueia até o contexto ser cancelado e todos terminarem
+func (s *SMSOutboxService) Run(ctx context.Context, workers int) {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *SMSOutboxService) work(ctx context.Context) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		message, err := s.outboxRepo.ClaimNext(ctx, smsOutboxLease)
+		if err != nil && ctx.Err() == nil {
+			s.logger.Error("sms_outbox_claim_failed", "error", err.Error())
+		}
+
+		if err != nil || message.ID == "" {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(smsOutboxPollInterval):
+			}
+			continue
+		}
+
+		s.deliver(ctx, message)
+	}
+}
+
+// deliver envia uma mensagem e regista o resultado.
+// O envio é interrompido quando workerCtx é cancelado; o resultado é gravado com um
+// contexto próprio para que fique registado mesmo durante o encerramento.
+func (s *SMSOutboxService) deliver(workerCtx context.Context, message models.SMSMessage) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	providerMessageID, err := s.smsService.SendMessage(workerCtx, message.Recipient, message.Message)
+	if err == nil {
+		if err := s.outboxRepo.MarkSent(ctx, message.ID, providerMessageID); err != nil {
+			s.logger.Error("sms_outbox_update_failed", "id", message.ID, "error", err.Error())
+		}
+		return
+	}
+
+	attempts := message.Attempts + 1
+	if isPermanentSMSError(err) || attempts >= s.maxAttempts {
+		s.logger.Warn("sms_outbox_failed", "id", message.ID, "attempts", attempts, "error", err.Error())
+		if err := s.outboxRepo.MarkFailed(ctx, message.ID, err.Error()); err != nil {
+			s.logger.Error("sms_outbox_update_failed", "id", message.ID, "error", err.Error())
+		}
+		return
+	}
+
+	nextAttemptAt := time.Now().Add(smsBackoff(attempts))
+	if err := s.outboxRepo.MarkRetry(ctx, message.ID, nextAttemptAt, err.Error()); err != nil {
+		s.logger.Error("sms_outbox_update_failed", "id", message.ID, "error", err.Error())
+	}
+}
+
+// HandleDeliveryReport aplica o relatório de entrega enviado pelo provedor
+func (s *SMSOutboxService) HandleDeliveryReport(ctx context.Context, form url.Values) error {
+	report, err := s.smsService.ParseDeliveryReport(form)
+	if err != nil {
+		return err
+	}
+
+	var status models.SMSStatus
+	switch report.Status {
+	case sms.DeliveryStatusDelivered:
+		status = models.SMSStatusDelivered
+	case sms.DeliveryStatusFailed:
+		status = models.SMSStatusFailed
+	default:
+		// Estados intermédios não alteram a mensagem, que já está como enviada
+		return nil
+	}
+
+	return s.outboxRepo.UpdateDeliveryStatus(ctx, report.MessageID, status, report.Reason)
+}
+
+// isPermanentSMSError indica se o provedor recusou a mensagem de forma definitiva.
+// Erros sem classificação do provedor são tratados como temporários.
+func isPermanentSMSError(err error) bool {
+	var providerErr *sms.ProviderError
+	return errors.As(err, &providerErr) && !providerErr.Temporary
+}
+
+// smsBackoff calcula a espera antes da próxima tentativa
+func smsBackoff(attempts int) time.Duration {
+	backoff := smsOutboxBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= smsOutboxMaxBackoff {
+			return smsOutboxMaxBackoff
+		}
+	}
+	return backoff
+}
@@ -28,9 +28,8 @@ func (s *UserService) GetUserByID(ctx context.Context, id string) (models.User,
 		return models.User{}, err
 	}
 	
-	// Não retornar a senha e o código de reset
+	// Não retornar a senha
 	user.Password = ""
-	user.ResetCode = ""
 	
 	return user, nil
 }
@@ -42,9 +41,8 @@ func (s *UserService) GetUserByContact(ctx context.Context, contact string) (mod
 		return models.User{}, err
 	}
 	
-	// Não retornar a senha e o código de reset
+	// Não retornar a senha
 	user.Password = ""
-	user.ResetCode = ""
 	return user, nil
 }
 
@@ -92,9 +90,8 @@ func (s *UserService) UpdateUser(ctx context.Context, id string, updateData mode
 		return models.User{}, err
 	}
 	
-	// Não retornar a senha e o código de reset
+	// Não retornar a senha
 	user.Password = ""
-	user.ResetCode = ""
 	
 	return user, nil
 }
@@ -108,7 +105,6 @@ func (s *UserService) GetAllUsers(ctx context.Context, page, limit int) (models.
 	// Remover informações sensíveis
 	for _, user := range users {
 		user.Password = ""
-		user.ResetCode = ""
 	}
 	
 	return users, int(total), nil
@@ -123,7 +119,6 @@ func (s *UserService) GetUsersByProvince(ctx context.Context, province string, p
 	// Remover informações sensíveis
 	for i, user := range users {
 		user.Password = ""
-		user.ResetCode = ""
 		users[i] = user
 	}
 	
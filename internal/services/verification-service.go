@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/anamalala/internal/models"
+	"github.com/anamalala/internal/repositories/interfaces"
+	"github.com/anamalala/internal/utils"
+	"github.com/anamalala/pkg/sms"
+)
+
+var (
+	ErrCodeInvalid         = errors.New("código de verificação inválido")
+	ErrCodeExpired         = errors.New("código de verificação expirado")
+	ErrCodeTooManyAttempts = errors.New("número máximo de tentativas atingido, peça um novo código")
+	ErrCodeResendCooldown  = errors.New("aguarde antes de pedir um novo código")
+	ErrCodeLocked          = errors.New("demasiadas tentativas falhadas, tente mais tarde")
+)
+
+// VerificationService envia e valida códigos de uso único por SMS
+type VerificationService struct {
+	verificationRepo interfaces.VerificationRepository
+	smsService       *sms.Service
+	expiry           time.Duration
+	maxAttempts      int
+	resendCooldown   time.Duration
+	lockout          time.Duration
+}
+
+func NewVerificationService(
+	verificationRepo interfaces.VerificationRepository,
+	smsService *sms.Service,
+	expiry time.Duration,
+	maxAttempts int,
+	resendCooldown time.Duration,
+	lockout time.Duration,
+) VerificationService {
+	return VerificationService{
+		verificationRepo: verificationRepo,
+		smsService:       smsService,
+		expiry:           expiry,
+		maxAttempts:      maxAttempts,
+		resendCooldown:   resendCooldown,
+		lockout:          lockout,
+	}
+}
+
+// SendCode gera um novo código para o contacto e envia-o por SMS.
+// Respeita o intervalo mínimo entre envios para o mesmo contacto.
+// As tentativas falhadas continuam a contar entre códigos, e só voltam a zero
+// depois de terminado um bloqueio.
+func (s *VerificationService) SendCode(ctx context.Context, contact string, purpose models.VerificationPurpose) error {
+	current, err := s.verificationRepo.FindByContact(ctx, contact, purpose)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if current.ID != "" && now.Before(current.LockedUntil) {
+		return ErrCodeLocked
+	}
+	if current.ID != "" && now.Before(current.LastSentAt.Add(s.resendCooldown)) {
+		return ErrCodeResendCooldown
+	}
+
+	attempts := current.Attempts
+	if !current.LockedUntil.IsZero() {
+		attempts = 0
+	}
+	// Tentativas esgotadas sem bloqueio (por exemplo, a última com o código expirado)
+	if current.ID != "" && attempts >= s.maxAttempts {
+		if err := s.verificationRepo.Lock(ctx, current.ID, now.Add(s.lockout)); err != nil {
+			return err
+		}
+		return ErrCodeLocked
+	}
+
+	code := utils.GenerateResetCode()
+	codeHash, err := utils.HashCode(code)
+	if err != nil {
+		return err
+	}
+
+	err = s.verificationRepo.Save(ctx, models.VerificationCode{
+		Contact:    contact,
+		Purpose:    purpose,
+		CodeHash:   codeHash,
+		Attempts:   attempts,
+		SendCount:  current.SendCount + 1,
+		ExpiresAt:  now.Add(s.expiry),
+		LastSentAt: now,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.smsService.Send(ctx, contact, s.message(code, purpose))
+}
+
+// VerifyCode valida o código apresentado e invalida-o em caso de sucesso
+func (s *VerificationService) VerifyCode(ctx context.Context, contact string, purpose models.VerificationPurpose, code string) error {
+	current, err := s.checkCode(ctx, contact, purpose, code)
+	if err != nil {
+		return err
+	}
+
+	return s.verificationRepo.Delete(ctx, current.ID)
+}
+
+// CheckCode valida o código apresentado sem o invalidar.
+// Cada verificação conta como tentativa.
+func (s *VerificationService) CheckCode(ctx context.Context, contact string, purpose models.VerificationPurpose, code string) error {
+	_, err := s.checkCode(ctx, contact, purpose, code)
+	return err
+}
+
+func (s *VerificationService) checkCode(ctx context.Context, contact string, purpose models.VerificationPurpose, code string) (models.VerificationCode, error) {
+	current, err := s.verificationRepo.FindByContact(ctx, contact, purpose)
+	if err != nil {
+		return models.VerificationCode{}, err
+	}
+	if current.ID == "" || current.CodeHash == "" {
+		return models.VerificationCode{}, ErrCodeInvalid
+	}
+	if time.Now().Before(current.LockedUntil) {
+		return models.VerificationCode{}, ErrCodeLocked
+	}
+
+	// Contar a tentativa antes de comparar, para que pedidos simultâneos não excedam o limite
+	allowed, err := s.verificationRepo.RegisterAttempt(ctx, current.ID, s.maxAttempts)
+	if err != nil {
+		return models.VerificationCode{}, err
+	}
+	if !allowed {
+		return models.VerificationCode{}, ErrCodeTooManyAttempts
+	}
+
+	if time.Now().After(current.ExpiresAt) {
+		return models.VerificationCode{}, ErrCodeExpired
+	}
+
+	if !utils.CheckPasswordHash(code, current.CodeHash) {
+		// Última tentativa falhada: invalidar o código e bloquear o contacto
+		if current.Attempts+1 >= s.maxAttempts {
+			if err := s.verificationRepo.Lock(ctx, current.ID, time.Now().Add(s.lockout)); err != nil {
+				return models.VerificationCode{}, err
+			}
+			return models.VerificationCode{}, ErrCodeLocked
+		}
+		return models.VerificationCode{}, ErrCodeInvalid
+	}
+
+	return current, nil
+}
+
+// message monta o texto do SMS de acordo com a finalidade do código
+func (s *VerificationService) message(code string, purpose models.VerificationPurpose) string {
+	minutes := int(s.expiry.Minutes())
+	switch purpose {
+	case models.VerificationPurposeContact:
+		return fmt.Sprintf("ANAMALALA: o seu código de verificação é %s. Expira em %d minutos.", code, minutes)
+	case models.VerificationPurposePasswordReset:
+		return fmt.Sprintf("ANAMALALA: o seu código para redefinir a senha é %s. Expira em %d minutos. Se não pediu, ignore esta mensagem.", code, minutes)
+	default:
+		return fmt.Sprintf("ANAMALALA: o seu código é %s. Expira em %d minutos.", code, minutes)
+	}
+}
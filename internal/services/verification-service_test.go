@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/anamalala/internal/models"
+	"github.com/anamalala/internal/repositories/interfaces"
+	"github.com/anamalala/internal/utils"
+	"github.com/anamalala/pkg/logger"
+	"github.com/anamalala/pkg/sms"
+)
+
+// verificationRepoStub guarda um código por contacto, como o repositório MongoDB
+type verificationRepoStub struct {
+	interfaces.VerificationRepository
+	codes map[string]models.VerificationCode
+}
+
+func (r *verificationRepoStub) Save(ctx context.Context, code models.VerificationCode) error {
+	if current, ok := r.codes[code.Contact]; ok {
+		code.ID = current.ID
+	} else {
+		code.ID = "code-" + code.Contact
+	}
+	r.codes[code.Contact] = code
+	return nil
+}
+
+func (r *verificationRepoStub) FindByContact(ctx context.Context, contact string, purpose models.VerificationPurpose) (models.VerificationCode, error) {
+	return r.codes[contact], nil
+}
+
+func (r *verificationRepoStub) RegisterAttempt(ctx context.Context, id string, maxAttempts int) (bool, error) {
+	for contact, code := range r.codes {
+		if code.ID == id && code.Attempts < maxAttempts {
+			code.Attempts++
+			r.codes[contact] = code
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *verificationRepoStub) Lock(ctx context.Context, id string, until time.Time) error {
+	for contact, code := range r.codes {
+		if code.ID == id {
+			code.CodeHash = ""
+			code.LockedUntil = until
+			r.codes[contact] = code
+		}
+	}
+	return nil
+}
+
+func (r *verificationRepoStub) Delete(ctx context.Context, id string) error {
+	for contact, code := range r.codes {
+		if code.ID == id {
+			delete(r.codes, contact)
+		}
+	}
+	return nil
+}
+
+const (
+	testOTPMaxAttempts = 3
+	testOTPLockout     = 30 * time.Minute
+)
+
+// newVerificationTestService cria um VerificationService com o provedor de SMS mock
+func newVerificationTestService(t *testing.T, codes ...models.VerificationCode) (VerificationService, *verificationRepoStub) {
+	t.Helper()
+
+	smsService, err := sms.NewService(&sms.SMSConfig{ProviderType: "mock"}, logger.NewLogger("production"))
+	if err != nil {
+		t.Fatalf("sms.NewService: %v", err)
+	}
+
+	repo := &verificationRepoStub{codes: map[string]models.VerificationCode{}}
+	for _, code := range codes {
+		code.ID = "code-" + code.Contact
+		repo.codes[code.Contact] = code
+	}
+	return NewVerificationService(repo, smsService, 10*time.Minute, testOTPMaxAttempts, time.Minute, testOTPLockout), repo
+}
+
+// hashCode devolve o hash de um código, como é guardado
+func hashCode(t *testing.T, code string) string {
+	t.Helper()
+
+	hash, err := utils.HashCode(code)
+	if err != nil {
+		t.Fatalf("HashCode: %v", err)
+	}
+	return hash
+}
+
+func TestVerifyCode(t *testing.T) {
+	const contact = "841234567"
+	valid := models.VerificationCode{
+		Contact:   contact,
+		Purpose:   models.VerificationPurposeContact,
+		CodeHash:  hashCode(t, "123456"),
+		ExpiresAt: time.Now().Add(10 * time.Minute),
+	}
+
+	withAttempts := func(attempts int) models.VerificationCode {
+		code := valid
+		code.Attempts = attempts
+		return code
+	}
+	expired := valid
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	locked := valid
+	locked.LockedUntil = time.Now().Add(time.Minute)
+
+	tests := []struct {
+		name        string
+		stored      []models.VerificationCode
+		code        string
+		want        error
+		wantLocking bool
+	}{
+		{"correct code", []models.VerificationCode{valid}, "123456", nil, false},
+		{"wrong code", []models.VerificationCode{valid}, "000000", ErrCodeInvalid, false},
+		{"last wrong attempt locks", []models.VerificationCode{withAttempts(testOTPMaxAttempts - 1)}, "000000", ErrCodeLocked, true},
+		{"correct code after the attempts ran out", []models.VerificationCode{withAttempts(testOTPMaxAttempts)}, "123456", ErrCodeTooManyAttempts, false},
+		{"correct code while locked", []models.VerificationCode{locked}, "123456", ErrCodeLocked, false},
+		{"expired code", []models.VerificationCode{expired}, "123456", ErrCodeExpired, false},
+		{"unknown contact", nil, "123456", ErrCodeInvalid, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo := newVerificationTestService(t, tt.stored...)
+
+			err := service.VerifyCode(context.Background(), contact, models.VerificationPurposeContact, tt.code)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+
+			stored, ok := repo.codes[contact]
+			if tt.want == nil && ok {
+				t.Errorf("expected the used code to be deleted")
+			}
+			if tt.wantLocking {
+				if !time.Now().Before(stored.LockedUntil) {
+					t.Errorf("expected the contact to be locked, got locked until %v", stored.LockedUntil)
+				}
+				if stored.CodeHash != "" {
+					t.Errorf("expected the code to be invalidated by the lock")
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyCodeLockout(t *testing.T) {
+	const contact = "841234567"
+	service, repo := newVerificationTestService(t, models.VerificationCode{
+		Contact:   contact,
+		Purpose:   models.VerificationPurposePasswordReset,
+		CodeHash:  hashCode(t, "123456"),
+		ExpiresAt: time.Now().Add(10 * time.Minute),
+	})
+	ctx := context.Background()
+
+	for i := 1; i < testOTPMaxAttempts; i++ {
+		if err := service.CheckCode(ctx, contact, models.VerificationPurposePasswordReset, "000000"); !errors.Is(err, ErrCodeInvalid) {
+			t.Fatalf("attempt %d: expected ErrCodeInvalid, got %v", i, err)
+		}
+	}
+	if err := service.CheckCode(ctx, contact, models.VerificationPurposePasswordReset, "000000"); !errors.Is(err, ErrCodeLocked) {
+		t.Fatalf("expected the last attempt to lock the contact, got %v", err)
+	}
+
+	// Nem o código certo nem um código novo são aceites durante o bloqueio
+	if err := service.CheckCode(ctx, contact, models.VerificationPurposePasswordReset, "123456"); err == nil {
+		t.Errorf("expected the correct code to be refused while locked")
+	}
+	if err := service.SendCode(ctx, contact, models.VerificationPurposePasswordReset); !errors.Is(err, ErrCodeLocked) {
+		t.Errorf("expected no new code while locked, got %v", err)
+	}
+	if got := repo.codes[contact].Attempts; got != testOTPMaxAttempts {
+		t.Errorf("expected %d attempts, got %d", testOTPMaxAttempts, got)
+	}
+}
+
+func TestSendCode(t *testing.T) {
+	const contact = "841234567"
+	now := time.Now()
+
+	tests := []struct {
+		name         string
+		stored       []models.VerificationCode
+		want         error
+		wantAttempts int
+		wantLocked   bool
+	}{
+		{"first code", nil, nil, 0, false},
+		{"within the resend cooldown", []models.VerificationCode{{Contact: contact, LastSentAt: now}}, ErrCodeResendCooldown, 0, false},
+		{"failed attempts carry over", []models.VerificationCode{{Contact: contact, Attempts: 2, LastSentAt: now.Add(-time.Hour)}}, nil, 2, false},
+		{"attempts ran out", []models.VerificationCode{{Contact: contact, Attempts: testOTPMaxAttempts, LastSentAt: now.Add(-time.Hour)}}, ErrCodeLocked, testOTPMaxAttempts, true},
+		{"locked", []models.VerificationCode{{Contact: contact, Attempts: testOTPMaxAttempts, LockedUntil: now.Add(time.Minute)}}, ErrCodeLocked, testOTPMaxAttempts, true},
+		{"lockout ended", []models.VerificationCode{{Contact: contact, Attempts: testOTPMaxAttempts, LockedUntil: now.Add(-time.Minute), LastSentAt: now.Add(-time.Hour)}}, nil, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo := newVerificationTestService(t, tt.stored...)
+
+			err := service.SendCode(context.Background(), contact, models.VerificationPurposeContact)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+
+			stored := repo.codes[contact]
+			if stored.Attempts != tt.wantAttempts {
+				t.Errorf("expected %d attempts, got %d", tt.wantAttempts, stored.Attempts)
+			}
+			if locked := time.Now().Before(stored.LockedUntil); locked != tt.wantLocked {
+				t.Errorf("expected locked %v, got %v", tt.wantLocked, locked)
+			}
+			if tt.want == nil && stored.CodeHash == "" {
+				t.Errorf("expected a new code to be stored")
+			}
+		})
+	}
+}
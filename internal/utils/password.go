@@ -30,6 +30,16 @@ func CheckPasswordHash(password, hash string) bool {
 	return err == nil
 }
 
+// HashCode gera um hash bcrypt para um código de verificação
+func HashCode(code string) (string, error) {
+	bytes, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(bytes), nil
+}
+
 // GenerateResetCode gera um código de reset de 6 dígitos
 func GenerateResetCode() string {
 	// Gerar número aleatório entre 100000 e 999999
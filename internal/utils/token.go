@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"time"
 
@@ -8,34 +10,46 @@ import (
 )
 
 type Claims struct {
-	UserID string `json:"user_id"`
-	Role   string `json:"role"`
+	UserID    string `json:"user_id"`
+	Role      string `json:"role"`
+	SessionID string `json:"session_id"`
 }
 
 type TokenUtil struct {
-	secretKey []byte
-	expiresIn time.Duration
+	secretKey        []byte
+	expiresIn        time.Duration
+	refreshSecretKey []byte
+	refreshExpiresIn time.Duration
 }
 
-func NewTokenUtil(secretKey string, expiresIn time.Duration) TokenUtil {
+func NewTokenUtil(secretKey string, expiresIn time.Duration, refreshSecretKey string, refreshExpiresIn time.Duration) TokenUtil {
 	return TokenUtil{
-		secretKey: []byte(secretKey),
-		expiresIn: expiresIn,
+		secretKey:        []byte(secretKey),
+		expiresIn:        expiresIn,
+		refreshSecretKey: []byte(refreshSecretKey),
+		refreshExpiresIn: refreshExpiresIn,
 	}
 }
 
-// GenerateToken cria um novo token JWT para o usuário
-func (t TokenUtil) GenerateToken(userID, role string) (string, error) {
-	// Definir tempo de expiração
+// ExpiresIn retorna a duração de validade do token de acesso
+func (t TokenUtil) ExpiresIn() time.Duration {
+	return t.expiresIn
+}
 
-	// Criar claims
+// RefreshExpiresIn retorna a duração de validade do token de renovação
+func (t TokenUtil) RefreshExpiresIn() time.Duration {
+	return t.refreshExpiresIn
+}
 
+// GenerateToken cria um novo token JWT para o usuário, ligado à sessão do dispositivo
+func (t TokenUtil) GenerateToken(userID, role, sessionID string) (string, error) {
 	// Criar token com claims
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub": userID,                           // Subject (user identifier)
-		"iss": role,                             // Issuer
-		"aud": role,                             // Audience (user role)
-		"exp": time.Now().Add(time.Hour).Unix(), // Expiration time
+		"sub": userID,                             // Subject (user identifier)
+		"iss": role,                               // Issuer
+		"aud": role,                               // Audience (user role)
+		"jti": sessionID,                          // Sessão do dispositivo
+		"exp": time.Now().Add(t.expiresIn).Unix(), // Expiration time
 		"iat": time.Now().Unix(),
 	})
 
@@ -50,15 +64,79 @@ func (t TokenUtil) GenerateToken(userID, role string) (string, error) {
 
 // ValidateToken valida um token JWT e retorna as claims
 func (t TokenUtil) ValidateToken(tokenString string) (Claims, error) {
-	claims := Claims{}
+	mapClaims := jwt.MapClaims{}
 	// Parse do token
-	token, _ := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+	_, err := jwt.ParseWithClaims(tokenString, mapClaims, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("método de assinatura inválido")
 		}
 		return t.secretKey, nil
 	})
-	claims.UserID, _ = token.Claims.GetSubject()
-	claims.Role, _ = token.Claims.GetIssuer()
+	if err != nil {
+		return Claims{}, err
+	}
+
+	claims := Claims{}
+	claims.UserID, _ = mapClaims.GetSubject()
+	claims.Role, _ = mapClaims.GetIssuer()
+	claims.SessionID, _ = mapClaims["jti"].(string)
+	if claims.UserID == "" || claims.SessionID == "" {
+		return Claims{}, errors.New("token sem sessão associada")
+	}
+
+	return claims, nil
+}
+
+// GenerateRefreshToken cria um token de renovação ligado a uma sessão.
+// Retorna o token e a data em que expira.
+func (t TokenUtil) GenerateRefreshToken(userID, sessionID string) (string, time.Time, error) {
+	tokenID, err := GenerateRandomString(32)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	expiresAt := time.Now().Add(t.refreshExpiresIn)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": userID,           // Subject (user identifier)
+		"sid": sessionID,        // Sessão a que o token pertence
+		"jti": tokenID,          // Identificador único, muda a cada rotação
+		"exp": expiresAt.Unix(), // Expiration time
+		"iat": time.Now().Unix(),
+	})
+
+	tokenString, err := token.SignedString(t.refreshSecretKey)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return tokenString, expiresAt, nil
+}
+
+// ValidateRefreshToken valida um token de renovação e retorna as claims
+func (t TokenUtil) ValidateRefreshToken(tokenString string) (Claims, error) {
+	mapClaims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, mapClaims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("método de assinatura inválido")
+		}
+		return t.refreshSecretKey, nil
+	})
+	if err != nil {
+		return Claims{}, err
+	}
+
+	claims := Claims{}
+	claims.UserID, _ = mapClaims.GetSubject()
+	claims.SessionID, _ = mapClaims["sid"].(string)
+	if claims.UserID == "" || claims.SessionID == "" {
+		return Claims{}, errors.New("token de renovação inválido")
+	}
+
 	return claims, nil
 }
+
+// HashToken gera o hash SHA-256 de um token para armazenamento
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,145 @@
+package contentfilter
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Term é uma palavra ou expressão proibida, com as suas variantes (grafias, outras línguas,
+// abreviaturas). Um termo terminado em "*" também apanha as palavras que começam por ele.
+type Term struct {
+	Text     string
+	Variants []string
+	Action   Action
+}
+
+// blockEntry é uma grafia de um termo já normalizada, palavra a palavra
+type blockEntry struct {
+	words  []string
+	prefix bool
+	text   string
+	action Action
+}
+
+// Blocklist é a lista de bloqueio pronta a comparar com o conteúdo
+type Blocklist struct {
+	entries []blockEntry
+}
+
+// NewBlocklist normaliza os termos e as variantes. Termos com ação inválida são ignorados.
+func NewBlocklist(terms []Term) *Blocklist {
+	blocklist := &Blocklist{}
+	for _, term := range terms {
+		if !term.Action.Valid() {
+			continue
+		}
+		for _, text := range append([]string{term.Text}, term.Variants...) {
+			text = strings.TrimSpace(text)
+			prefix := strings.HasSuffix(text, "*")
+			text = strings.TrimSuffix(text, "*")
+
+			words := strings.Fields(NormalizeText(text))
+			if len(words) == 0 {
+				continue
+			}
+			blocklist.entries = append(blocklist.entries, blockEntry{
+				words:  words,
+				prefix: prefix,
+				text:   term.Text,
+				action: term.Action,
+			})
+		}
+	}
+
+	// As expressões mais longas são comparadas primeiro para serem mascaradas por inteiro
+	sort.SliceStable(blocklist.entries, func(i, j int) bool {
+		return len(blocklist.entries[i].words) > len(blocklist.entries[j].words)
+	})
+	return blocklist
+}
+
+// Check procura os termos no conteúdo. A ação é a mais severa dos termos encontrados
+// e os termos com a ação mask são mascarados.
+func (b *Blocklist) Check(content string) Result {
+	tokens := tokenize(content)
+	result := Result{}
+	var found []string
+	var spans [][2]int
+
+	for i := 0; i < len(tokens); i++ {
+		entry, ok := b.match(tokens[i:])
+		if !ok {
+			continue
+		}
+
+		if !slices.Contains(found, entry.text) {
+			found = append(found, entry.text)
+		}
+		if entry.action.severity() > result.Action.severity() {
+			result.Action = entry.action
+		}
+		end := i + len(entry.words) - 1
+		if entry.action == ActionMask {
+			spans = append(spans, [2]int{tokens[i].start, tokens[end].end})
+		}
+		i = end
+	}
+
+	if result.Action == "" {
+		return Result{}
+	}
+	result.Reason = "termos proibidos: " + strings.Join(found, ", ")
+	if len(spans) > 0 {
+		// Do fim para o início, para que as posições das outras ocorrências não mudem
+		masked := content
+		for k := len(spans) - 1; k >= 0; k-- {
+			masked = mask(masked, spans[k][0], spans[k][1])
+		}
+		result.Masked = masked
+	}
+	return result
+}
+
+// match devolve o primeiro termo que começa na primeira palavra de tokens
+func (b *Blocklist) match(tokens []token) (blockEntry, bool) {
+	for _, entry := range b.entries {
+		if len(entry.words) > len(tokens) {
+			continue
+		}
+		matched := true
+		for k, word := range entry.words {
+			last := k == len(entry.words)-1
+			if tokens[k].normalized != word && !(last && entry.prefix && strings.HasPrefix(tokens[k].normalized, word)) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return entry, true
+		}
+	}
+	return blockEntry{}, false
+}
+
+// BlocklistRule aplica a lista de bloqueio devolvida por load, que pode mudar entre chamadas
+type BlocklistRule struct {
+	load func(ctx context.Context) (*Blocklist, error)
+}
+
+func NewBlocklistRule(load func(ctx context.Context) (*Blocklist, error)) BlocklistRule {
+	return BlocklistRule{load: load}
+}
+
+func (r BlocklistRule) Name() string {
+	return "blocklist"
+}
+
+func (r BlocklistRule) Check(ctx context.Context, userID, content string) (Result, error) {
+	blocklist, err := r.load(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+	return blocklist.Check(content), nil
+}
@@ -0,0 +1,94 @@
+package contentfilter
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"merda", "merda"},
+		{"M3RRRDA", "merda"},
+		{"Coração", "coracao"},
+		{"$acan@", "sacana"},
+		{"carro", "caro"},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.word); got != tt.want {
+			t.Errorf("Normalize(%q): expected %q, got %q", tt.word, tt.want, got)
+		}
+	}
+}
+
+func TestBlocklistCheck(t *testing.T) {
+	blocklist := NewBlocklist([]Term{
+		{Text: "merda", Variants: []string{"shit"}, Action: ActionMask},
+		{Text: "filho da puta", Action: ActionReject},
+		{Text: "puta", Action: ActionMask},
+		{Text: "burr*", Action: ActionHold},
+		{Text: "desligado", Action: Action("off")},
+	})
+
+	tests := []struct {
+		name       string
+		content    string
+		wantAction Action
+		wantReason string
+		wantMasked string
+	}{
+		{"clean", "bom dia a todos", "", "", ""},
+		{"masked", "que merda de jogo", ActionMask, "termos proibidos: merda", "que ***** de jogo"},
+		{"disguised", "que M3RRRDA, pá", ActionMask, "termos proibidos: merda", "que *******, pá"},
+		{"spelled out", "que m.e.r.d.a de jogo", ActionMask, "termos proibidos: merda", "que *.*.*.*.* de jogo"},
+		{"variant", "oh shit", ActionMask, "termos proibidos: merda", "oh ****"},
+		{"longer expression first", "seu filho da puta", ActionReject, "termos proibidos: filho da puta", ""},
+		{"prefix", "que burrice", ActionHold, "termos proibidos: burr*", ""},
+		{"most severe action wins", "merda, és um burro", ActionHold, "termos proibidos: merda, burr*", "*****, és um burro"},
+		{"term counted once", "merda merda", ActionMask, "termos proibidos: merda", "***** *****"},
+		{"inside another word", "merdalhice", "", "", ""},
+		{"invalid action ignored", "está desligado", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := blocklist.Check(tt.content)
+			if result.Action != tt.wantAction {
+				t.Errorf("expected action %q, got %q", tt.wantAction, result.Action)
+			}
+			if result.Reason != tt.wantReason {
+				t.Errorf("expected reason %q, got %q", tt.wantReason, result.Reason)
+			}
+			if result.Masked != tt.wantMasked {
+				t.Errorf("expected masked %q, got %q", tt.wantMasked, result.Masked)
+			}
+		})
+	}
+}
+
+func TestBlocklistRule(t *testing.T) {
+	blocklist := NewBlocklist([]Term{{Text: "merda", Action: ActionMask}})
+	rule := NewBlocklistRule(func(ctx context.Context) (*Blocklist, error) {
+		return blocklist, nil
+	})
+
+	result, err := rule.Check(context.Background(), "user-1", "que merda")
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if result.Action != ActionMask || result.Masked != "que *****" {
+		t.Errorf("expected the loaded blocklist to be applied, got %+v", result)
+	}
+
+	failure := errors.New("falha")
+	broken := NewBlocklistRule(func(ctx context.Context) (*Blocklist, error) {
+		return nil, failure
+	})
+	if _, err := broken.Check(context.Background(), "user-1", "que merda"); !errors.Is(err, failure) {
+		t.Errorf("expected the load error, got %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+// Package contentfilter examina o texto das postagens e comentários antes de ser guardado.
+// Cada regra decide o que fazer com o que encontra: rejeitar, mascarar ou reter para revisão.
+package contentfilter
+
+import (
+	"context"
+)
+
+// Action é o que acontece ao conteúdo quando uma regra encontra algo
+type Action string
+
+const (
+	// ActionReject recusa o conteúdo
+	ActionReject Action = "reject"
+	// ActionMask esconde as partes encontradas e aceita o resto
+	ActionMask Action = "mask"
+	// ActionHold guarda o conteúdo oculto até um moderador o analisar
+	ActionHold Action = "hold"
+)
+
+// ParseAction interpreta uma ação configurada; valores desconhecidos, como "off", desativam a regra
+func ParseAction(value string) Action {
+	switch action := Action(value); action {
+	case ActionReject, ActionMask, ActionHold:
+		return action
+	}
+	return ""
+}
+
+// Valid indica se a ação é uma das conhecidas
+func (a Action) Valid() bool {
+	return ParseAction(string(a)) != ""
+}
+
+// severity ordena as ações: reject prevalece sobre hold, e hold sobre mask
+func (a Action) severity() int {
+	switch a {
+	case ActionReject:
+		return 3
+	case ActionHold:
+		return 2
+	case ActionMask:
+		return 1
+	}
+	return 0
+}
+
+// Result é o que uma regra encontrou no conteúdo.
+// Action fica vazio quando não encontrou nada; Masked, quando preenchido,
+// é o conteúdo com as partes encontradas escondidas.
+type Result struct {
+	Action Action
+	Reason string
+	Masked string
+}
+
+// Rule é uma etapa do filtro
+type Rule interface {
+	Name() string
+	Check(ctx context.Context, userID, content string) (Result, error)
+}
+
+// Recorder é uma regra que precisa de saber que conteúdo foi guardado,
+// como a deteção de mensagens repetidas
+type Recorder interface {
+	Record(ctx context.Context, userID, content string) error
+}
+
+// Match regista uma regra que encontrou algo no conteúdo
+type Match struct {
+	Rule   string `json:"rule"`
+	Action Action `json:"action"`
+	Reason string `json:"reason"`
+}
+
+// Verdict é a decisão final sobre o conteúdo.
+// Action é a ação mais severa das regras, vazia quando o conteúdo é aceite sem alterações,
+// e Content é o texto a guardar, já com as partes mascaradas.
+type Verdict struct {
+	Action  Action
+	Content string
+	Matches []Match
+}
+
+// Reasons junta os motivos das regras que encontraram algo
+func (v Verdict) Reasons() string {
+	reasons := ""
+	for i, match := range v.Matches {
+		if i > 0 {
+			reasons += "; "
+		}
+		reasons += match.Reason
+	}
+	return reasons
+}
+
+// Pipeline aplica as regras pela ordem em que foram dadas
+type Pipeline struct {
+	rules []Rule
+}
+
+func NewPipeline(rules ...Rule) *Pipeline {
+	return &Pipeline{rules: rules}
+}
+
+// Run aplica as regras ao conteúdo. As regras seguintes já recebem o conteúdo mascarado
+// pelas anteriores; a primeira que rejeita interrompe o filtro.
+func (p *Pipeline) Run(ctx context.Context, userID, content string) (Verdict, error) {
+	verdict := Verdict{Content: content}
+
+	for _, rule := range p.rules {
+		result, err := rule.Check(ctx, userID, verdict.Content)
+		if err != nil {
+			return Verdict{}, err
+		}
+		if result.Action == "" {
+			continue
+		}
+
+		verdict.Matches = append(verdict.Matches, Match{
+			Rule:   rule.Name(),
+			Action: result.Action,
+			Reason: result.Reason,
+		})
+		if result.Action.severity() > verdict.Action.severity() {
+			verdict.Action = result.Action
+		}
+		if result.Action == ActionReject {
+			verdict.Content = content
+			return verdict, nil
+		}
+		if result.Masked != "" {
+			verdict.Content = result.Masked
+		}
+	}
+
+	return verdict, nil
+}
+
+// Record avisa as regras que guardam histórico de que o conteúdo, tal como ficou no veredicto,
+// foi guardado. Deve ser chamado só depois de o conteúdo ser gravado.
+func (p *Pipeline) Record(ctx context.Context, userID, content string) error {
+	for _, rule := range p.rules {
+		recorder, ok := rule.(Recorder)
+		if !ok {
+			continue
+		}
+		if err := recorder.Record(ctx, userID, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package contentfilter
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// ruleStub devolve sempre o mesmo resultado e regista o conteúdo que recebeu
+type ruleStub struct {
+	name     string
+	result   Result
+	err      error
+	received *string
+}
+
+func (r ruleStub) Name() string {
+	return r.name
+}
+
+func (r ruleStub) Check(ctx context.Context, userID, content string) (Result, error) {
+	if r.received != nil {
+		*r.received = content
+	}
+	return r.result, r.err
+}
+
+func TestParseAction(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Action
+	}{
+		{"reject", ActionReject},
+		{"mask", ActionMask},
+		{"hold", ActionHold},
+		{"off", ""},
+		{"", ""},
+		{"REJECT", ""},
+	}
+
+	for _, tt := range tests {
+		if got := ParseAction(tt.value); got != tt.want {
+			t.Errorf("ParseAction(%q): expected %q, got %q", tt.value, tt.want, got)
+		}
+	}
+}
+
+func TestPipelineRun(t *testing.T) {
+	const content = "olá mundo"
+	mask := func(name, masked string) Rule {
+		return ruleStub{name: name, result: Result{Action: ActionMask, Reason: name, Masked: masked}}
+	}
+	hold := ruleStub{name: "hold", result: Result{Action: ActionHold, Reason: "hold"}}
+	reject := ruleStub{name: "reject", result: Result{Action: ActionReject, Reason: "reject"}}
+	clean := ruleStub{name: "clean"}
+
+	tests := []struct {
+		name        string
+		rules       []Rule
+		wantAction  Action
+		wantContent string
+		wantRules   []string
+	}{
+		{"no rules", nil, "", content, nil},
+		{"nothing found", []Rule{clean, clean}, "", content, nil},
+		{"masked", []Rule{mask("mask", "olá *****")}, ActionMask, "olá *****", []string{"mask"}},
+		{"last mask wins", []Rule{mask("first", "*** mundo"), mask("second", "*** *****")}, ActionMask, "*** *****", []string{"first", "second"}},
+		{"hold keeps the masked content", []Rule{mask("mask", "olá *****"), hold}, ActionHold, "olá *****", []string{"mask", "hold"}},
+		{"mask after hold stays hold", []Rule{hold, mask("mask", "olá *****")}, ActionHold, "olá *****", []string{"hold", "mask"}},
+		{"reject restores the original", []Rule{mask("mask", "olá *****"), reject}, ActionReject, content, []string{"mask", "reject"}},
+		{"reject stops the pipeline", []Rule{reject, hold}, ActionReject, content, []string{"reject"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict, err := NewPipeline(tt.rules...).Run(context.Background(), "user-1", content)
+			if err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+			if verdict.Action != tt.wantAction {
+				t.Errorf("expected action %q, got %q", tt.wantAction, verdict.Action)
+			}
+			if verdict.Content != tt.wantContent {
+				t.Errorf("expected content %q, got %q", tt.wantContent, verdict.Content)
+			}
+			if len(verdict.Matches) != len(tt.wantRules) {
+				t.Fatalf("expected %d matches, got %+v", len(tt.wantRules), verdict.Matches)
+			}
+			for i, match := range verdict.Matches {
+				if match.Rule != tt.wantRules[i] || match.Reason != tt.wantRules[i] {
+					t.Errorf("expected match %d from %s, got %+v", i, tt.wantRules[i], match)
+				}
+			}
+		})
+	}
+}
+
+func TestPipelineRunPassesMaskedContent(t *testing.T) {
+	var received string
+	pipeline := NewPipeline(
+		ruleStub{name: "mask", result: Result{Action: ActionMask, Masked: "olá *****"}},
+		ruleStub{name: "next", received: &received},
+	)
+
+	if _, err := pipeline.Run(context.Background(), "user-1", "olá mundo"); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if received != "olá *****" {
+		t.Errorf("expected the next rule to receive the masked content, got %q", received)
+	}
+}
+
+func TestPipelineRunError(t *testing.T) {
+	failure := errors.New("falha")
+	pipeline := NewPipeline(
+		ruleStub{name: "mask", result: Result{Action: ActionMask, Masked: "***"}},
+		ruleStub{name: "broken", err: failure},
+	)
+
+	verdict, err := pipeline.Run(context.Background(), "user-1", "olá")
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected the rule error, got %v", err)
+	}
+	if verdict.Action != "" || verdict.Matches != nil {
+		t.Errorf("expected an empty verdict, got %+v", verdict)
+	}
+}
+
+func TestVerdictReasons(t *testing.T) {
+	verdict := Verdict{Matches: []Match{{Reason: "links a mais (3)"}, {Reason: "mensagem repetida"}}}
+
+	if got, want := verdict.Reasons(), "links a mais (3); mensagem repetida"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if got := (Verdict{}).Reasons(); got != "" {
+		t.Errorf("expected no reasons, got %q", got)
+	}
+}
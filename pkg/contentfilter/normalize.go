@@ -0,0 +1,131 @@
+package contentfilter
+
+import (
+	"strings"
+	"unicode"
+)
+
+// accents associa as letras acentuadas do português à letra base
+var accents = map[rune]rune{
+	'á': 'a', 'à': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a',
+	'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e',
+	'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i',
+	'ó': 'o', 'ò': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o',
+	'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u',
+	'ç': 'c', 'ñ': 'n',
+}
+
+// leetspeak associa os números e símbolos usados para disfarçar palavras à letra que imitam
+var leetspeak = map[rune]rune{
+	'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't',
+	'@': 'a', '$': 's',
+}
+
+// Normalize reduz uma palavra à forma usada na comparação com a lista de bloqueio:
+// minúsculas, sem acentos, com o leetspeak traduzido e sem letras repetidas seguidas,
+// para que "M3RRRDA" e "merda" coincidam. Palavras com letras duplas, como "carro",
+// também perdem a repetição, o que é indiferente porque os termos são normalizados da mesma forma.
+func Normalize(word string) string {
+	var b strings.Builder
+	var last rune
+	for _, r := range strings.ToLower(word) {
+		if base, ok := accents[r]; ok {
+			r = base
+		}
+		if letter, ok := leetspeak[r]; ok {
+			r = letter
+		}
+		if unicode.Is(unicode.Mn, r) || r == last {
+			continue
+		}
+		b.WriteRune(r)
+		last = r
+	}
+	return b.String()
+}
+
+// NormalizeText normaliza cada palavra do texto e separa-as por um espaço, sem a pontuação
+func NormalizeText(text string) string {
+	var words []string
+	for _, t := range tokenize(text) {
+		words = append(words, t.normalized)
+	}
+	return strings.Join(words, " ")
+}
+
+// isWordRune indica se o carácter faz parte de uma palavra, incluindo os símbolos do leetspeak
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) || r == '@' || r == '$'
+}
+
+// token é uma palavra do conteúdo, com a posição original para a poder mascarar
+type token struct {
+	start, end int
+	normalized string
+}
+
+// minSpelledOut é o número mínimo de letras soltas seguidas tratadas como uma palavra soletrada
+const minSpelledOut = 3
+
+// tokenize divide o conteúdo em palavras normalizadas. Letras soltas seguidas, como em
+// "m e r d a" ou "m.e.r.d.a", são juntas numa só palavra.
+func tokenize(content string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range content {
+		switch {
+		case isWordRune(r) && start < 0:
+			start = i
+		case !isWordRune(r) && start >= 0:
+			tokens = append(tokens, token{start: start, end: i, normalized: Normalize(content[start:i])})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{start: start, end: len(content), normalized: Normalize(content[start:])})
+	}
+
+	merged := make([]token, 0, len(tokens))
+	for i := 0; i < len(tokens); {
+		j := i
+		for j < len(tokens) && isSingleLetter(content, tokens[j]) {
+			j++
+		}
+		if j-i < minSpelledOut {
+			merged = append(merged, tokens[i])
+			i++
+			continue
+		}
+
+		var letters []string
+		for _, t := range tokens[i:j] {
+			letters = append(letters, t.normalized)
+		}
+		merged = append(merged, token{
+			start:      tokens[i].start,
+			end:        tokens[j-1].end,
+			normalized: Normalize(strings.Join(letters, "")),
+		})
+		i = j
+	}
+	return merged
+}
+
+// isSingleLetter indica se a palavra tem um único carácter
+func isSingleLetter(content string, t token) bool {
+	return len([]rune(content[t.start:t.end])) == 1
+}
+
+// mask substitui as letras e números entre start e end por asteriscos, mantendo a pontuação e os espaços
+func mask(content string, start, end int) string {
+	var b strings.Builder
+	b.WriteString(content[:start])
+	for _, r := range content[start:end] {
+		if isWordRune(r) {
+			r = '*'
+		}
+		b.WriteRune(r)
+	}
+	b.WriteString(content[end:])
+	return b.String()
+}
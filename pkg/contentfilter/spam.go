@@ -0,0 +1,157 @@
+package contentfilter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	// linkPattern apanha endereços com protocolo, começados por www. ou com um domínio de topo comum
+	linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s]+|\b[a-z0-9-]+(?:\.[a-z0-9-]+)*\.(?:com|net|org|info|biz|xyz|top|click|link|site|online|shop|io|co|me|ly|gl|mz|pt|br|ao)\b(?:/[^\s]*)?`)
+	// phonePattern apanha números de telemóvel moçambicanos (82 a 87), com ou sem o indicativo,
+	// e números internacionais escritos com + ou 00
+	phonePattern = regexp.MustCompile(`(?:(?:\+|00)?258[\s.-]?)?\b8[2-7](?:[\s.-]?\d){7}\b|(?:\+|00)[1-9](?:[\s.-]?\d){7,13}\b`)
+)
+
+// PatternRule conta as ocorrências de um padrão e age quando passam do máximo permitido.
+// Ao mascarar, cada ocorrência é trocada por replacement.
+type PatternRule struct {
+	name        string
+	pattern     *regexp.Regexp
+	max         int
+	action      Action
+	reason      string
+	replacement string
+}
+
+// NewLinkRule age sobre o conteúdo com mais de max links
+func NewLinkRule(max int, action Action) PatternRule {
+	return PatternRule{
+		name:        "links",
+		pattern:     linkPattern,
+		max:         max,
+		action:      action,
+		reason:      "links a mais",
+		replacement: "[link removido]",
+	}
+}
+
+// NewPhoneRule age sobre o conteúdo com mais de max números de telefone
+func NewPhoneRule(max int, action Action) PatternRule {
+	return PatternRule{
+		name:        "phones",
+		pattern:     phonePattern,
+		max:         max,
+		action:      action,
+		reason:      "números de telefone a mais",
+		replacement: "[número removido]",
+	}
+}
+
+func (r PatternRule) Name() string {
+	return r.name
+}
+
+func (r PatternRule) Check(ctx context.Context, userID, content string) (Result, error) {
+	if r.action == "" {
+		return Result{}, nil
+	}
+
+	found := r.pattern.FindAllStringIndex(content, -1)
+	if len(found) <= r.max {
+		return Result{}, nil
+	}
+
+	result := Result{Action: r.action, Reason: r.reason + " (" + strconv.Itoa(len(found)) + ")"}
+	if r.action == ActionMask {
+		result.Masked = r.pattern.ReplaceAllLiteralString(content, r.replacement)
+	}
+	return result, nil
+}
+
+// History guarda as impressões digitais das mensagens guardadas de cada usuário
+type History interface {
+	// Count devolve quantas vezes o usuário enviou a impressão digital desde since
+	Count(ctx context.Context, userID, fingerprint string, since time.Time) (int64, error)
+	// Record regista a impressão digital de uma mensagem do usuário que foi guardada
+	Record(ctx context.Context, userID, fingerprint string) error
+}
+
+// minDuplicateLength ignora mensagens curtas, como "obrigado" ou "parabéns", que é normal repetir
+const minDuplicateLength = 20
+
+// DuplicateRule age quando o usuário envia a mesma mensagem mais de uma vez dentro da janela,
+// mesmo em postagens diferentes. Só contam as mensagens que foram de facto guardadas, registadas
+// com Record: uma mensagem recusada pode ser enviada de novo. Mascarar não se aplica a mensagens
+// repetidas e é tratado como hold.
+type DuplicateRule struct {
+	history History
+	window  time.Duration
+	action  Action
+}
+
+func NewDuplicateRule(history History, window time.Duration, action Action) DuplicateRule {
+	if action == ActionMask {
+		action = ActionHold
+	}
+	return DuplicateRule{
+		history: history,
+		window:  window,
+		action:  action,
+	}
+}
+
+func (r DuplicateRule) Name() string {
+	return "duplicate"
+}
+
+func (r DuplicateRule) Check(ctx context.Context, userID, content string) (Result, error) {
+	if r.action == "" {
+		return Result{}, nil
+	}
+
+	fingerprint, ok := duplicateFingerprint(content)
+	if !ok {
+		return Result{}, nil
+	}
+
+	previous, err := r.history.Count(ctx, userID, fingerprint, time.Now().Add(-r.window))
+	if err != nil {
+		return Result{}, err
+	}
+	if previous == 0 {
+		return Result{}, nil
+	}
+
+	return Result{Action: r.action, Reason: "mensagem repetida"}, nil
+}
+
+// Record regista a mensagem guardada para que as repetições seguintes sejam detetadas
+func (r DuplicateRule) Record(ctx context.Context, userID, content string) error {
+	if r.action == "" {
+		return nil
+	}
+
+	fingerprint, ok := duplicateFingerprint(content)
+	if !ok {
+		return nil
+	}
+	return r.history.Record(ctx, userID, fingerprint)
+}
+
+// duplicateFingerprint devolve a impressão digital do texto normalizado, ou false
+// para as mensagens curtas que não contam como repetidas
+func duplicateFingerprint(content string) (string, bool) {
+	normalized := NormalizeText(content)
+	if utf8.RuneCountInString(normalized) < minDuplicateLength {
+		return "", false
+	}
+
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:]), true
+}
@@ -0,0 +1,225 @@
+package contentfilter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLinkRule(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		max        int
+		action     Action
+		wantAction Action
+		wantMasked string
+	}{
+		{"within the limit", "veja https://exemplo.com", 1, ActionReject, "", ""},
+		{"above the limit", "veja https://exemplo.com e www.outro.net", 1, ActionReject, ActionReject, ""},
+		{"domain without protocol", "compre em loja.co.mz e promo.xyz", 1, ActionHold, ActionHold, ""},
+		{"masked", "veja http://a.com/x e loja.shop", 1, ActionMask, ActionMask, "veja [link removido] e [link removido]"},
+		{"disabled", "a.com b.com c.com", 0, "", "", ""},
+		{"plain text", "o jogo acabou 3.0 para nós", 0, ActionReject, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewLinkRule(tt.max, tt.action).Check(context.Background(), "user-1", tt.content)
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if result.Action != tt.wantAction {
+				t.Errorf("expected action %q, got %q", tt.wantAction, result.Action)
+			}
+			if result.Masked != tt.wantMasked {
+				t.Errorf("expected masked %q, got %q", tt.wantMasked, result.Masked)
+			}
+		})
+	}
+}
+
+func TestPhoneRule(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantAction Action
+		wantMasked string
+	}{
+		{"local number", "ligue 841234567", ActionMask, "ligue [número removido]"},
+		{"with country code and spaces", "ligue +258 84 123 4567", ActionMask, "ligue [número removido]"},
+		{"international", "whatsapp 00351912345678", ActionMask, "whatsapp [número removido]"},
+		{"not a mobile prefix", "código 211234567", "", ""},
+		{"too short", "ligue 8412345", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewPhoneRule(0, ActionMask).Check(context.Background(), "user-1", tt.content)
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if result.Action != tt.wantAction {
+				t.Errorf("expected action %q, got %q", tt.wantAction, result.Action)
+			}
+			if result.Masked != tt.wantMasked {
+				t.Errorf("expected masked %q, got %q", tt.wantMasked, result.Masked)
+			}
+		})
+	}
+}
+
+// historyStub guarda as impressões digitais em memória, com a hora em que foram registadas
+type historyStub struct {
+	records map[string][]time.Time
+	err     error
+}
+
+func newHistoryStub() *historyStub {
+	return &historyStub{records: map[string][]time.Time{}}
+}
+
+func (h *historyStub) Count(ctx context.Context, userID, fingerprint string, since time.Time) (int64, error) {
+	var count int64
+	for _, at := range h.records[userID+":"+fingerprint] {
+		if !at.Before(since) {
+			count++
+		}
+	}
+	return count, h.err
+}
+
+func (h *historyStub) Record(ctx context.Context, userID, fingerprint string) error {
+	if h.err != nil {
+		return h.err
+	}
+	h.records[userID+":"+fingerprint] = append(h.records[userID+":"+fingerprint], time.Now())
+	return nil
+}
+
+func TestDuplicateRule(t *testing.T) {
+	const message = "Vendo telemóvel novo, contacte-me já"
+
+	tests := []struct {
+		name       string
+		recorded   []string
+		userID     string
+		content    string
+		action     Action
+		wantAction Action
+	}{
+		{"first message", nil, "user-1", message, ActionReject, ""},
+		{"repeated", []string{message}, "user-1", message, ActionReject, ActionReject},
+		{"repeated with other spelling", []string{message}, "user-1", "VENDO telemovel novo!!! contacte me ja", ActionReject, ActionReject},
+		{"other user", []string{message}, "user-2", message, ActionReject, ""},
+		{"short message", []string{"muito obrigado"}, "user-1", "muito obrigado", ActionReject, ""},
+		{"mask is treated as hold", []string{message}, "user-1", message, ActionMask, ActionHold},
+		{"disabled", []string{message}, "user-1", message, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := newHistoryStub()
+			rule := NewDuplicateRule(history, time.Hour, tt.action)
+			for _, content := range tt.recorded {
+				if err := NewDuplicateRule(history, time.Hour, ActionReject).Record(context.Background(), "user-1", content); err != nil {
+					t.Fatalf("Record: %v", err)
+				}
+			}
+
+			result, err := rule.Check(context.Background(), tt.userID, tt.content)
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if result.Action != tt.wantAction {
+				t.Errorf("expected action %q, got %q", tt.wantAction, result.Action)
+			}
+			if result.Masked != "" {
+				t.Errorf("expected repeated messages not to be masked, got %q", result.Masked)
+			}
+		})
+	}
+}
+
+func TestDuplicateRuleCheckDoesNotRecord(t *testing.T) {
+	const message = "Vendo telemóvel novo, contacte-me já"
+	history := newHistoryStub()
+	rule := NewDuplicateRule(history, time.Hour, ActionReject)
+	ctx := context.Background()
+
+	// Uma mensagem recusada por outra regra nunca é registada e pode ser enviada de novo
+	for i := 0; i < 2; i++ {
+		if result, err := rule.Check(ctx, "user-1", message); err != nil || result.Action != "" {
+			t.Fatalf("attempt %d: expected no match, got %+v, %v", i, result, err)
+		}
+	}
+	if len(history.records) != 0 {
+		t.Errorf("expected Check not to record, got %v", history.records)
+	}
+}
+
+func TestDuplicateRuleWindow(t *testing.T) {
+	const message = "Vendo telemóvel novo, contacte-me já"
+	history := newHistoryStub()
+	fingerprint, _ := duplicateFingerprint(message)
+	history.records["user-1:"+fingerprint] = []time.Time{time.Now().Add(-2 * time.Hour)}
+
+	result, err := NewDuplicateRule(history, time.Hour, ActionReject).Check(context.Background(), "user-1", message)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if result.Action != "" {
+		t.Errorf("expected messages outside the window to be ignored, got %q", result.Action)
+	}
+}
+
+func TestDuplicateRuleRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		action  Action
+		want    int
+	}{
+		{"long message", "Vendo telemóvel novo, contacte-me já", ActionHold, 1},
+		{"short message", "parabéns!", ActionHold, 0},
+		{"disabled", "Vendo telemóvel novo, contacte-me já", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := newHistoryStub()
+			if err := NewDuplicateRule(history, time.Hour, tt.action).Record(context.Background(), "user-1", tt.content); err != nil {
+				t.Fatalf("Record: %v", err)
+			}
+			if len(history.records) != tt.want {
+				t.Errorf("expected %d fingerprints, got %d", tt.want, len(history.records))
+			}
+		})
+	}
+}
+
+func TestPipelineRecord(t *testing.T) {
+	const message = "Vendo telemóvel novo, contacte-me já"
+	history := newHistoryStub()
+	pipeline := NewPipeline(NewLinkRule(0, ActionReject), NewDuplicateRule(history, time.Hour, ActionHold))
+	ctx := context.Background()
+
+	if err := pipeline.Record(ctx, "user-1", message); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	verdict, err := pipeline.Run(ctx, "user-1", message)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if verdict.Action != ActionHold || !strings.Contains(verdict.Reasons(), "mensagem repetida") {
+		t.Errorf("expected the recorded message to be held as repeated, got %+v", verdict)
+	}
+
+	failure := errors.New("falha")
+	history.err = failure
+	if err := pipeline.Record(ctx, "user-1", message); !errors.Is(err, failure) {
+		t.Errorf("expected the history error, got %v", err)
+	}
+}
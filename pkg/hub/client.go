@@ -0,0 +1,200 @@
+package hub
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Client é uma ligação WebSocket registada no hub.
+// Só a goroutine de escrita escreve na ligação, por isso Send é seguro entre goroutines.
+type Client struct {
+	hub    *Hub
+	conn   *websocket.Conn
+	userID string
+	send   chan []byte
+
+	topicsMu sync.Mutex
+	topics   map[string]struct{}
+
+	throttleMu sync.Mutex
+	lastAction map[string]time.Time
+
+	closed      chan struct{}
+	closeOnce   sync.Once
+	closeCode   int
+	closeReason string
+}
+
+// MessageHandler recebe cada mensagem enviada pelo cliente
+type MessageHandler func(client *Client, data []byte)
+
+// Serve regista a ligação já inscrita nos tópicos indicados e bloqueia até ela terminar.
+// handler pode ser nil quando as mensagens recebidas não interessam.
+func (h *Hub) Serve(conn *websocket.Conn, userID string, topics []string, handler MessageHandler) error {
+	client := &Client{
+		hub:    h,
+		conn:   conn,
+		userID: userID,
+		send:   make(chan []byte, h.config.SendBuffer),
+		topics: make(map[string]struct{}),
+		closed: make(chan struct{}),
+	}
+	for _, topic := range topics {
+		client.topics[topic] = struct{}{}
+	}
+
+	select {
+	case h.register <- client:
+	case <-h.done:
+		conn.Close()
+		return ErrHubClosed
+	}
+
+	go client.writePump()
+	client.readPump(handler)
+	h.Unregister(client)
+	return nil
+}
+
+// UserID devolve o usuário dono da ligação
+func (c *Client) UserID() string {
+	return c.userID
+}
+
+// Subscribe inscreve a ligação num tópico publicado com Hub.Publish
+func (c *Client) Subscribe(topic string) {
+	c.topicsMu.Lock()
+	defer c.topicsMu.Unlock()
+	c.topics[topic] = struct{}{}
+}
+
+// Unsubscribe cancela a inscrição da ligação num tópico
+func (c *Client) Unsubscribe(topic string) {
+	c.topicsMu.Lock()
+	defer c.topicsMu.Unlock()
+	delete(c.topics, topic)
+}
+
+// Subscribed indica se a ligação está inscrita no tópico
+func (c *Client) Subscribed(topic string) bool {
+	c.topicsMu.Lock()
+	defer c.topicsMu.Unlock()
+	_, ok := c.topics[topic]
+	return ok
+}
+
+// SubscribedAny indica se a ligação está inscrita em algum dos tópicos
+func (c *Client) SubscribedAny(topics []string) bool {
+	c.topicsMu.Lock()
+	defer c.topicsMu.Unlock()
+	for _, topic := range topics {
+		if _, ok := c.topics[topic]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Allow indica se a ação pode ser feita agora nesta ligação, no máximo uma vez por interval.
+// Serve para limitar mensagens frequentes do cliente, como a indicação de que está a escrever.
+func (c *Client) Allow(action string, interval time.Duration) bool {
+	c.throttleMu.Lock()
+	defer c.throttleMu.Unlock()
+
+	now := time.Now()
+	if last, ok := c.lastAction[action]; ok && now.Sub(last) < interval {
+		return false
+	}
+	if c.lastAction == nil {
+		c.lastAction = make(map[string]time.Time)
+	}
+	c.lastAction[action] = now
+	return true
+}
+
+// Send coloca a mensagem na fila da ligação sem bloquear.
+// Devolve false se a ligação estiver fechada ou com a fila cheia.
+func (c *Client) Send(data []byte) bool {
+	select {
+	case <-c.closed:
+		return false
+	default:
+	}
+
+	select {
+	case c.send <- data:
+		return true
+	default:
+		return false
+	}
+}
+
+// close pede à goroutine de escrita que envie o frame de fecho e termine a ligação
+func (c *Client) close(code int, reason string) {
+	c.closeOnce.Do(func() {
+		c.closeCode = code
+		c.closeReason = reason
+		close(c.closed)
+	})
+}
+
+// readPump lê as mensagens do cliente e renova o prazo de leitura a cada pong
+func (c *Client) readPump(handler MessageHandler) {
+	config := c.hub.config
+
+	c.conn.SetReadLimit(config.MaxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(config.PongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(config.PongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure, websocket.CloseNormalClosure) {
+				c.hub.logger.Debug("ws_read_error", "user_id", c.userID, "error", err)
+			}
+			return
+		}
+
+		c.conn.SetReadDeadline(time.Now().Add(config.PongWait))
+		if handler != nil {
+			handler(c, data)
+		}
+	}
+}
+
+// writePump escreve as mensagens da fila e envia pings periódicos
+func (c *Client) writePump() {
+	config := c.hub.config
+	ticker := time.NewTicker(config.PingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case data := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(config.WriteWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				c.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(config.WriteWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-c.closed:
+			if c.closeCode != websocket.CloseAbnormalClosure {
+				message := websocket.FormatCloseMessage(c.closeCode, c.closeReason)
+				c.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(config.WriteWait))
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package hub
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/anamalala/pkg/logger"
+	"github.com/gorilla/websocket"
+)
+
+// ErrHubClosed indica que o hub já foi encerrado e não aceita novas ligações
+var ErrHubClosed = errors.New("hub: encerrado")
+
+// Config define a fila de envio e os tempos de keepalive de cada ligação
+type Config struct {
+	// SendBuffer é o número de mensagens que podem esperar por uma ligação antes de ela ser considerada lenta
+	SendBuffer int
+	// PingPeriod é o intervalo entre pings; deve ser inferior a PongWait
+	PingPeriod time.Duration
+	// PongWait é o tempo máximo sem receber nada do cliente
+	PongWait time.Duration
+	// WriteWait é o tempo máximo para escrever uma mensagem
+	WriteWait time.Duration
+	// MaxMessageSize é o tamanho máximo de uma mensagem recebida
+	MaxMessageSize int64
+}
+
+// withDefaults preenche os valores em falta com os predefinidos
+func (c Config) withDefaults() Config {
+	if c.SendBuffer <= 0 {
+		c.SendBuffer = 64
+	}
+	if c.PongWait <= 0 {
+		c.PongWait = 60 * time.Second
+	}
+	if c.PingPeriod <= 0 || c.PingPeriod >= c.PongWait {
+		c.PingPeriod = c.PongWait * 9 / 10
+	}
+	if c.WriteWait <= 0 {
+		c.WriteWait = 10 * time.Second
+	}
+	if c.MaxMessageSize <= 0 {
+		c.MaxMessageSize = 8192
+	}
+	return c
+}
+
+// Metrics resume o estado das ligações do hub
+type Metrics struct {
+	Connections          int64 `json:"connections"`
+	Users                int64 `json:"users"`
+	TotalConnections     int64 `json:"total_connections"`
+	MessagesSent         int64 `json:"messages_sent"`
+	MessagesDropped      int64 `json:"messages_dropped"`
+	SlowConsumersEvicted int64 `json:"slow_consumers_evicted"`
+}
+
+// delivery é uma mensagem à espera de ser distribuída pelo hub
+type delivery struct {
+	// userID vazio e sem tópicos envia para todas as ligações
+	userID string
+	topics []string
+	data   []byte
+	result chan int
+}
+
+// Hub gere as ligações WebSocket. Só a goroutine de Run acede ao mapa de clientes;
+// cada ligação tem a sua própria fila e goroutine de escrita.
+type Hub struct {
+	config     Config
+	logger     *logger.Logger
+	register   chan *Client
+	unregister chan *Client
+	deliveries chan delivery
+	done       chan struct{}
+	clients    map[string]map[*Client]struct{}
+
+	connections      atomic.Int64
+	users            atomic.Int64
+	totalConnections atomic.Int64
+	messagesSent     atomic.Int64
+	messagesDropped  atomic.Int64
+	evicted          atomic.Int64
+}
+
+// NewHub cria um novo Hub; é preciso chamar Run para começar a aceitar ligações
+func NewHub(config Config, logger *logger.Logger) *Hub {
+	return &Hub{
+		config:     config.withDefaults(),
+		logger:     logger,
+		register:   make(chan *Client),
+		unregister: make(chan *Client),
+		deliveries: make(chan delivery, 256),
+		done:       make(chan struct{}),
+		clients:    make(map[string]map[*Client]struct{}),
+	}
+}
+
+// Run processa registos, saídas e mensagens até o contexto ser cancelado.
+// Ao terminar fecha todas as ligações.
+func (h *Hub) Run(ctx context.Context) {
+	defer close(h.done)
+
+	for {
+		select {
+		case <-ctx.Done():
+			for _, clients := range h.clients {
+				for client := range clients {
+					h.remove(client, websocket.CloseGoingAway, "servidor a encerrar")
+				}
+			}
+			return
+		case client := <-h.register:
+			h.add(client)
+		case client := <-h.unregister:
+			h.remove(client, websocket.CloseNormalClosure, "")
+		case d := <-h.deliveries:
+			delivered := h.deliver(d)
+			if d.result != nil {
+				d.result <- delivered
+			}
+		}
+	}
+}
+
+// Broadcast envia a mensagem para todas as ligações sem esperar pela distribuição
+func (h *Hub) Broadcast(data []byte) {
+	select {
+	case h.deliveries <- delivery{data: data}:
+	case <-h.done:
+	}
+}
+
+// Publish envia a mensagem apenas às ligações inscritas em pelo menos um dos tópicos
+func (h *Hub) Publish(topics []string, data []byte) {
+	if len(topics) == 0 {
+		return
+	}
+
+	select {
+	case h.deliveries <- delivery{topics: topics, data: data}:
+	case <-h.done:
+	}
+}
+
+// SendToUser envia a mensagem para as ligações de um usuário e devolve quantas a receberam
+func (h *Hub) SendToUser(userID string, data []byte) int {
+	result := make(chan int, 1)
+	select {
+	case h.deliveries <- delivery{userID: userID, data: data, result: result}:
+	case <-h.done:
+		return 0
+	}
+
+	select {
+	case delivered := <-result:
+		return delivered
+	case <-h.done:
+		return 0
+	}
+}
+
+// Metrics devolve os contadores atuais do hub
+func (h *Hub) Metrics() Metrics {
+	return Metrics{
+		Connections:          h.connections.Load(),
+		Users:                h.users.Load(),
+		TotalConnections:     h.totalConnections.Load(),
+		MessagesSent:         h.messagesSent.Load(),
+		MessagesDropped:      h.messagesDropped.Load(),
+		SlowConsumersEvicted: h.evicted.Load(),
+	}
+}
+
+// Unregister remove a ligação do hub e fecha-a
+func (h *Hub) Unregister(client *Client) {
+	select {
+	case h.unregister <- client:
+	case <-h.done:
+	}
+}
+
+// add regista uma ligação; chamado apenas pela goroutine de Run
+func (h *Hub) add(client *Client) {
+	clients, ok := h.clients[client.userID]
+	if !ok {
+		clients = make(map[*Client]struct{})
+		h.clients[client.userID] = clients
+		h.users.Add(1)
+	}
+	clients[client] = struct{}{}
+
+	h.connections.Add(1)
+	h.totalConnections.Add(1)
+	h.logger.Debug("ws_client_registered", "user_id", client.userID, "connections", len(clients))
+}
+
+// remove retira uma ligação do mapa e pede o seu fecho; chamado apenas pela goroutine de Run
+func (h *Hub) remove(client *Client, code int, reason string) {
+	clients, ok := h.clients[client.userID]
+	if !ok {
+		return
+	}
+	if _, ok := clients[client]; !ok {
+		return
+	}
+
+	delete(clients, client)
+	if len(clients) == 0 {
+		delete(h.clients, client.userID)
+		h.users.Add(-1)
+	}
+	h.connections.Add(-1)
+
+	client.close(code, reason)
+	h.logger.Debug("ws_client_unregistered", "user_id", client.userID, "connections", len(clients))
+}
+
+// deliver coloca a mensagem na fila de cada ligação de destino.
+// Uma ligação com a fila cheia é lenta demais e é desligada.
+func (h *Hub) deliver(d delivery) int {
+	var targets []*Client
+	if d.userID != "" {
+		for client := range h.clients[d.userID] {
+			targets = append(targets, client)
+		}
+	} else {
+		for _, clients := range h.clients {
+			for client := range clients {
+				if len(d.topics) == 0 || client.SubscribedAny(d.topics) {
+					targets = append(targets, client)
+				}
+			}
+		}
+	}
+
+	delivered := 0
+	for _, client := range targets {
+		if client.Send(d.data) {
+			delivered++
+			h.messagesSent.Add(1)
+			continue
+		}
+
+		h.messagesDropped.Add(1)
+		h.evicted.Add(1)
+		h.logger.Warn("ws_slow_consumer_evicted", "user_id", client.userID)
+		h.remove(client, websocket.CloseTryAgainLater, "ligação lenta")
+	}
+
+	return delivered
+}
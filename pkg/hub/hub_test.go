@@ -0,0 +1,321 @@
+package hub
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/anamalala/pkg/logger"
+	"github.com/gorilla/websocket"
+)
+
+// newTestHub inicia um hub e um servidor que liga cada pedido ao hub. O usuário vem do parâmetro
+// user e os tópicos dos parâmetros topic; as mensagens "subscribe:<tópico>" e "unsubscribe:<tópico>"
+// mudam as inscrições e são respondidas com "ok".
+func newTestHub(t *testing.T, config Config) (*Hub, *httptest.Server, context.CancelFunc) {
+	t.Helper()
+
+	hub := NewHub(config, logger.NewLogger("production"))
+	ctx, cancel := context.WithCancel(context.Background())
+	go hub.Run(ctx)
+
+	upgrader := websocket.Upgrader{}
+	handler := func(client *Client, data []byte) {
+		command, topic, _ := strings.Cut(string(data), ":")
+		switch command {
+		case "subscribe":
+			client.Subscribe(topic)
+		case "unsubscribe":
+			client.Unsubscribe(topic)
+		}
+		client.Send([]byte("ok"))
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		hub.Serve(conn, r.URL.Query().Get("user"), r.URL.Query()["topic"], handler)
+	}))
+
+	t.Cleanup(func() {
+		cancel()
+		server.Close()
+	})
+	return hub, server, cancel
+}
+
+// dial abre uma ligação do usuário inscrita nos tópicos indicados
+func dial(t *testing.T, server *httptest.Server, userID string, topics ...string) *websocket.Conn {
+	t.Helper()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/?user=" + userID
+	for _, topic := range topics {
+		url += "&topic=" + topic
+	}
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// waitFor espera até a condição ser verdadeira, para as mudanças feitas pela goroutine do hub
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// waitConnections espera até o hub ter o número de ligações e usuários indicados
+func waitConnections(t *testing.T, hub *Hub, connections, users int64) {
+	t.Helper()
+
+	waitFor(t, "connections", func() bool {
+		metrics := hub.Metrics()
+		return metrics.Connections == connections && metrics.Users == users
+	})
+}
+
+// readText lê a próxima mensagem de texto da ligação
+func readText(t *testing.T, conn *websocket.Conn) string {
+	t.Helper()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	return string(data)
+}
+
+// command envia um comando ao handler de teste e espera pela resposta
+func command(t *testing.T, conn *websocket.Conn, text string) {
+	t.Helper()
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(text)); err != nil {
+		t.Fatalf("WriteMessage: %v", err)
+	}
+	if got := readText(t, conn); got != "ok" {
+		t.Fatalf("expected ok, got %q", got)
+	}
+}
+
+func TestRegisterAndUnregister(t *testing.T) {
+	hub, server, _ := newTestHub(t, Config{})
+
+	first := dial(t, server, "user-1")
+	second := dial(t, server, "user-1")
+	dial(t, server, "user-2")
+	waitConnections(t, hub, 3, 2)
+
+	first.Close()
+	waitConnections(t, hub, 2, 2)
+	second.Close()
+	waitConnections(t, hub, 1, 1)
+
+	if got := hub.Metrics().TotalConnections; got != 3 {
+		t.Errorf("expected 3 connections in total, got %d", got)
+	}
+}
+
+func TestSendToUser(t *testing.T) {
+	hub, server, _ := newTestHub(t, Config{})
+
+	first := dial(t, server, "user-1")
+	second := dial(t, server, "user-1")
+	other := dial(t, server, "user-2")
+	waitConnections(t, hub, 3, 2)
+
+	tests := []struct {
+		userID string
+		want   int
+	}{
+		{"user-1", 2},
+		{"user-2", 1},
+		{"nobody", 0},
+	}
+	for _, tt := range tests {
+		if got := hub.SendToUser(tt.userID, []byte("to "+tt.userID)); got != tt.want {
+			t.Errorf("SendToUser(%s): expected %d connections, got %d", tt.userID, tt.want, got)
+		}
+	}
+
+	for _, conn := range []*websocket.Conn{first, second} {
+		if got := readText(t, conn); got != "to user-1" {
+			t.Errorf("expected the message to user-1, got %q", got)
+		}
+	}
+	if got := readText(t, other); got != "to user-2" {
+		t.Errorf("expected only the message to user-2, got %q", got)
+	}
+}
+
+func TestPublishTopics(t *testing.T) {
+	hub, server, _ := newTestHub(t, Config{})
+
+	feed := dial(t, server, "user-1", "feed")
+	post := dial(t, server, "user-2")
+	both := dial(t, server, "user-3", "feed")
+	none := dial(t, server, "user-4")
+	waitConnections(t, hub, 4, 4)
+
+	command(t, post, "subscribe:post:1")
+	command(t, both, "subscribe:post:1")
+
+	hub.Publish([]string{"post:1"}, []byte("comment"))
+	hub.Publish([]string{"feed", "post:1"}, []byte("post"))
+	hub.Publish(nil, []byte("ignored"))
+	hub.Broadcast([]byte("end"))
+
+	// Cada mensagem chega uma vez a cada inscrito, pela ordem em que foi publicada
+	tests := []struct {
+		name string
+		conn *websocket.Conn
+		want []string
+	}{
+		{"feed", feed, []string{"post", "end"}},
+		{"post", post, []string{"comment", "post", "end"}},
+		{"feed and post", both, []string{"comment", "post", "end"}},
+		{"no topics", none, []string{"end"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, want := range tt.want {
+				if got := readText(t, tt.conn); got != want {
+					t.Fatalf("expected %q, got %q", want, got)
+				}
+			}
+		})
+	}
+
+	command(t, post, "unsubscribe:post:1")
+	hub.Publish([]string{"post:1"}, []byte("after"))
+	hub.Broadcast([]byte("end"))
+	if got := readText(t, post); got != "end" {
+		t.Errorf("expected no messages after unsubscribing, got %q", got)
+	}
+}
+
+func TestSlowConsumerEvicted(t *testing.T) {
+	hub, server, _ := newTestHub(t, Config{SendBuffer: 1})
+
+	// O cliente lento nunca lê, por isso a ligação enche e depois a fila também
+	dial(t, server, "slow")
+	fast := dial(t, server, "fast")
+	waitConnections(t, hub, 2, 2)
+
+	payload := make([]byte, 256<<10)
+	for i := 0; i < 2000; i++ {
+		if hub.SendToUser("slow", payload) == 0 {
+			break
+		}
+	}
+	if got := hub.Metrics().SlowConsumersEvicted; got != 1 {
+		t.Fatalf("expected 1 slow consumer evicted, got %d", got)
+	}
+	waitConnections(t, hub, 1, 1)
+
+	if got := hub.SendToUser("slow", []byte("gone")); got != 0 {
+		t.Errorf("expected the evicted connection to get nothing, got %d", got)
+	}
+	if got := hub.SendToUser("fast", []byte("still here")); got != 1 {
+		t.Errorf("expected the fast connection to stay, got %d", got)
+	}
+	if got := readText(t, fast); got != "still here" {
+		t.Errorf("expected the fast connection to keep receiving, got %q", got)
+	}
+}
+
+func TestUnregisterRacesWithBroadcast(t *testing.T) {
+	hub, server, _ := newTestHub(t, Config{SendBuffer: 4})
+
+	const clients = 20
+	conns := make([]*websocket.Conn, clients)
+	for i := range conns {
+		conns[i] = dial(t, server, "user-"+string(rune('a'+i%5)), "feed")
+	}
+	waitConnections(t, hub, clients, 5)
+
+	// Difusões, envios e saídas ao mesmo tempo; o detetor de corridas faz o resto
+	ctx, stop := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				hub.Publish([]string{"feed"}, []byte("post"))
+				hub.Broadcast([]byte("all"))
+				hub.SendToUser("user-a", []byte("direct"))
+			}
+		}()
+	}
+	for _, conn := range conns {
+		wg.Add(1)
+		go func(conn *websocket.Conn) {
+			defer wg.Done()
+			conn.Close()
+		}(conn)
+	}
+
+	waitConnections(t, hub, 0, 0)
+	stop()
+	wg.Wait()
+}
+
+func TestRunClosesConnections(t *testing.T) {
+	hub, server, cancel := newTestHub(t, Config{})
+
+	conn := dial(t, server, "user-1")
+	waitConnections(t, hub, 1, 1)
+	cancel()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err := conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Errorf("expected a going away close, got %v", err)
+	}
+
+	// Depois de encerrado, o hub recusa e fecha as novas ligações
+	<-hub.done
+	late := dial(t, server, "user-2")
+	late.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, _, err := late.ReadMessage(); err == nil {
+		t.Errorf("expected the connection to be closed")
+	}
+	if got := hub.SendToUser("user-1", []byte("late")); got != 0 {
+		t.Errorf("expected nothing to be delivered after closing, got %d", got)
+	}
+	if got := hub.Metrics().Connections; got != 0 {
+		t.Errorf("expected no connections, got %d", got)
+	}
+}
+
+func TestClientAllow(t *testing.T) {
+	client := &Client{}
+
+	if !client.Allow("typing", time.Hour) {
+		t.Fatalf("expected the first typing to be allowed")
+	}
+	if client.Allow("typing", time.Hour) {
+		t.Errorf("expected a second typing within the interval to be refused")
+	}
+	if !client.Allow("other", time.Hour) {
+		t.Errorf("expected other actions to be counted separately")
+	}
+	if !client.Allow("typing", 0) {
+		t.Errorf("expected typing to be allowed once the interval passed")
+	}
+}
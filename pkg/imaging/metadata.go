@@ -0,0 +1,358 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// ErrInvalidImage indica um ficheiro que não tem a estrutura do formato declarado
+var ErrInvalidImage = errors.New("imaging: imagem inválida")
+
+// StripMetadata remove os metadados (EXIF, XMP, IPTC e comentários) de uma imagem JPEG, PNG, GIF ou WebP
+// sem voltar a codificar os pixels. Devolve também a orientação EXIF encontrada (1 quando não há).
+// Num JPEG a orientação é mantida num bloco EXIF mínimo, para que a foto continue a aparecer direita;
+// a localização GPS e os restantes campos são sempre removidos. Outros formatos são devolvidos sem alterações.
+func StripMetadata(data []byte, contentType string) ([]byte, int, error) {
+	switch contentType {
+	case "image/jpeg":
+		return stripJPEG(data)
+	case "image/png":
+		stripped, err := stripPNG(data)
+		return stripped, 1, err
+	case "image/gif":
+		stripped, err := stripGIF(data)
+		return stripped, 1, err
+	case "image/webp":
+		stripped, err := stripWebP(data)
+		return stripped, 1, err
+	default:
+		return data, 1, nil
+	}
+}
+
+// stripJPEG copia os segmentos de um JPEG até ao início dos dados da imagem,
+// deixando de fora APP1 (EXIF e XMP), APP13 (IPTC) e comentários
+func stripJPEG(data []byte) ([]byte, int, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, 0, ErrInvalidImage
+	}
+
+	orientation := 1
+	var segments [][]byte
+	pos := 2
+	for {
+		if pos >= len(data) {
+			return nil, 0, ErrInvalidImage
+		}
+		if data[pos] != 0xFF {
+			return nil, 0, ErrInvalidImage
+		}
+		// Bytes de preenchimento entre segmentos
+		if pos+1 < len(data) && data[pos+1] == 0xFF {
+			pos++
+			continue
+		}
+		if pos+1 >= len(data) {
+			return nil, 0, ErrInvalidImage
+		}
+
+		marker := data[pos+1]
+		// Marcadores sem comprimento
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			segments = append(segments, data[pos:pos+2])
+			pos += 2
+			continue
+		}
+		if pos+4 > len(data) {
+			return nil, 0, ErrInvalidImage
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, 0, ErrInvalidImage
+		}
+
+		// A partir do início dos dados (SOS) o resto do ficheiro é copiado tal como está
+		if marker == 0xDA {
+			segments = append(segments, data[pos:])
+			break
+		}
+
+		switch marker {
+		case 0xE1:
+			if o, ok := exifOrientation(data[pos+4 : end]); ok {
+				orientation = o
+			}
+		case 0xED, 0xFE:
+		default:
+			segments = append(segments, data[pos:end])
+		}
+		pos = end
+	}
+
+	var out bytes.Buffer
+	out.Grow(len(data))
+	out.Write(data[:2])
+
+	inserted := orientation == 1
+	for _, segment := range segments {
+		// O bloco EXIF fica depois do APP0 (JFIF), quando existe, como é habitual
+		if !inserted && !(len(segment) > 1 && segment[1] == 0xE0) {
+			out.Write(orientationSegment(orientation))
+			inserted = true
+		}
+		out.Write(segment)
+	}
+
+	return out.Bytes(), orientation, nil
+}
+
+// exifOrientation lê a etiqueta de orientação do IFD0 de um bloco APP1 EXIF
+func exifOrientation(app1 []byte) (int, bool) {
+	if !bytes.HasPrefix(app1, []byte("Exif\x00\x00")) {
+		return 0, false
+	}
+	tiff := app1[6:]
+	if len(tiff) < 8 {
+		return 0, false
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0, false
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 0, false
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0, false
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 0, false
+			}
+			return orientation, true
+		}
+	}
+	return 0, false
+}
+
+// orientationSegment cria um bloco APP1 EXIF apenas com a orientação
+func orientationSegment(orientation int) []byte {
+	segment := []byte{
+		0xFF, 0xE1, 0x00, 0x22,
+		'E', 'x', 'i', 'f', 0x00, 0x00,
+		// Cabeçalho TIFF big-endian com o IFD0 logo a seguir
+		'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08,
+		// Uma entrada: orientação, SHORT, 1 valor
+		0x00, 0x01,
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00,
+		// Sem IFD seguinte
+		0x00, 0x00, 0x00, 0x00,
+	}
+	binary.BigEndian.PutUint16(segment[28:], uint16(orientation))
+	return segment
+}
+
+// pngMetadataChunks são os blocos de um PNG com metadados de texto, EXIF ou data
+var pngMetadataChunks = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"tIME": true,
+}
+
+// stripPNG remove os blocos de metadados de um PNG
+func stripPNG(data []byte) ([]byte, error) {
+	signature := []byte("\x89PNG\r\n\x1a\n")
+	if !bytes.HasPrefix(data, signature) {
+		return nil, ErrInvalidImage
+	}
+
+	var out bytes.Buffer
+	out.Grow(len(data))
+	out.Write(signature)
+
+	pos := len(signature)
+	for pos < len(data) {
+		if pos+8 > len(data) {
+			return nil, ErrInvalidImage
+		}
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, ErrInvalidImage
+		}
+
+		if !pngMetadataChunks[string(data[pos+4:pos+8])] {
+			out.Write(data[pos:end])
+		}
+		pos = end
+	}
+
+	return out.Bytes(), nil
+}
+
+// Bits do cabeçalho VP8X que anunciam blocos EXIF e XMP
+const (
+	vp8xFlagEXIF = 0x08
+	vp8xFlagXMP  = 0x04
+)
+
+// stripWebP remove os blocos EXIF e XMP de um WebP e atualiza os tamanhos e o cabeçalho VP8X
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, ErrInvalidImage
+	}
+
+	var out bytes.Buffer
+	out.Grow(len(data))
+	out.Write(data[:12])
+
+	pos := 12
+	for pos < len(data) {
+		if pos+8 > len(data) {
+			return nil, ErrInvalidImage
+		}
+		fourCC := string(data[pos : pos+4])
+		length := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + length + length%2
+		if length < 0 || end > len(data) {
+			return nil, ErrInvalidImage
+		}
+
+		switch fourCC {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := bytes.Clone(data[pos:end])
+			if length > 0 {
+				chunk[8] &^= vp8xFlagEXIF | vp8xFlagXMP
+			}
+			out.Write(chunk)
+		default:
+			out.Write(data[pos:end])
+		}
+		pos = end
+	}
+
+	stripped := out.Bytes()
+	binary.LittleEndian.PutUint32(stripped[4:], uint32(len(stripped)-8))
+	return stripped, nil
+}
+
+// Blocos de um GIF
+const (
+	gifExtension   = 0x21
+	gifImage       = 0x2C
+	gifTrailer     = 0x3B
+	gifComment     = 0xFE
+	gifApplication = 0xFF
+)
+
+// gifLoopApplications são as extensões de aplicação que controlam a repetição das animações;
+// todas as outras, como o XMP, são metadados
+var gifLoopApplications = map[string]bool{
+	"NETSCAPE2.0": true,
+	"ANIMEXTS1.0": true,
+}
+
+// stripGIF remove de um GIF os comentários, as extensões de aplicação que não controlam a animação
+// e tudo o que vier depois do fim do ficheiro
+func stripGIF(data []byte) ([]byte, error) {
+	if len(data) < 13 || (string(data[:6]) != "GIF87a" && string(data[:6]) != "GIF89a") {
+		return nil, ErrInvalidImage
+	}
+
+	pos := 13 + gifColorTableSize(data[10])
+	if pos > len(data) {
+		return nil, ErrInvalidImage
+	}
+
+	var out bytes.Buffer
+	out.Grow(len(data))
+	out.Write(data[:pos])
+
+	for pos < len(data) {
+		start := pos
+		switch data[pos] {
+		case gifTrailer:
+			out.WriteByte(gifTrailer)
+			return out.Bytes(), nil
+		case gifExtension:
+			if pos+2 > len(data) {
+				return nil, ErrInvalidImage
+			}
+			end, err := gifSubBlocks(data, pos+2)
+			if err != nil {
+				return nil, err
+			}
+			if !gifMetadata(data[pos+1], data[pos+2:end]) {
+				out.Write(data[start:end])
+			}
+			pos = end
+		case gifImage:
+			// Descritor da imagem, tabela de cores local e tamanho mínimo do código LZW
+			if pos+10 > len(data) {
+				return nil, ErrInvalidImage
+			}
+			end, err := gifSubBlocks(data, pos+10+gifColorTableSize(data[pos+9])+1)
+			if err != nil {
+				return nil, err
+			}
+			out.Write(data[start:end])
+			pos = end
+		default:
+			return nil, ErrInvalidImage
+		}
+	}
+
+	return nil, ErrInvalidImage
+}
+
+// gifColorTableSize devolve o tamanho da tabela de cores anunciada no campo de bits de um descritor
+func gifColorTableSize(flags byte) int {
+	if flags&0x80 == 0 {
+		return 0
+	}
+	return 3 << (flags&0x07 + 1)
+}
+
+// gifSubBlocks salta a sequência de sub-blocos que começa em pos e devolve a posição a seguir ao terminador
+func gifSubBlocks(data []byte, pos int) (int, error) {
+	for {
+		if pos >= len(data) {
+			return 0, ErrInvalidImage
+		}
+		size := int(data[pos])
+		pos++
+		if size == 0 {
+			return pos, nil
+		}
+		pos += size
+	}
+}
+
+// gifMetadata indica se a extensão com o rótulo e os sub-blocos indicados é um metadado
+func gifMetadata(label byte, blocks []byte) bool {
+	switch label {
+	case gifComment:
+		return true
+	case gifApplication:
+		return len(blocks) < 12 || blocks[0] != 11 || !gifLoopApplications[string(blocks[1:12])]
+	default:
+		return false
+	}
+}
@@ -0,0 +1,356 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// secret simula a localização GPS e outros dados pessoais guardados nos metadados
+const secret = "GPS -25.9692 32.5732"
+
+// testImage cria uma imagem opaca com uma cor diferente em cada pixel
+func testImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 10), G: uint8(y * 10), B: 100, A: 255})
+		}
+	}
+	return img
+}
+
+// segment monta um segmento JPEG com o marcador e o conteúdo indicados
+func segment(marker byte, payload []byte) []byte {
+	out := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(out[2:], uint16(len(payload)+2))
+	return append(out, payload...)
+}
+
+// exifPayload monta um bloco EXIF com a orientação no IFD0, seguido de dados extra
+func exifPayload(order binary.ByteOrder, orientation int, extra string) []byte {
+	tiff := make([]byte, 8+2+12+4)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 0x2A)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)
+	order.PutUint16(tiff[10:], 0x0112)
+	order.PutUint16(tiff[12:], 3)
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], uint16(orientation))
+
+	return append(append([]byte("Exif\x00\x00"), tiff...), extra...)
+}
+
+// jpegWith codifica uma imagem em JPEG e junta os segmentos indicados logo depois do SOI
+func jpegWith(t *testing.T, segments ...[]byte) ([]byte, []byte) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(8, 4), nil); err != nil {
+		t.Fatalf("jpeg.Encode: %v", err)
+	}
+	clean := buf.Bytes()
+
+	data := append([]byte{}, clean[:2]...)
+	for _, s := range segments {
+		data = append(data, s...)
+	}
+	return append(data, clean[2:]...), clean
+}
+
+func TestStripJPEG(t *testing.T) {
+	tests := []struct {
+		name            string
+		segments        [][]byte
+		wantOrientation int
+	}{
+		{"no metadata", nil, 1},
+		{"exif without rotation", [][]byte{segment(0xE1, exifPayload(binary.BigEndian, 1, secret))}, 1},
+		{"exif big endian rotated", [][]byte{segment(0xE1, exifPayload(binary.BigEndian, 6, secret))}, 6},
+		{"exif little endian rotated", [][]byte{segment(0xE1, exifPayload(binary.LittleEndian, 8, secret))}, 8},
+		{"invalid orientation", [][]byte{segment(0xE1, exifPayload(binary.LittleEndian, 9, secret))}, 1},
+		{"xmp", [][]byte{segment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta>"+secret+"</x:xmpmeta>"))}, 1},
+		{"iptc and comment", [][]byte{segment(0xED, []byte("Photoshop 3.0\x00"+secret)), segment(0xFE, []byte(secret))}, 1},
+		{"jfif kept before exif", [][]byte{segment(0xE0, []byte("JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00")), segment(0xE1, exifPayload(binary.BigEndian, 3, secret))}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, clean := jpegWith(t, tt.segments...)
+
+			stripped, orientation, err := StripMetadata(data, "image/jpeg")
+			if err != nil {
+				t.Fatalf("StripMetadata: %v", err)
+			}
+			if orientation != tt.wantOrientation {
+				t.Errorf("expected orientation %d, got %d", tt.wantOrientation, orientation)
+			}
+			if bytes.Contains(stripped, []byte(secret)) {
+				t.Errorf("expected the metadata to be removed")
+			}
+			if _, err := jpeg.Decode(bytes.NewReader(stripped)); err != nil {
+				t.Errorf("expected a valid JPEG, got %v", err)
+			}
+			// Os pixels não são codificados de novo
+			if !bytes.HasSuffix(stripped, clean[2:]) {
+				t.Errorf("expected the image data to be copied unchanged")
+			}
+
+			// A orientação fica num bloco EXIF mínimo, e só quando roda a imagem
+			_, kept, err := StripMetadata(stripped, "image/jpeg")
+			if err != nil {
+				t.Fatalf("StripMetadata of the stripped image: %v", err)
+			}
+			if kept != tt.wantOrientation {
+				t.Errorf("expected the stripped image to keep orientation %d, got %d", tt.wantOrientation, kept)
+			}
+			if tt.wantOrientation == 1 && bytes.Contains(stripped, []byte("Exif\x00\x00")) {
+				t.Errorf("expected no EXIF block without rotation")
+			}
+			if tt.segments != nil && len(tt.segments[0]) > 1 && tt.segments[0][1] == 0xE0 {
+				if !bytes.HasPrefix(stripped[2:], tt.segments[0]) {
+					t.Errorf("expected the JFIF block to stay first")
+				}
+			}
+		})
+	}
+}
+
+func TestStripJPEGInvalid(t *testing.T) {
+	data, _ := jpegWith(t, segment(0xE1, exifPayload(binary.BigEndian, 6, secret)))
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"not a jpeg", []byte("GIF89a......")},
+		{"truncated segment", data[:10]},
+		{"no image data", data[:2+len(segment(0xE1, exifPayload(binary.BigEndian, 6, secret)))]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := StripMetadata(tt.data, "image/jpeg"); !errors.Is(err, ErrInvalidImage) {
+				t.Errorf("expected ErrInvalidImage, got %v", err)
+			}
+		})
+	}
+}
+
+// pngChunk monta um bloco PNG com o tipo e o conteúdo indicados
+func pngChunk(kind string, payload []byte) []byte {
+	out := make([]byte, 8, 12+len(payload))
+	binary.BigEndian.PutUint32(out, uint32(len(payload)))
+	copy(out[4:], kind)
+	out = append(out, payload...)
+	return binary.BigEndian.AppendUint32(out, crc32.ChecksumIEEE(out[4:]))
+}
+
+func TestStripPNG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage(8, 4)); err != nil {
+		t.Fatalf("png.Encode: %v", err)
+	}
+	clean := buf.Bytes()
+	// O IHDR vem logo a seguir à assinatura e ocupa 25 bytes
+	header := 8 + 25
+
+	tests := []struct {
+		name   string
+		chunks [][]byte
+	}{
+		{"no metadata", nil},
+		{"text", [][]byte{pngChunk("tEXt", []byte("Comment\x00"+secret))}},
+		{"compressed and international text", [][]byte{pngChunk("zTXt", []byte("Author\x00\x00"+secret)), pngChunk("iTXt", []byte("Location\x00\x00\x00\x00\x00"+secret))}},
+		{"exif and time", [][]byte{pngChunk("eXIf", exifPayload(binary.BigEndian, 6, secret)[6:]), pngChunk("tIME", []byte{0x07, 0xEA, 10, 16, 12, 0, 0})}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := append([]byte{}, clean[:header]...)
+			for _, chunk := range tt.chunks {
+				data = append(data, chunk...)
+			}
+			data = append(data, clean[header:]...)
+
+			stripped, orientation, err := StripMetadata(data, "image/png")
+			if err != nil {
+				t.Fatalf("StripMetadata: %v", err)
+			}
+			if orientation != 1 {
+				t.Errorf("expected orientation 1, got %d", orientation)
+			}
+			if !bytes.Equal(stripped, clean) {
+				t.Errorf("expected only the metadata chunks to be removed")
+			}
+			if _, err := png.Decode(bytes.NewReader(stripped)); err != nil {
+				t.Errorf("expected a valid PNG, got %v", err)
+			}
+		})
+	}
+
+	if _, _, err := StripMetadata(clean[:header+4], "image/png"); !errors.Is(err, ErrInvalidImage) {
+		t.Errorf("expected ErrInvalidImage for a truncated PNG, got %v", err)
+	}
+}
+
+// riffChunk monta um bloco RIFF, com o byte de preenchimento quando o tamanho é ímpar
+func riffChunk(fourCC string, payload []byte) []byte {
+	out := make([]byte, 8, 9+len(payload))
+	copy(out, fourCC)
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(payload)))
+	out = append(out, payload...)
+	if len(payload)%2 == 1 {
+		out = append(out, 0)
+	}
+	return out
+}
+
+// webp monta um ficheiro WebP com os blocos indicados
+func webp(chunks ...[]byte) []byte {
+	out := []byte("RIFF\x00\x00\x00\x00WEBP")
+	for _, chunk := range chunks {
+		out = append(out, chunk...)
+	}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out
+}
+
+func TestStripWebP(t *testing.T) {
+	bitstream := riffChunk("VP8L", []byte{0x2F, 0x07, 0xC0, 0x00, 0x00, 0x01})
+	vp8x := func(flags byte) []byte {
+		return riffChunk("VP8X", []byte{flags, 0, 0, 0, 7, 0, 0, 3, 0, 0})
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want []byte
+	}{
+		{"simple format", webp(bitstream), webp(bitstream)},
+		{"extended format without metadata", webp(vp8x(0x10), bitstream), webp(vp8x(0x10), bitstream)},
+		{"exif and xmp", webp(vp8x(0x10|vp8xFlagEXIF|vp8xFlagXMP), bitstream, riffChunk("EXIF", exifPayload(binary.LittleEndian, 6, secret)[6:]), riffChunk("XMP ", []byte("<x:xmpmeta>"+secret+"</x:xmpmeta>!"))), webp(vp8x(0x10), bitstream)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stripped, orientation, err := StripMetadata(tt.data, "image/webp")
+			if err != nil {
+				t.Fatalf("StripMetadata: %v", err)
+			}
+			if orientation != 1 {
+				t.Errorf("expected orientation 1, got %d", orientation)
+			}
+			if !bytes.Equal(stripped, tt.want) {
+				t.Errorf("expected %x, got %x", tt.want, stripped)
+			}
+		})
+	}
+
+	for _, data := range [][]byte{[]byte("RIFF\x00\x00\x00\x00WAVE"), webp(bitstream)[:len(webp(bitstream))-2]} {
+		if _, _, err := StripMetadata(data, "image/webp"); !errors.Is(err, ErrInvalidImage) {
+			t.Errorf("expected ErrInvalidImage, got %v", err)
+		}
+	}
+}
+
+// gifExtensionBlock monta uma extensão GIF com o rótulo e os sub-blocos indicados
+func gifExtensionBlock(label byte, blocks ...string) []byte {
+	out := []byte{gifExtension, label}
+	for _, block := range blocks {
+		out = append(out, byte(len(block)))
+		out = append(out, block...)
+	}
+	return append(out, 0)
+}
+
+// gifWith junta as extensões indicadas logo depois da tabela de cores global e os dados indicados
+// depois do fim do ficheiro
+func gifWith(data []byte, trailing string, extensions ...[]byte) []byte {
+	pos := 13 + gifColorTableSize(data[10])
+	out := append([]byte{}, data[:pos]...)
+	for _, extension := range extensions {
+		out = append(out, extension...)
+	}
+	out = append(out, data[pos:]...)
+	return append(out, trailing...)
+}
+
+func TestStripGIF(t *testing.T) {
+	palette := color.Palette{color.Black, color.White}
+	frame := image.NewPaletted(image.Rect(0, 0, 4, 2), palette)
+	var still, animated bytes.Buffer
+	if err := gif.Encode(&still, frame, nil); err != nil {
+		t.Fatalf("gif.Encode: %v", err)
+	}
+	if err := gif.EncodeAll(&animated, &gif.GIF{Image: []*image.Paletted{frame, frame}, Delay: []int{10, 10}}); err != nil {
+		t.Fatalf("gif.EncodeAll: %v", err)
+	}
+
+	comment := gifExtensionBlock(gifComment, secret)
+	xmp := gifExtensionBlock(gifApplication, "XMP DataXMP", "<x:xmpmeta>"+secret+"</x:xmpmeta>")
+
+	tests := []struct {
+		name string
+		data []byte
+		want []byte
+	}{
+		{"without metadata", still.Bytes(), still.Bytes()},
+		{"comment and xmp", gifWith(still.Bytes(), "", comment, xmp), still.Bytes()},
+		{"data after the trailer", gifWith(still.Bytes(), secret), still.Bytes()},
+		{"animation keeps the loop", gifWith(animated.Bytes(), "", comment), animated.Bytes()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stripped, orientation, err := StripMetadata(tt.data, "image/gif")
+			if err != nil {
+				t.Fatalf("StripMetadata: %v", err)
+			}
+			if orientation != 1 {
+				t.Errorf("expected orientation 1, got %d", orientation)
+			}
+			if !bytes.Equal(stripped, tt.want) {
+				t.Errorf("expected %x, got %x", tt.want, stripped)
+			}
+			if _, err := gif.DecodeAll(bytes.NewReader(stripped)); err != nil {
+				t.Errorf("decode stripped gif: %v", err)
+			}
+		})
+	}
+
+	if !bytes.Contains(animated.Bytes(), []byte("NETSCAPE2.0")) {
+		t.Errorf("expected the animation to have a loop extension")
+	}
+
+	for _, data := range [][]byte{[]byte("GIF89a" + secret), still.Bytes()[:still.Len()-1], []byte("PNG89a\x04\x00\x02\x00\x00\x00\x00;")} {
+		if _, _, err := StripMetadata(data, "image/gif"); !errors.Is(err, ErrInvalidImage) {
+			t.Errorf("expected ErrInvalidImage, got %v", err)
+		}
+	}
+}
+
+func TestStripMetadataOtherFormats(t *testing.T) {
+	data := []byte("II*\x00" + secret)
+
+	stripped, orientation, err := StripMetadata(data, "image/tiff")
+	if err != nil {
+		t.Fatalf("StripMetadata: %v", err)
+	}
+	if !bytes.Equal(stripped, data) || orientation != 1 {
+		t.Errorf("expected other formats to be returned unchanged")
+	}
+}
@@ -0,0 +1,230 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"slices"
+
+	// Formatos que é possível descodificar para gerar miniaturas
+	_ "image/gif"
+
+	_ "golang.org/x/image/webp"
+)
+
+// ErrUnsupportedFormat indica um formato que não é possível descodificar
+var ErrUnsupportedFormat = errors.New("imaging: formato não suportado")
+
+// maxPixels limita o tamanho das imagens descodificadas, para que uma imagem pequena
+// em bytes mas enorme em pixels não esgote a memória
+const maxPixels = 50_000_000
+
+// thumbnailQuality é a qualidade JPEG das miniaturas, pensadas para ligações lentas
+const thumbnailQuality = 75
+
+// Thumbnail é uma miniatura codificada, sem metadados
+type Thumbnail struct {
+	Width       int
+	Height      int
+	ContentType string
+	Data        []byte
+}
+
+// Thumbnails gera miniaturas com as larguras indicadas, apenas as mais estreitas que a imagem.
+// A orientação EXIF é aplicada aos pixels; devolve também as dimensões da imagem já orientada.
+// Imagens opacas são codificadas em JPEG e as restantes em PNG, para manter a transparência.
+func Thumbnails(data []byte, orientation int, widths []int) (int, int, []Thumbnail, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if errors.Is(err, image.ErrFormat) {
+		return 0, 0, nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxPixels {
+		return 0, 0, nil, ErrInvalidImage
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	src := toRGBA(img)
+
+	width, height := src.Bounds().Dx(), src.Bounds().Dy()
+	rotated := orientation >= 5 && orientation <= 8
+	if rotated {
+		width, height = height, width
+	}
+
+	widths = slices.Clone(widths)
+	slices.Sort(widths)
+	widths = slices.Compact(widths)
+
+	var thumbnails []Thumbnail
+	for _, w := range widths {
+		if w <= 0 || w >= width {
+			continue
+		}
+		h := max(1, int(math.Round(float64(height)*float64(w)/float64(width))))
+
+		var resized *image.RGBA
+		if rotated {
+			resized = resize(src, h, w)
+		} else {
+			resized = resize(src, w, h)
+		}
+
+		thumbnail, err := encode(orient(resized, orientation))
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		thumbnails = append(thumbnails, thumbnail)
+	}
+
+	return width, height, thumbnails, nil
+}
+
+// encode codifica a miniatura em JPEG, ou em PNG quando tem transparência
+func encode(img *image.RGBA) (Thumbnail, error) {
+	var buf bytes.Buffer
+	thumbnail := Thumbnail{Width: img.Bounds().Dx(), Height: img.Bounds().Dy()}
+
+	if img.Opaque() {
+		thumbnail.ContentType = "image/jpeg"
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+			return Thumbnail{}, err
+		}
+	} else {
+		thumbnail.ContentType = "image/png"
+		if err := png.Encode(&buf, img); err != nil {
+			return Thumbnail{}, err
+		}
+	}
+
+	thumbnail.Data = buf.Bytes()
+	return thumbnail, nil
+}
+
+// toRGBA copia a imagem para RGBA com origem em (0, 0)
+func toRGBA(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Src)
+	return dst
+}
+
+// resize reduz a imagem pela média das áreas: cada pixel de destino é a média ponderada
+// dos pixels de origem que cobre, primeiro na horizontal e depois na vertical
+func resize(src *image.RGBA, width, height int) *image.RGBA {
+	srcW, srcH := src.Bounds().Dx(), src.Bounds().Dy()
+
+	tmp := image.NewRGBA(image.Rect(0, 0, width, srcH))
+	for x, c := range areaWeights(srcW, width) {
+		for y := 0; y < srcH; y++ {
+			var sum [4]float64
+			for i, weight := range c.weights {
+				offset := src.PixOffset(c.start+i, y)
+				for k := 0; k < 4; k++ {
+					sum[k] += float64(src.Pix[offset+k]) * weight
+				}
+			}
+			setPixel(tmp, x, y, sum)
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y, c := range areaWeights(srcH, height) {
+		for x := 0; x < width; x++ {
+			var sum [4]float64
+			for i, weight := range c.weights {
+				offset := tmp.PixOffset(x, c.start+i)
+				for k := 0; k < 4; k++ {
+					sum[k] += float64(tmp.Pix[offset+k]) * weight
+				}
+			}
+			setPixel(dst, x, y, sum)
+		}
+	}
+
+	return dst
+}
+
+// contribution são os pixels de origem que cobrem um pixel de destino e o peso de cada um
+type contribution struct {
+	start   int
+	weights []float64
+}
+
+// areaWeights calcula as contribuições de uma dimensão de srcSize pixels reduzida para dstSize
+func areaWeights(srcSize, dstSize int) []contribution {
+	scale := float64(srcSize) / float64(dstSize)
+	contributions := make([]contribution, dstSize)
+
+	for i := range contributions {
+		left := float64(i) * scale
+		right := left + scale
+		start := int(left)
+		end := min(int(math.Ceil(right)), srcSize)
+
+		weights := make([]float64, end-start)
+		for j := start; j < end; j++ {
+			covered := math.Min(right, float64(j+1)) - math.Max(left, float64(j))
+			weights[j-start] = covered / scale
+		}
+		contributions[i] = contribution{start: start, weights: weights}
+	}
+
+	return contributions
+}
+
+// setPixel grava um pixel a partir das somas ponderadas de cada canal
+func setPixel(img *image.RGBA, x, y int, sum [4]float64) {
+	offset := img.PixOffset(x, y)
+	for k := 0; k < 4; k++ {
+		img.Pix[offset+k] = uint8(math.Min(255, math.Round(sum[k])))
+	}
+}
+
+// orient aplica aos pixels a orientação EXIF (1 a 8)
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dstW, dstH := w, h
+	if orientation >= 5 {
+		dstW, dstH = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		for x := 0; x < dstW; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+
+	return dst
+}
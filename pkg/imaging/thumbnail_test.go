@@ -0,0 +1,186 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func TestOrient(t *testing.T) {
+	// Imagem 3x2 em que cada pixel guarda as suas coordenadas em R e G
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			src.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), A: 255})
+		}
+	}
+
+	tests := []struct {
+		orientation   int
+		width, height int
+		// Pixels da imagem original que ficam em (0, 0) e (1, 0)
+		first, second image.Point
+	}{
+		{1, 3, 2, image.Pt(0, 0), image.Pt(1, 0)},
+		{2, 3, 2, image.Pt(2, 0), image.Pt(1, 0)},
+		{3, 3, 2, image.Pt(2, 1), image.Pt(1, 1)},
+		{4, 3, 2, image.Pt(0, 1), image.Pt(1, 1)},
+		{5, 2, 3, image.Pt(0, 0), image.Pt(0, 1)},
+		{6, 2, 3, image.Pt(0, 1), image.Pt(0, 0)},
+		{7, 2, 3, image.Pt(2, 1), image.Pt(2, 0)},
+		{8, 2, 3, image.Pt(2, 0), image.Pt(2, 1)},
+		{0, 3, 2, image.Pt(0, 0), image.Pt(1, 0)},
+		{9, 3, 2, image.Pt(0, 0), image.Pt(1, 0)},
+	}
+
+	for _, tt := range tests {
+		dst := orient(src, tt.orientation)
+
+		if dst.Bounds().Dx() != tt.width || dst.Bounds().Dy() != tt.height {
+			t.Errorf("orientation %d: expected %dx%d, got %dx%d", tt.orientation, tt.width, tt.height, dst.Bounds().Dx(), dst.Bounds().Dy())
+			continue
+		}
+		for i, want := range []image.Point{tt.first, tt.second} {
+			c := dst.RGBAAt(i, 0)
+			if got := image.Pt(int(c.R), int(c.G)); got != want {
+				t.Errorf("orientation %d: expected pixel (%d, 0) from %v, got %v", tt.orientation, i, want, got)
+			}
+		}
+	}
+}
+
+// vp8l monta um WebP sem perdas de uma só cor. Cada canal usa um código de Huffman com um único
+// símbolo, por isso os pixels não ocupam nenhum bit.
+func vp8l(width, height int, c color.NRGBA) []byte {
+	var out []byte
+	var acc uint64
+	var n uint
+	write := func(value uint32, bits uint) {
+		acc |= uint64(value) << n
+		for n += bits; n >= 8; n -= 8 {
+			out = append(out, byte(acc))
+			acc >>= 8
+		}
+	}
+
+	write(0x2F, 8)
+	write(uint32(width-1), 14)
+	write(uint32(height-1), 14)
+	// Indicação de transparência e versão
+	write(0, 4)
+	// Sem transformações, cache de cores nem códigos por região
+	write(0, 3)
+	for _, symbol := range []uint8{c.G, c.R, c.B, c.A, 0} {
+		write(1, 1)
+		write(0, 1)
+		write(1, 1)
+		write(uint32(symbol), 8)
+	}
+	if n > 0 {
+		out = append(out, byte(acc))
+	}
+
+	return webp(riffChunk("VP8L", out))
+}
+
+func TestThumbnails(t *testing.T) {
+	var opaque bytes.Buffer
+	if err := png.Encode(&opaque, testImage(40, 20)); err != nil {
+		t.Fatalf("png.Encode: %v", err)
+	}
+	transparent := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	var translucent bytes.Buffer
+	if err := png.Encode(&translucent, transparent); err != nil {
+		t.Fatalf("png.Encode: %v", err)
+	}
+
+	type size struct{ width, height int }
+	tests := []struct {
+		name          string
+		data          []byte
+		orientation   int
+		widths        []int
+		width, height int
+		want          []size
+		contentType   string
+	}{
+		{"narrower widths only", opaque.Bytes(), 1, []int{20, 10, 40, 80, 10}, 40, 20, []size{{10, 5}, {20, 10}}, "image/jpeg"},
+		{"rotated", opaque.Bytes(), 6, []int{10, 30}, 20, 40, []size{{10, 20}}, "image/jpeg"},
+		{"mirrored keeps the size", opaque.Bytes(), 2, []int{10}, 40, 20, []size{{10, 5}}, "image/jpeg"},
+		{"transparency stays in png", translucent.Bytes(), 1, []int{10}, 40, 20, []size{{10, 5}}, "image/png"},
+		{"webp", vp8l(40, 20, color.NRGBA{R: 200, G: 100, B: 50, A: 255}), 1, []int{10}, 40, 20, []size{{10, 5}}, "image/jpeg"},
+		{"translucent webp", vp8l(40, 20, color.NRGBA{R: 200, A: 128}), 1, []int{10}, 40, 20, []size{{10, 5}}, "image/png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width, height, thumbnails, err := Thumbnails(tt.data, tt.orientation, tt.widths)
+			if err != nil {
+				t.Fatalf("Thumbnails: %v", err)
+			}
+			if width != tt.width || height != tt.height {
+				t.Errorf("expected %dx%d, got %dx%d", tt.width, tt.height, width, height)
+			}
+			if len(thumbnails) != len(tt.want) {
+				t.Fatalf("expected %d thumbnails, got %d", len(tt.want), len(thumbnails))
+			}
+
+			for i, thumbnail := range thumbnails {
+				if thumbnail.Width != tt.want[i].width || thumbnail.Height != tt.want[i].height {
+					t.Errorf("expected thumbnail %dx%d, got %dx%d", tt.want[i].width, tt.want[i].height, thumbnail.Width, thumbnail.Height)
+				}
+				if thumbnail.ContentType != tt.contentType {
+					t.Errorf("expected %s, got %s", tt.contentType, thumbnail.ContentType)
+				}
+
+				decoded, _, err := image.Decode(bytes.NewReader(thumbnail.Data))
+				if err != nil {
+					t.Fatalf("decode thumbnail: %v", err)
+				}
+				if decoded.Bounds().Dx() != thumbnail.Width || decoded.Bounds().Dy() != thumbnail.Height {
+					t.Errorf("expected the encoded thumbnail to be %dx%d, got %v", thumbnail.Width, thumbnail.Height, decoded.Bounds())
+				}
+			}
+		})
+	}
+}
+
+func TestThumbnailsUnsupported(t *testing.T) {
+	_, _, _, err := Thumbnails([]byte("II*\x00"+secret), 1, []int{10})
+	if !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("expected ErrUnsupportedFormat, got %v", err)
+	}
+}
+
+func TestThumbnailsOfStrippedJPEG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(40, 20), nil); err != nil {
+		t.Fatalf("jpeg.Encode: %v", err)
+	}
+	data := append([]byte{0xFF, 0xD8}, segment(0xE1, exifPayload(binary.BigEndian, 8, secret))...)
+	data = append(data, buf.Bytes()[2:]...)
+
+	// O fluxo do upload: metadados removidos, depois miniaturas já direitas
+	stripped, orientation, err := StripMetadata(data, "image/jpeg")
+	if err != nil {
+		t.Fatalf("StripMetadata: %v", err)
+	}
+	width, height, thumbnails, err := Thumbnails(stripped, orientation, []int{10})
+	if err != nil {
+		t.Fatalf("Thumbnails: %v", err)
+	}
+	if width != 20 || height != 40 {
+		t.Errorf("expected 20x40, got %dx%d", width, height)
+	}
+	if len(thumbnails) != 1 || thumbnails[0].Width != 10 || thumbnails[0].Height != 20 {
+		t.Errorf("expected one 10x20 thumbnail, got %+v", thumbnails)
+	}
+	if bytes.Contains(thumbnails[0].Data, []byte("Exif")) {
+		t.Errorf("expected thumbnails without metadata")
+	}
+}
@@ -0,0 +1,161 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/anamalala/pkg/logger"
+)
+
+const africasTalkingDefaultURL = "https://api.africastalking.com"
+
+// AfricasTalkingProvider implementa o provedor AfricasTalking
+type AfricasTalkingProvider struct {
+	APIKey   string
+	Username string
+	SenderID string
+	BaseURL  string
+	client   *http.Client
+	logger   *logger.Logger
+}
+
+// africasTalkingResponse representa a resposta do endpoint de envio
+type africasTalkingResponse struct {
+	SMSMessageData struct {
+		Message    string `json:"Message"`
+		Recipients []struct {
+			StatusCode int    `json:"statusCode"`
+			Number     string `json:"number"`
+			Status     string `json:"status"`
+			Cost       string `json:"cost"`
+			MessageID  string `json:"messageId"`
+		} `json:"Recipients"`
+	} `json:"SMSMessageData"`
+}
+
+func (p *AfricasTalkingProvider) Send(ctx context.Context, recipient, message string) (string, error) {
+	baseURL := p.BaseURL
+	if baseURL == "" {
+		baseURL = africasTalkingDefaultURL
+	}
+
+	form := url.Values{}
+	form.Set("username", p.Username)
+	form.Set("to", formatRecipient(recipient))
+	form.Set("message", message)
+	if p.SenderID != "" {
+		form.Set("from", p.SenderID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(baseURL, "/")+"/version1/messaging", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("apiKey", p.APIKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", &ProviderError{Provider: "africastalking", Code: "network", Message: err.Error(), Temporary: true, Err: ErrUnavailable}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", &ProviderError{Provider: "africastalking", Code: "network", Message: err.Error(), Temporary: true, Err: ErrUnavailable}
+	}
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return "", africasTalkingHTTPError(resp.StatusCode, string(body))
+	}
+
+	var result africasTalkingResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", &ProviderError{Provider: "africastalking", Code: "decode", Message: err.Error(), Err: ErrRejected}
+	}
+
+	if len(result.SMSMessageData.Recipients) == 0 {
+		// Sem destinatários aceites, o motivo vem apenas no texto da mensagem
+		return "", &ProviderError{Provider: "africastalking", Code: "no_recipients", Message: result.SMSMessageData.Message, Err: ErrInvalidRecipient}
+	}
+
+	status := result.SMSMessageData.Recipients[0]
+	if err := africasTalkingStatusError(status.StatusCode, status.Status); err != nil {
+		return "", err
+	}
+
+	p.logger.Debug("africas_talking_sent",
+		"recipient", status.Number,
+		"message_id", status.MessageID,
+		"cost", status.Cost,
+	)
+
+	return status.MessageID, nil
+}
+
+// africasTalkingHTTPError converte erros HTTP da AfricasTalking
+func africasTalkingHTTPError(statusCode int, body string) error {
+	providerErr := &ProviderError{
+		Provider: "africastalking",
+		Code:     strconv.Itoa(statusCode),
+		Message:  strings.TrimSpace(body),
+	}
+
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		providerErr.Err = ErrAuthentication
+	case statusCode == http.StatusTooManyRequests:
+		providerErr.Err = ErrRateLimited
+		providerErr.Temporary = true
+	case statusCode >= 500:
+		providerErr.Err = ErrUnavailable
+		providerErr.Temporary = true
+	default:
+		providerErr.Err = ErrRejected
+	}
+
+	return providerErr
+}
+
+// africasTalkingStatusError converte o statusCode de cada destinatário.
+// 100, 101 e 102 indicam mensagem aceite (processada, enviada ou em fila).
+func africasTalkingStatusError(statusCode int, status string) error {
+	var kind error
+	temporary := false
+
+	switch statusCode {
+	case 100, 101, 102:
+		return nil
+	case 401:
+		kind = ErrRejected // RiskHold
+	case 402:
+		kind = ErrInvalidSender
+	case 403, 404:
+		kind = ErrInvalidRecipient
+	case 405:
+		kind = ErrInsufficientBalance
+	case 406:
+		kind = ErrBlacklisted
+	case 407:
+		kind = ErrRejected // CouldNotRoute
+	case 500, 501:
+		kind = ErrUnavailable
+		temporary = true
+	default:
+		kind = ErrRejected
+	}
+
+	return &ProviderError{
+		Provider:  "africastalking",
+		Code:      strconv.Itoa(statusCode),
+		Message:   status,
+		Temporary: temporary,
+		Err:       kind,
+	}
+}
@@ -0,0 +1,160 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/anamalala/pkg/logger"
+)
+
+// newAfricasTalkingTestProvider cria um provedor AfricasTalking apontado para o servidor de teste
+func newAfricasTalkingTestProvider(server *httptest.Server) *AfricasTalkingProvider {
+	return &AfricasTalkingProvider{
+		APIKey:   "key",
+		Username: "sandbox",
+		SenderID: "ANAMALALA",
+		BaseURL:  server.URL,
+		client:   server.Client(),
+		logger:   logger.NewLogger("production"),
+	}
+}
+
+func TestAfricasTalkingSendSuccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/version1/messaging" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if got := r.Header.Get("apiKey"); got != "key" {
+			t.Errorf("expected apiKey header, got %q", got)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("ParseForm: %v", err)
+		}
+		if got := r.PostForm.Get("username"); got != "sandbox" {
+			t.Errorf("expected username sandbox, got %q", got)
+		}
+		if got := r.PostForm.Get("to"); got != "+258841234567" {
+			t.Errorf("expected to +258841234567, got %q", got)
+		}
+		if got := r.PostForm.Get("from"); got != "ANAMALALA" {
+			t.Errorf("expected from ANAMALALA, got %q", got)
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"SMSMessageData":{"Message":"Sent to 1/1","Recipients":[
+			{"statusCode":101,"number":"+258841234567","status":"Success","cost":"MZN 1.00","messageId":"ATXid_1"}]}}`))
+	}))
+	defer server.Close()
+
+	messageID, err := newAfricasTalkingTestProvider(server).Send(context.Background(), "841234567", "Olá")
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if messageID != "ATXid_1" {
+		t.Errorf("expected message id ATXid_1, got %q", messageID)
+	}
+}
+
+func TestAfricasTalkingSendHTTPErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		want      error
+		temporary bool
+	}{
+		{"unauthorized", http.StatusUnauthorized, ErrAuthentication, false},
+		{"forbidden", http.StatusForbidden, ErrAuthentication, false},
+		{"too many requests", http.StatusTooManyRequests, ErrRateLimited, true},
+		{"bad request", http.StatusBadRequest, ErrRejected, false},
+		{"server error", http.StatusBadGateway, ErrUnavailable, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte("The supplied authentication is invalid"))
+			}))
+			defer server.Close()
+
+			_, err := newAfricasTalkingTestProvider(server).Send(context.Background(), "841234567", "Olá")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+			if IsTemporary(err) != tt.temporary {
+				t.Errorf("expected temporary %v, got %v", tt.temporary, IsTemporary(err))
+			}
+		})
+	}
+}
+
+func TestAfricasTalkingSendRecipientErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode string
+		want       error
+		temporary  bool
+	}{
+		{"invalid sender", "402", ErrInvalidSender, false},
+		{"invalid phone number", "403", ErrInvalidRecipient, false},
+		{"insufficient balance", "405", ErrInsufficientBalance, false},
+		{"user in blacklist", "406", ErrBlacklisted, false},
+		{"could not route", "407", ErrRejected, false},
+		{"internal server error", "500", ErrUnavailable, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"SMSMessageData":{"Message":"Sent to 0/1","Recipients":[
+					{"statusCode":` + tt.statusCode + `,"number":"+258841234567","status":"Failed","cost":"0","messageId":"None"}]}}`))
+			}))
+			defer server.Close()
+
+			_, err := newAfricasTalkingTestProvider(server).Send(context.Background(), "841234567", "Olá")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+			if IsTemporary(err) != tt.temporary {
+				t.Errorf("expected temporary %v, got %v", tt.temporary, IsTemporary(err))
+			}
+
+			var providerErr *ProviderError
+			if !errors.As(err, &providerErr) || providerErr.Code != tt.statusCode {
+				t.Errorf("expected provider code %s, got %v", tt.statusCode, err)
+			}
+		})
+	}
+}
+
+func TestAfricasTalkingSendWithoutRecipients(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"SMSMessageData":{"Message":"InvalidPhoneNumber","Recipients":[]}}`))
+	}))
+	defer server.Close()
+
+	_, err := newAfricasTalkingTestProvider(server).Send(context.Background(), "84", "Olá")
+	if !errors.Is(err, ErrInvalidRecipient) {
+		t.Fatalf("expected ErrInvalidRecipient, got %v", err)
+	}
+}
+
+func TestAfricasTalkingSendCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := newAfricasTalkingTestProvider(server).Send(ctx, "841234567", "Olá")
+	if !errors.Is(err, ErrUnavailable) || !IsTemporary(err) {
+		t.Fatalf("expected a temporary ErrUnavailable, got %v", err)
+	}
+}
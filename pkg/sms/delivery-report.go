@@ -0,0 +1,107 @@
+package sms
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// DeliveryStatus representa o estado final comunicado pelo provedor
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending   DeliveryStatus = "pending"
+	DeliveryStatusDelivered DeliveryStatus = "delivered"
+	DeliveryStatusFailed    DeliveryStatus = "failed"
+)
+
+// ErrInvalidDeliveryReport indica um relatório de entrega que não pôde ser interpretado
+var ErrInvalidDeliveryReport = errors.New("sms: relatório de entrega inválido")
+
+// DeliveryReport representa um relatório de entrega recebido por webhook
+type DeliveryReport struct {
+	MessageID string
+	Status    DeliveryStatus
+	Reason    string
+}
+
+// DeliveryReportParser é implementado pelos provedores que enviam relatórios de entrega
+type DeliveryReportParser interface {
+	ParseDeliveryReport(form url.Values) (DeliveryReport, error)
+}
+
+// ParseDeliveryReport interpreta o relatório de entrega no formato do provedor configurado
+func (s *Service) ParseDeliveryReport(form url.Values) (DeliveryReport, error) {
+	parser, ok := s.provider.(DeliveryReportParser)
+	if !ok {
+		return DeliveryReport{}, errors.New("provedor de SMS não suporta relatórios de entrega")
+	}
+	return parser.ParseDeliveryReport(form)
+}
+
+// ParseDeliveryReport interpreta os campos id, status e failureReason.
+// Referência: https://developers.africastalking.com/docs/sms/notifications
+func (p *AfricasTalkingProvider) ParseDeliveryReport(form url.Values) (DeliveryReport, error) {
+	report := DeliveryReport{
+		MessageID: form.Get("id"),
+		Reason:    form.Get("failureReason"),
+	}
+	if report.MessageID == "" {
+		return DeliveryReport{}, ErrInvalidDeliveryReport
+	}
+
+	switch form.Get("status") {
+	case "Success":
+		report.Status = DeliveryStatusDelivered
+	case "Failed", "Rejected":
+		report.Status = DeliveryStatusFailed
+	default: // Sent, Submitted, Buffered
+		report.Status = DeliveryStatusPending
+	}
+
+	return report, nil
+}
+
+// ParseDeliveryReport interpreta os campos MessageSid, MessageStatus e ErrorCode do status callback
+func (p *TwilioProvider) ParseDeliveryReport(form url.Values) (DeliveryReport, error) {
+	report := DeliveryReport{
+		MessageID: form.Get("MessageSid"),
+	}
+	if report.MessageID == "" {
+		return DeliveryReport{}, ErrInvalidDeliveryReport
+	}
+
+	switch form.Get("MessageStatus") {
+	case "delivered":
+		report.Status = DeliveryStatusDelivered
+	case "undelivered", "failed":
+		report.Status = DeliveryStatusFailed
+		if code := form.Get("ErrorCode"); code != "" {
+			report.Reason = "twilio " + code
+		}
+	default: // queued, sending, sent
+		report.Status = DeliveryStatusPending
+	}
+
+	return report, nil
+}
+
+// ParseDeliveryReport aceita os campos id e status, útil para simular relatórios em desenvolvimento
+func (p *MockProvider) ParseDeliveryReport(form url.Values) (DeliveryReport, error) {
+	report := DeliveryReport{
+		MessageID: form.Get("id"),
+		Status:    DeliveryStatus(strings.ToLower(form.Get("status"))),
+		Reason:    form.Get("reason"),
+	}
+	if report.MessageID == "" {
+		return DeliveryReport{}, ErrInvalidDeliveryReport
+	}
+
+	switch report.Status {
+	case DeliveryStatusDelivered, DeliveryStatusFailed:
+	default:
+		report.Status = DeliveryStatusPending
+	}
+
+	return report, nil
+}
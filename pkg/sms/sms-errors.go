@@ -0,0 +1,46 @@
+package sms
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Categorias de erro comuns a todos os provedores.
+// Use errors.Is para as inspecionar e errors.As com *ProviderError para obter o código original.
+var (
+	ErrAuthentication      = errors.New("sms: credenciais do provedor inválidas")
+	ErrAccountSuspended    = errors.New("sms: conta do provedor suspensa")
+	ErrInvalidRecipient    = errors.New("sms: número de destino inválido")
+	ErrInvalidSender       = errors.New("sms: remetente inválido")
+	ErrInsufficientBalance = errors.New("sms: saldo insuficiente")
+	ErrBlacklisted         = errors.New("sms: destinatário bloqueou mensagens")
+	ErrRateLimited         = errors.New("sms: limite de envios do provedor atingido")
+	ErrRejected            = errors.New("sms: mensagem rejeitada pelo provedor")
+	ErrUnavailable         = errors.New("sms: provedor indisponível")
+)
+
+// ProviderError descreve uma falha devolvida por um provedor de SMS
+type ProviderError struct {
+	Provider  string
+	Code      string
+	Message   string
+	Temporary bool
+	Err       error
+}
+
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("%s: %s (código %s)", e.Provider, e.Message, e.Code)
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// IsTemporary indica se vale a pena tentar o envio novamente mais tarde
+func IsTemporary(err error) bool {
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) {
+		return providerErr.Temporary
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package sms
+
+import (
+	"strings"
+)
+
+// Alfabeto GSM 03.38. Mensagens só com estes caracteres usam 7 bits por carácter.
+const gsm7Basic = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+
+// Caracteres da tabela de extensão, que ocupam duas posições
+const gsm7Extended = "^{}\\[~]|€\f"
+
+// Segments devolve o número de partes em que a mensagem será dividida pela rede.
+// Com caracteres fora do alfabeto GSM (ex.: ã, õ, ç, á) a mensagem passa a UCS-2.
+func Segments(message string) int {
+	if message == "" {
+		return 0
+	}
+
+	length := 0
+	unicode := false
+	for _, r := range message {
+		switch {
+		case strings.ContainsRune(gsm7Basic, r):
+			length++
+		case strings.ContainsRune(gsm7Extended, r):
+			length += 2
+		default:
+			unicode = true
+		}
+		if unicode {
+			break
+		}
+	}
+
+	single, multi := 160, 153
+	if unicode {
+		// UCS-2 conta unidades de 16 bits; caracteres fora do plano básico ocupam duas
+		length = 0
+		for _, r := range message {
+			if r > 0xFFFF {
+				length += 2
+			} else {
+				length++
+			}
+		}
+		single, multi = 70, 67
+	}
+
+	if length <= single {
+		return 1
+	}
+	return (length + multi - 1) / multi
+}
@@ -1,24 +1,37 @@
 package sms
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/anamalala/pkg/logger"
 )
 
-// Provider é uma interface para provedores de serviço de SMS
+// Indicativo de Moçambique, usado quando o contacto é guardado sem indicativo
+const defaultCountryCode = "258"
+
+// Provider é uma interface para provedores de serviço de SMS.
+// Send devolve o identificador atribuído pelo provedor à mensagem e
+// abandona o pedido quando o contexto é cancelado.
 type Provider interface {
-	Send(recipient, message string) error
+	Send(ctx context.Context, recipient, message string) (string, error)
 }
 
-// SMSConfig contém a configuração do serviço de SMS
+// SMSConfig contém a configuração do serviço de SMS.
+// Na AfricasTalking, APIKey é a chave da API e APISecret o nome de usuário da conta.
+// Na Twilio, APIKey é o Account SID e APISecret o Auth Token.
+// ServiceURL substitui o endereço base da API do provedor (útil para sandbox e testes).
 type SMSConfig struct {
 	ProviderType string
 	APIKey       string
+	APISecret    string
+	ServiceURL   string
 	SenderID     string
-	// Outros campos específicos do provedor podem ser adicionados
+	Timeout      time.Duration
 }
 
 // Service gerencia o envio de SMS
@@ -32,21 +45,39 @@ type Service struct {
 func NewService(config *SMSConfig, logger *logger.Logger) (*Service, error) {
 	// Criar provedor com base na configuração
 	var provider Provider
-	
+
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	client := &http.Client{Timeout: timeout}
+
 	switch config.ProviderType {
 	case "mock":
 		provider = &MockProvider{logger: logger}
 	case "africastalking": // Exemplo de provedor comum em África
+		if config.APIKey == "" || config.APISecret == "" {
+			return nil, errors.New("AfricasTalking requer chave da API e nome de usuário")
+		}
 		provider = &AfricasTalkingProvider{
 			APIKey:   config.APIKey,
+			Username: config.APISecret,
 			SenderID: config.SenderID,
+			BaseURL:  config.ServiceURL,
+			client:   client,
 			logger:   logger,
 		}
 	case "twilio":
+		if config.APIKey == "" || config.APISecret == "" {
+			return nil, errors.New("Twilio requer Account SID e Auth Token")
+		}
 		provider = &TwilioProvider{
-			APIKey:   config.APIKey,
-			SenderID: config.SenderID,
-			logger:   logger,
+			AccountSID: config.APIKey,
+			AuthToken:  config.APISecret,
+			SenderID:   config.SenderID,
+			BaseURL:    config.ServiceURL,
+			client:     client,
+			logger:     logger,
 		}
 	default:
 		return nil, errors.New("provedor de SMS não suportado")
@@ -60,9 +91,15 @@ func NewService(config *SMSConfig, logger *logger.Logger) (*Service, error) {
 }
 
 // Send envia uma mensagem SMS
-func (s *Service) Send(recipient, message string) error {
+func (s *Service) Send(ctx context.Context, recipient, message string) error {
+	_, err := s.SendMessage(ctx, recipient, message)
+	return err
+}
+
+// SendMessage envia uma mensagem SMS e devolve o identificador atribuído pelo provedor
+func (s *Service) SendMessage(ctx context.Context, recipient, message string) (string, error) {
 	start := time.Now()
-	err := s.provider.Send(recipient, message)
+	messageID, err := s.provider.Send(ctx, recipient, message)
 	duration := time.Since(start)
 	
 	if err != nil {
@@ -71,79 +108,43 @@ func (s *Service) Send(recipient, message string) error {
 			"error", err.Error(),
 			"duration_ms", duration.Milliseconds(),
 		)
-		return err
+		return "", err
 	}
 	
 	s.logger.Info("sms_sent",
 		"recipient", recipient,
+		"message_id", messageID,
 		"message_length", len(message),
 		"duration_ms", duration.Milliseconds(),
 	)
 	
-	return nil
+	return messageID, nil
 }
 
-// SendBulk envia SMS em massa para múltiplos destinatários
-func (s *Service) SendBulk(recipients []string, message string) (map[string]error, error) {
-	results := make(map[string]error)
-	
-	for _, recipient := range recipients {
-		err := s.Send(recipient, message)
-		results[recipient] = err
-	}
-	
-	return results, nil
-}
-
-// MockProvider é um provedor de SMS simulado para testes
+// MockProvider é um provedor de SMS simulado para testes.
+// O texto não é registado, porque pode conter códigos de verificação.
 type MockProvider struct {
 	logger *logger.Logger
 }
 
-func (p *MockProvider) Send(recipient, message string) error {
+func (p *MockProvider) Send(ctx context.Context, recipient, message string) (string, error) {
+	messageID := fmt.Sprintf("mock-%d", time.Now().UnixNano())
 	p.logger.Info("mock_sms_sent",
-		"recipient", recipient,
-		"message", message,
-	)
-	return nil
-}
-
-// AfricasTalkingProvider implementa o provedor AfricasTalking
-type AfricasTalkingProvider struct {
-	APIKey   string
-	SenderID string
-	logger   *logger.Logger
-}
-
-func (p *AfricasTalkingProvider) Send(recipient, message string) error {
-	// Aqui seria implementada a integração com a API da AfricasTalking
-	// Por enquanto, apenas registramos a tentativa
-	p.logger.Info("africas_talking_send_attempt",
-		"recipient", recipient,
-		"api_key", fmt.Sprintf("%s****", p.APIKey[:4]),
-		"sender_id", p.SenderID,
+		"recipient", formatRecipient(recipient),
+		"message_id", messageID,
+		"message_length", len(message),
 	)
-	
-	// Para implementação real, usar biblioteca HTTP para chamar a API
-	return nil
+	return messageID, nil
 }
 
-// TwilioProvider implementa o provedor Twilio
-type TwilioProvider struct {
-	APIKey   string
-	SenderID string
-	logger   *logger.Logger
-}
-
-func (p *TwilioProvider) Send(recipient, message string) error {
-	// Aqui seria implementada a integração com a API da Twilio
-	// Por enquanto, apenas registramos a tentativa
-	p.logger.Info("twilio_send_attempt",
-		"recipient", recipient,
-		"api_key", fmt.Sprintf("%s****", p.APIKey[:4]),
-		"sender_id", p.SenderID,
-	)
-	
-	// Para implementação real, usar biblioteca HTTP para chamar a API
-	return nil
+// formatRecipient converte o contacto para o formato internacional (E.164)
+func formatRecipient(recipient string) string {
+	recipient = strings.ReplaceAll(recipient, " ", "")
+	if strings.HasPrefix(recipient, "+") {
+		return recipient
+	}
+	if strings.HasPrefix(recipient, defaultCountryCode) && len(recipient) > 9 {
+		return "+" + recipient
+	}
+	return "+" + defaultCountryCode + recipient
 }
@@ -0,0 +1,139 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/anamalala/pkg/logger"
+)
+
+const twilioDefaultURL = "https://api.twilio.com"
+
+// TwilioProvider implementa o provedor Twilio
+type TwilioProvider struct {
+	AccountSID string
+	AuthToken  string
+	SenderID   string
+	BaseURL    string
+	client     *http.Client
+	logger     *logger.Logger
+}
+
+// twilioMessage representa a resposta de sucesso do endpoint Messages
+type twilioMessage struct {
+	SID    string `json:"sid"`
+	Status string `json:"status"`
+}
+
+// twilioError representa o corpo de erro devolvido pela Twilio
+type twilioError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Status  int    `json:"status"`
+}
+
+func (p *TwilioProvider) Send(ctx context.Context, recipient, message string) (string, error) {
+	baseURL := p.BaseURL
+	if baseURL == "" {
+		baseURL = twilioDefaultURL
+	}
+	endpoint := strings.TrimRight(baseURL, "/") + "/2010-04-01/Accounts/" + url.PathEscape(p.AccountSID) + "/Messages.json"
+
+	form := url.Values{}
+	form.Set("To", formatRecipient(recipient))
+	form.Set("Body", message)
+	// Identificadores MG... são Messaging Services, o resto é um número ou remetente alfanumérico
+	if strings.HasPrefix(p.SenderID, "MG") {
+		form.Set("MessagingServiceSid", p.SenderID)
+	} else {
+		form.Set("From", p.SenderID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(p.AccountSID, p.AuthToken)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", &ProviderError{Provider: "twilio", Code: "network", Message: err.Error(), Temporary: true, Err: ErrUnavailable}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", &ProviderError{Provider: "twilio", Code: "network", Message: err.Error(), Temporary: true, Err: ErrUnavailable}
+	}
+
+	if resp.StatusCode >= 300 {
+		var apiErr twilioError
+		_ = json.Unmarshal(body, &apiErr)
+		return "", twilioErrorFor(resp.StatusCode, apiErr)
+	}
+
+	var result twilioMessage
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", &ProviderError{Provider: "twilio", Code: "decode", Message: err.Error(), Err: ErrRejected}
+	}
+
+	p.logger.Debug("twilio_sent",
+		"recipient", recipient,
+		"message_id", result.SID,
+		"status", result.Status,
+	)
+
+	return result.SID, nil
+}
+
+// twilioErrorFor converte os códigos de erro da Twilio em erros tipados.
+// Referência: https://www.twilio.com/docs/api/errors
+func twilioErrorFor(statusCode int, apiErr twilioError) error {
+	providerErr := &ProviderError{
+		Provider: "twilio",
+		Code:     strconv.Itoa(apiErr.Code),
+		Message:  apiErr.Message,
+	}
+	if apiErr.Code == 0 {
+		providerErr.Code = strconv.Itoa(statusCode)
+		providerErr.Message = http.StatusText(statusCode)
+	}
+
+	switch apiErr.Code {
+	case 20003, 20005:
+		providerErr.Err = ErrAuthentication
+	case 21211, 21214, 21217, 21401, 21407, 21408, 21612, 21614:
+		providerErr.Err = ErrInvalidRecipient
+	case 21212, 21603, 21606, 21659:
+		providerErr.Err = ErrInvalidSender
+	case 21610:
+		providerErr.Err = ErrBlacklisted
+	case 20429, 14107, 30001:
+		providerErr.Err = ErrRateLimited
+		providerErr.Temporary = true
+	case 30002:
+		providerErr.Err = ErrAccountSuspended
+	default:
+		switch {
+		case statusCode == http.StatusUnauthorized:
+			providerErr.Err = ErrAuthentication
+		case statusCode == http.StatusTooManyRequests:
+			providerErr.Err = ErrRateLimited
+			providerErr.Temporary = true
+		case statusCode >= 500:
+			providerErr.Err = ErrUnavailable
+			providerErr.Temporary = true
+		default:
+			providerErr.Err = ErrRejected
+		}
+	}
+
+	return providerErr
+}
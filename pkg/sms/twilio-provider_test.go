@@ -0,0 +1,134 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/anamalala/pkg/logger"
+)
+
+// newTwilioTestProvider cria um provedor Twilio apontado para o servidor de teste
+func newTwilioTestProvider(server *httptest.Server) *TwilioProvider {
+	return &TwilioProvider{
+		AccountSID: "AC123",
+		AuthToken:  "secret",
+		SenderID:   "ANAMALALA",
+		BaseURL:    server.URL,
+		client:     server.Client(),
+		logger:     logger.NewLogger("production"),
+	}
+}
+
+func TestTwilioSendSuccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2010-04-01/Accounts/AC123/Messages.json" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		user, password, ok := r.BasicAuth()
+		if !ok || user != "AC123" || password != "secret" {
+			t.Errorf("unexpected basic auth %q/%q", user, password)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("ParseForm: %v", err)
+		}
+		if got := r.PostForm.Get("To"); got != "+258841234567" {
+			t.Errorf("expected To +258841234567, got %q", got)
+		}
+		if got := r.PostForm.Get("From"); got != "ANAMALALA" {
+			t.Errorf("expected From ANAMALALA, got %q", got)
+		}
+		if got := r.PostForm.Get("Body"); got != "Olá" {
+			t.Errorf("expected Body Olá, got %q", got)
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sid":"SM42","status":"queued"}`))
+	}))
+	defer server.Close()
+
+	messageID, err := newTwilioTestProvider(server).Send(context.Background(), "841234567", "Olá")
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if messageID != "SM42" {
+		t.Errorf("expected message id SM42, got %q", messageID)
+	}
+}
+
+func TestTwilioSendErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		want      error
+		temporary bool
+	}{
+		{"invalid recipient", http.StatusBadRequest, `{"code":21211,"message":"Invalid 'To' Phone Number"}`, ErrInvalidRecipient, false},
+		{"invalid sender", http.StatusBadRequest, `{"code":21606,"message":"Not a valid message-capable number"}`, ErrInvalidSender, false},
+		{"unsubscribed recipient", http.StatusBadRequest, `{"code":21610,"message":"Attempt to send to unsubscribed recipient"}`, ErrBlacklisted, false},
+		{"bad credentials", http.StatusUnauthorized, `{"code":20003,"message":"Authenticate"}`, ErrAuthentication, false},
+		{"too many requests", http.StatusTooManyRequests, `{"code":20429,"message":"Too Many Requests"}`, ErrRateLimited, true},
+		{"queue overflow", http.StatusBadRequest, `{"code":30001,"message":"Queue overflow"}`, ErrRateLimited, true},
+		{"account suspended", http.StatusBadRequest, `{"code":30002,"message":"Account suspended"}`, ErrAccountSuspended, false},
+		{"unknown client error", http.StatusBadRequest, `{"code":12345,"message":"Unknown"}`, ErrRejected, false},
+		{"unauthorized without body", http.StatusUnauthorized, ``, ErrAuthentication, false},
+		{"server error", http.StatusServiceUnavailable, ``, ErrUnavailable, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := newTwilioTestProvider(server).Send(context.Background(), "841234567", "Olá")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+			if IsTemporary(err) != tt.temporary {
+				t.Errorf("expected temporary %v, got %v", tt.temporary, IsTemporary(err))
+			}
+		})
+	}
+}
+
+func TestTwilioSendUsesMessagingService(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if got := r.PostForm.Get("MessagingServiceSid"); got != "MG999" {
+			t.Errorf("expected MessagingServiceSid MG999, got %q", got)
+		}
+		if r.PostForm.Has("From") {
+			t.Errorf("expected no From with a messaging service")
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sid":"SM1","status":"accepted"}`))
+	}))
+	defer server.Close()
+
+	provider := newTwilioTestProvider(server)
+	provider.SenderID = "MG999"
+	if _, err := provider.Send(context.Background(), "841234567", "Olá"); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+}
+
+func TestTwilioSendCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := newTwilioTestProvider(server).Send(ctx, "841234567", "Olá")
+	if !errors.Is(err, ErrUnavailable) || !IsTemporary(err) {
+		t.Fatalf("expected a temporary ErrUnavailable, got %v", err)
+	}
+}
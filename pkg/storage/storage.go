@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrNotFound indica que não existe nenhum objeto com a chave pedida
+var ErrNotFound = errors.New("storage: objeto não encontrado")
+
+// Storage guarda o conteúdo dos ficheiros enviados pelos usuários.
+// As chaves são caminhos relativos separados por "/", escolhidos por quem chama.
+type Storage interface {
+	Put(ctx context.Context, key string, content io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Config contém a configuração do armazenamento.
+// Driver escolhe o backend; Root é a pasta base do backend "filesystem".
+type Config struct {
+	Driver string
+	Root   string
+}
+
+// NewStorage cria o backend de armazenamento indicado na configuração
+func NewStorage(config Config) (Storage, error) {
+	switch config.Driver {
+	case "", "filesystem":
+		if config.Root == "" {
+			return nil, errors.New("o armazenamento em disco requer uma pasta base")
+		}
+		return NewFileSystemStorage(config.Root)
+	default:
+		return nil, fmt.Errorf("backend de armazenamento não suportado: %s", config.Driver)
+	}
+}